@@ -2,8 +2,11 @@ package main
 
 import (
 	"context"
+	"crypto/tls"
 	"errors"
 	"fmt"
+	"io"
+	"io/fs"
 	"net"
 	"net/http"
 	"os"
@@ -11,28 +14,42 @@ import (
 	"time"
 
 	"github.com/getsentry/sentry-go"
+	"github.com/go-redis/redis/v8"
 	grpcMiddleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	grpcRecovery "github.com/grpc-ecosystem/go-grpc-middleware/recovery"
 	grpcOpentracing "github.com/grpc-ecosystem/go-grpc-middleware/tracing/opentracing"
 	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
 	"github.com/prometheus/client_golang/prometheus/promhttp"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/credentials"
 	"google.golang.org/grpc/credentials/insecure"
+	"google.golang.org/grpc/health"
+	"google.golang.org/grpc/health/grpc_health_v1"
 	"google.golang.org/grpc/reflection"
+	"google.golang.org/grpc/reflection/grpc_reflection_v1alpha"
 
 	"github.com/qulaz/artforintrovert-test/gen/api/v1"
 	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
 	"github.com/qulaz/artforintrovert-test/internal/config"
+	"github.com/qulaz/artforintrovert-test/internal/controller/gateway"
 	grpcController "github.com/qulaz/artforintrovert-test/internal/controller/grpc"
 	"github.com/qulaz/artforintrovert-test/internal/entity"
 	"github.com/qulaz/artforintrovert-test/internal/usecase"
 	"github.com/qulaz/artforintrovert-test/internal/usecase/repo"
 	"github.com/qulaz/artforintrovert-test/pkg/cache"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/actor"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/deadline"
 	"github.com/qulaz/artforintrovert-test/pkg/interceptors/grpc_sentry"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/idempotency"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/locale"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/ratelimit"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/rbac"
 	"github.com/qulaz/artforintrovert-test/pkg/interceptors/requestid"
 	"github.com/qulaz/artforintrovert-test/pkg/logging"
 	"github.com/qulaz/artforintrovert-test/pkg/mongodb"
 	"github.com/qulaz/artforintrovert-test/pkg/shutdown"
+	"github.com/qulaz/artforintrovert-test/pkg/tlsreload"
 	"github.com/qulaz/artforintrovert-test/pkg/tracing"
 )
 
@@ -40,13 +57,27 @@ const (
 	serviceName = "artforintrovert-test"
 )
 
-func runGrpcGateway(ctx context.Context, grpcEndpoint string, host string, port string) {
-	mux := runtime.NewServeMux()
+// runGrpcGateway запускает REST сервер. Без tlsConfig сервер слушает без шифрования.
+func runGrpcGateway(
+	ctx context.Context,
+	grpcEndpoint string,
+	host string,
+	port string,
+	dialCredentials credentials.TransportCredentials,
+	tlsConfig *tls.Config,
+) {
 	opts := []grpc.DialOption{
-		grpc.WithTransportCredentials(insecure.NewCredentials()),
+		grpc.WithTransportCredentials(dialCredentials),
 	}
 
-	err := mux.HandlePath(
+	healthConn, err := grpc.DialContext(ctx, grpcEndpoint, opts...)
+	if err != nil {
+		panic(err)
+	}
+
+	mux := gateway.NewServeMux(runtime.WithHealthzEndpoint(grpc_health_v1.NewHealthClient(healthConn)))
+
+	err = mux.HandlePath(
 		"GET",
 		"/metrics",
 		func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
@@ -61,17 +92,168 @@ func runGrpcGateway(ctx context.Context, grpcEndpoint string, host string, port
 		panic(err)
 	}
 
+	if err := api.RegisterAdminServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, opts); err != nil {
+		panic(err)
+	}
+
+	if err := api.RegisterCategoryServiceHandlerFromEndpoint(ctx, mux, grpcEndpoint, opts); err != nil {
+		panic(err)
+	}
+
 	httpServer := http.Server{ //nolint: exhaustruct
 		Addr:           fmt.Sprintf("%s:%s", host, port),
-		Handler:        mux,
+		Handler:        requestid.HTTPMiddleware(mux),
 		ReadTimeout:    time.Second * 5,
 		WriteTimeout:   time.Second * 10,
 		MaxHeaderBytes: 1 << 20,
+		TLSConfig:      tlsConfig,
+	}
+
+	if tlsConfig != nil {
+		// сертификат берется из TLSConfig
+		panic(httpServer.ListenAndServeTLS("", ""))
 	}
 
 	panic(httpServer.ListenAndServe())
 }
 
+// loadValidationRules читает правила валидации продуктов. Без файла действуют только встроенные проверки.
+func loadValidationRules(path string) (*entity.ValidationRules, error) {
+	if path == "" {
+		return &entity.ValidationRules{}, nil //nolint: exhaustruct
+	}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read validation rules: %w", err)
+	}
+
+	return entity.ParseValidationRules(data)
+}
+
+// serviceMethods служебные методы gRPC: доступны без аутентификации, без ограничения частоты и без дедлайна
+// по умолчанию, так как health Watch — долгоживущий поток. /metrics и /healthz шлюза отдаются без обращения
+// к защищенным методам.
+var serviceMethods = []string{
+	"/" + grpc_health_v1.Health_ServiceDesc.ServiceName + "/Check",
+	"/" + grpc_health_v1.Health_ServiceDesc.ServiceName + "/Watch",
+	"/" + grpc_reflection_v1alpha.ServerReflection_ServiceDesc.ServiceName + "/ServerReflectionInfo",
+}
+
+// idempotentMethods изменяющие методы, повтор которых с тем же ключом идемпотентности возвращает сохраненный ответ
+var idempotentMethods = []string{
+	"/ProductService/UpdateProduct",
+	"/ProductService/DeleteProduct",
+	"/ProductService/RestoreProduct",
+	"/ProductService/AddVariant",
+	"/ProductService/UpdateVariant",
+	"/ProductService/DeleteVariant",
+	"/ProductService/ReserveStock",
+	"/ProductService/ReleaseStock",
+	"/ProductService/AdjustStock",
+	"/CategoryService/CreateCategory",
+	"/CategoryService/UpdateCategory",
+	"/CategoryService/DeleteCategory",
+	"/CategoryService/MoveCategory",
+	"/AdminService/PurgeDeletedProducts",
+}
+
+// newAuthenticator создает проверку API ключей и JWT. Включенная аутентификация без ключей — ошибка конфигурации.
+func newAuthenticator(cfg *config.AuthConfig, logger logging.ContextLogger) (*auth.Authenticator, error) {
+	var (
+		jwtKeys map[string]interface{}
+		err     error
+	)
+
+	if cfg.JWTKeyFile != "" {
+		jwtKeys, err = auth.LoadJWTKeys(cfg.JWTKeyFile)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	if len(cfg.APIKeys) == 0 && len(jwtKeys) == 0 {
+		return nil, errors.New("authentication is enabled, but neither AUTH_API_KEYS nor AUTH_JWT_KEY_FILE is set")
+	}
+
+	return auth.New(
+		auth.Config{
+			APIKeys:       cfg.APIKeys,
+			JWTKeys:       jwtKeys,
+			JWTIssuer:     cfg.JWTIssuer,
+			JWTAudience:   cfg.JWTAudience,
+			ExemptMethods: serviceMethods,
+		},
+		logger,
+	), nil
+}
+
+// newTLSReloaders загружает сертификаты gRPC сервера и клиентский сертификат шлюза.
+// При mutual TLS шлюз без своего сертификата не сможет подключиться к gRPC серверу.
+func newTLSReloaders(cfg *config.TLSConfig) (*tlsreload.Reloader, *tlsreload.Reloader, error) {
+	if cfg.ClientCAFile != "" && cfg.GatewayCertFile == "" {
+		return nil, nil, errors.New("TLS_CLIENT_CA_FILE requires gateway client certificate TLS_GATEWAY_CERT_FILE")
+	}
+
+	serverTLS, err := tlsreload.New(cfg.CertFile, cfg.KeyFile, cfg.ClientCAFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't load server certificate: %w", err)
+	}
+
+	gatewayTLS, err := tlsreload.New(cfg.GatewayCertFile, cfg.GatewayKeyFile, cfg.GatewayCAFile)
+	if err != nil {
+		return nil, nil, fmt.Errorf("can't load gateway certificate: %w", err)
+	}
+
+	return serverTLS, gatewayTLS, nil
+}
+
+// newRateLimitConfig разбирает ограничения частоты запросов из формата `<rps>/<burst>`.
+func newRateLimitConfig(cfg *config.RateLimitConfig) (ratelimit.Config, error) {
+	defaultLimit, err := ratelimit.ParseLimit(cfg.Default)
+	if err != nil {
+		return ratelimit.Config{}, fmt.Errorf("RATE_LIMIT_DEFAULT: %w", err) //nolint: exhaustruct
+	}
+
+	methods := make(map[string]ratelimit.Limit, len(cfg.Methods))
+
+	for method, value := range cfg.Methods {
+		methods[method], err = ratelimit.ParseLimit(value)
+		if err != nil {
+			return ratelimit.Config{}, fmt.Errorf("RATE_LIMIT_METHODS %s: %w", method, err) //nolint: exhaustruct
+		}
+	}
+
+	return ratelimit.Config{
+		Default:       defaultLimit,
+		Methods:       methods,
+		ExemptMethods: serviceMethods,
+	}, nil
+}
+
+// listenCacheInvalidation применяет инвалидации от других реплик до отмены ctx. listen — подписка
+// TieredEntityCache.Run или ProductUseCase.ListenCacheInvalidation.
+// Потеря подписки не критична: локальный кеш все равно обновится синхронизацией, поэтому подписка просто повторяется.
+func listenCacheInvalidation(
+	ctx context.Context,
+	listen func(ctx context.Context) error,
+	logger logging.ContextLogger,
+) {
+	const resubscribeDelay = 5 * time.Second
+
+	for {
+		if err := listen(ctx); err != nil {
+			logger.Warnw("Products cache invalidation subscription failed", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-time.After(resubscribeDelay):
+		}
+	}
+}
+
 func main() { //nolint: cyclop
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -134,49 +316,256 @@ func main() { //nolint: cyclop
 	}
 
 	mongoDatabase := mongo.Client().Database(cfg.Database.Name)
-	productCache := cache.NewMemoryEntityCache[*entity.Product]()
+	localProductCache := cache.NewMemoryEntityCache[*entity.Product]()
+
+	var (
+		productCache       cache.EntityCache[*entity.Product] = localProductCache
+		tieredProductCache *cache.TieredEntityCache[*entity.Product]
+		invalidationBus    cache.InvalidationBus
+		redisClient        *redis.Client
+	)
+
+	if cfg.Redis.Addr != "" {
+		redisClient = redis.NewClient(&redis.Options{ //nolint: exhaustruct
+			Addr:     cfg.Redis.Addr,
+			Password: cfg.Redis.Password,
+			DB:       cfg.Redis.DB,
+		})
+		invalidationBus = cache.NewRedisInvalidationBus(redisClient, cfg.Redis.InvalidationChannel, cfg.Redis.Timeout)
+		tieredProductCache = cache.NewTieredEntityCache[*entity.Product](
+			localProductCache,
+			cache.NewRedisEntityCache[*entity.Product](redisClient, cfg.Redis.ProductsCacheKey, cfg.Redis.Timeout),
+			invalidationBus,
+		)
+		productCache = tieredProductCache
+	} else {
+		logger.Infow("Redis configuration is not set, products cache is not shared between replicas")
+	}
+
+	var productCacheSnapshotter *cache.Snapshotter[*entity.Product]
+
+	if cfg.API.ProductsCacheSnapshotPath != "" {
+		productCacheSnapshotter = cache.NewSnapshotter(localProductCache, cfg.API.ProductsCacheSnapshotPath)
+
+		switch err := productCacheSnapshotter.Load(); {
+		case err == nil:
+			logger.Infow("Products cache restored from snapshot", "path", cfg.API.ProductsCacheSnapshotPath)
+		case errors.Is(err, fs.ErrNotExist):
+			logger.Infow("Products cache snapshot not found", "path", cfg.API.ProductsCacheSnapshotPath)
+		default:
+			logger.Warnw("Can't restore products cache from snapshot", "err", err)
+		}
+	}
+
+	repoTimeouts := repo.Timeouts{
+		Read:  cfg.Database.ReadTimeout,
+		Write: cfg.Database.WriteTimeout,
+		Bulk:  cfg.Database.BulkTimeout,
+	}
+
+	productRepo := repo.NewMongoRepository(mongoDatabase, repoTimeouts, logger)
+	if err := productRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.Fatalw(err.Error())
+	}
+
+	validationRules, err := loadValidationRules(cfg.API.ProductValidationRulesPath)
+	if err != nil {
+		logger.Fatalw(err.Error())
+	}
 
-	productRepo := repo.NewMongoRepository(mongoDatabase, logger)
+	validationRules.RequireLocales(cfg.API.DefaultLocale, cfg.API.RequiredLocales...)
 
 	productUseCase := usecase.NewProductUseCase(
 		productRepo,
 		productCache,
+		invalidationBus,
+		logger,
+		usecase.CacheSyncConfig{
+			Interval:     cfg.API.ProductsCacheTtl,
+			MinBackoff:   cfg.API.ProductsCacheSyncMinBackoff,
+			MaxBackoff:   cfg.API.ProductsCacheSyncMaxBackoff,
+			Jitter:       cfg.API.ProductsCacheSyncJitter,
+			MaxStaleness: cfg.API.ProductsCacheMaxStaleness,
+			Timeout:      cfg.API.ProductsCacheSyncTimeout,
+		},
+		cfg.API.ProductsNegativeCacheTtl,
+		validationRules,
+	)
+
+	categoryRepo := repo.NewMongoCategoryRepository(mongoDatabase, repoTimeouts, logger)
+	if err := categoryRepo.EnsureIndexes(context.Background()); err != nil {
+		logger.Fatalw(err.Error())
+	}
+
+	idempotencyStore := repo.NewMongoIdempotencyStore(mongoDatabase, cfg.API.IdempotencyKeyTtl)
+	if err := idempotencyStore.EnsureIndexes(context.Background()); err != nil {
+		logger.Fatalw(err.Error())
+	}
+
+	categoryUseCase := usecase.NewCategoryUseCase(
+		categoryRepo,
+		productUseCase,
+		cache.NewMemoryEntityCache[*entity.Category](),
 		logger,
-		cfg.API.ProductsCacheTtl,
 	)
 
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	go productUseCase.SyncCache(ctx)
+	go categoryUseCase.SyncCache(ctx, cfg.API.CategoriesCacheTtl)
 
-	productGrpcServer := grpcController.NewProductGrpcServer(productUseCase, logger)
+	if tieredProductCache != nil {
+		go listenCacheInvalidation(ctx, tieredProductCache.Run, logger)
+		go listenCacheInvalidation(ctx, productUseCase.ListenCacheInvalidation, logger)
+	}
 
-	server := grpc.NewServer(
-		grpc.UnaryInterceptor(
-			grpcMiddleware.ChainUnaryServer(
-				requestid.UnaryServerInterceptor(),
-				grpcRecovery.UnaryServerInterceptor(grpcRecovery.WithRecoveryHandler(func(p interface{}) error {
-					return commonerr.ErrInternalServerError
-				})),
-				grpc_sentry.UnaryServerInterceptor(true),
-				logging.UnaryServerInterceptor(logger, logging.ExtractRequestId, true),
-				grpcOpentracing.UnaryServerInterceptor(),
-			),
-		),
-		grpc.StreamInterceptor(
-			grpcMiddleware.ChainStreamServer(
-				requestid.StreamServerInterceptor(),
-				grpcRecovery.StreamServerInterceptor(grpcRecovery.WithRecoveryHandler(func(p interface{}) error {
-					return commonerr.ErrInternalServerError
-				})),
-				grpc_sentry.StreamServerInterceptor(true),
-				logging.StreamServerInterceptor(logger, logging.ExtractRequestId, true),
-				grpcOpentracing.StreamServerInterceptor(),
-			),
+	if cfg.API.ProductsCacheVerifyInterval > 0 {
+		cacheVerifier := usecase.NewCacheVerifier(productRepo, productCache, logger, usecase.CacheVerifierConfig{
+			Interval:   cfg.API.ProductsCacheVerifyInterval,
+			SampleSize: cfg.API.ProductsCacheVerifySampleSize,
+			SelfHeal:   cfg.API.ProductsCacheVerifySelfHeal,
+		})
+
+		go cacheVerifier.Run(ctx)
+	}
+
+	if productCacheSnapshotter != nil {
+		go productCacheSnapshotter.Run(ctx, cfg.API.ProductsCacheSnapshotInterval, func(err error) {
+			logger.Warnw("Can't save products cache snapshot", "err", err)
+		})
+	}
+
+	serverOptions := []grpc.ServerOption{}
+	gatewayCredentials := insecure.NewCredentials()
+
+	var restTLSConfig *tls.Config
+
+	if cfg.TLS.CertFile != "" {
+		serverTLS, gatewayTLS, err := newTLSReloaders(cfg.TLS)
+		if err != nil {
+			logger.Fatalw(err.Error())
+		}
+
+		for _, reloader := range []*tlsreload.Reloader{serverTLS, gatewayTLS} {
+			go reloader.Run(
+				ctx,
+				cfg.TLS.ReloadInterval,
+				func() { logger.Infow("TLS certificates reloaded") },
+				func(err error) { logger.Warnw("Can't reload TLS certificates", "err", err) },
+			)
+		}
+
+		serverOptions = append(serverOptions, grpc.Creds(credentials.NewTLS(serverTLS.ServerConfig(true, "h2"))))
+		gatewayCredentials = credentials.NewTLS(gatewayTLS.ClientConfig(cfg.TLS.GatewayServerName))
+		restTLSConfig = serverTLS.ServerConfig(false, "h2", "http/1.1")
+	} else {
+		logger.Warnw("TLS is not configured, connections are not encrypted")
+	}
+
+	localeResolver, err := locale.NewResolver(cfg.API.DefaultLocale, cfg.API.SupportedLocales)
+	if err != nil {
+		logger.Fatalw(err.Error())
+	}
+
+	deadlines := deadline.New(deadline.Config{
+		Default:       cfg.API.RequestDefaultTimeout,
+		Max:           cfg.API.RequestMaxTimeout,
+		ExemptMethods: serviceMethods,
+	})
+
+	extractLogData := func(ctx context.Context) []any {
+		return append(logging.ExtractRequestId(ctx), auth.ExtractPrincipal(ctx)...)
+	}
+
+	// recovery сразу после request id, логирование и трейсинг — до проверок доступа и лимитов,
+	// чтобы отклоненные вызовы тоже попадали в логи и трейсы
+	unaryInterceptors := []grpc.UnaryServerInterceptor{
+		requestid.UnaryServerInterceptor(),
+		grpcRecovery.UnaryServerInterceptor(grpcRecovery.WithRecoveryHandler(func(p interface{}) error {
+			return commonerr.ErrInternalServerError
+		})),
+		grpc_sentry.UnaryServerInterceptor(true),
+		auth.PrincipalHolderUnaryServerInterceptor(),
+		logging.UnaryServerInterceptor(logger, extractLogData, true),
+		grpcOpentracing.UnaryServerInterceptor(),
+		deadline.UnaryServerInterceptor(deadlines),
+	}
+	streamInterceptors := []grpc.StreamServerInterceptor{
+		requestid.StreamServerInterceptor(),
+		grpcRecovery.StreamServerInterceptor(grpcRecovery.WithRecoveryHandler(func(p interface{}) error {
+			return commonerr.ErrInternalServerError
+		})),
+		grpc_sentry.StreamServerInterceptor(true),
+		auth.PrincipalHolderStreamServerInterceptor(),
+		logging.StreamServerInterceptor(logger, extractLogData, true),
+		grpcOpentracing.StreamServerInterceptor(),
+		deadline.StreamServerInterceptor(deadlines),
+	}
+
+	if cfg.Auth.Disabled {
+		logger.Warnw("Authentication is disabled")
+	} else {
+		authenticator, err := newAuthenticator(cfg.Auth, logger)
+		if err != nil {
+			logger.Fatalw(err.Error())
+		}
+
+		unaryInterceptors = append(unaryInterceptors, auth.UnaryServerInterceptor(authenticator))
+		streamInterceptors = append(streamInterceptors, auth.StreamServerInterceptor(authenticator))
+
+		if cfg.Auth.PolicyPath == "" {
+			logger.Fatalw("authentication is enabled, but AUTH_POLICY_PATH is not set")
+		}
+
+		policy, err := rbac.LoadPolicy(cfg.Auth.PolicyPath)
+		if err != nil {
+			logger.Fatalw(err.Error())
+		}
+
+		unaryInterceptors = append(unaryInterceptors, rbac.UnaryServerInterceptor(policy, serviceMethods, logger))
+		streamInterceptors = append(streamInterceptors, rbac.StreamServerInterceptor(policy, serviceMethods, logger))
+	}
+
+	rateLimitConfig, err := newRateLimitConfig(cfg.RateLimit)
+	if err != nil {
+		logger.Fatalw(err.Error())
+	}
+
+	rateLimiter := ratelimit.New(rateLimitConfig)
+	unaryInterceptors = append(unaryInterceptors, ratelimit.UnaryServerInterceptor(rateLimiter))
+	streamInterceptors = append(streamInterceptors, ratelimit.StreamServerInterceptor(rateLimiter))
+
+	productGrpcServer := grpcController.NewProductGrpcServer(productUseCase, logger)
+	adminGrpcServer := grpcController.NewAdminGrpcServer(productUseCase, logger)
+	categoryGrpcServer := grpcController.NewCategoryGrpcServer(categoryUseCase, logger)
+
+	unaryInterceptors = append(
+		unaryInterceptors,
+		actor.UnaryServerInterceptor(),
+		locale.UnaryServerInterceptor(localeResolver),
+		// последним, чтобы сохраненный ответ повторялся только для прошедших все проверки вызовов
+		idempotency.UnaryServerInterceptor(
+			idempotencyStore,
+			idempotency.Config{Methods: idempotentMethods, LockTimeout: cfg.API.IdempotencyLockTimeout},
+			logger,
 		),
 	)
+	streamInterceptors = append(
+		streamInterceptors,
+		actor.StreamServerInterceptor(),
+		locale.StreamServerInterceptor(localeResolver),
+	)
+
+	server := grpc.NewServer(append(
+		serverOptions,
+		grpc.UnaryInterceptor(grpcMiddleware.ChainUnaryServer(unaryInterceptors...)),
+		grpc.StreamInterceptor(grpcMiddleware.ChainStreamServer(streamInterceptors...)),
+	)...)
+	grpc_health_v1.RegisterHealthServer(server, health.NewServer())
 	api.RegisterProductServiceServer(server, productGrpcServer)
+	api.RegisterAdminServiceServer(server, adminGrpcServer)
+	api.RegisterCategoryServiceServer(server, categoryGrpcServer)
 
 	if cfg.API.Debug {
 		reflection.Register(server)
@@ -187,16 +576,31 @@ func main() { //nolint: cyclop
 		logger.Fatalw(err.Error())
 	}
 
+	closeItems := []io.Closer{mongo, tracer, listen}
+	if redisClient != nil {
+		closeItems = append(closeItems, redisClient)
+	}
+
+	if productCacheSnapshotter != nil {
+		// снапшот сохраняется первым, пока остальные ресурсы еще открыты
+		closeItems = append([]io.Closer{productCacheSnapshotter}, closeItems...)
+	}
+
 	go shutdown.Graceful(
 		[]os.Signal{syscall.SIGABRT, syscall.SIGQUIT, syscall.SIGHUP, syscall.SIGINT, syscall.SIGTERM},
-		mongo, tracer, listen, // add io.Closer items here
+		closeItems..., // add io.Closer items to closeItems
 	)
 
-	logger.Infow(fmt.Sprintf("🚀 Starting GRPC server at http://%s:%s", cfg.API.Host, cfg.API.GrpcPort))
+	scheme := "http"
+	if restTLSConfig != nil {
+		scheme = "https"
+	}
+
+	logger.Infow(fmt.Sprintf("🚀 Starting GRPC server at %s://%s:%s", scheme, cfg.API.Host, cfg.API.GrpcPort))
 
-	go runGrpcGateway(ctx, listen.Addr().String(), cfg.API.Host, cfg.API.RestPort)
+	go runGrpcGateway(ctx, listen.Addr().String(), cfg.API.Host, cfg.API.RestPort, gatewayCredentials, restTLSConfig)
 
-	logger.Infow(fmt.Sprintf("🚀 Starting REST server at http://%s:%s", cfg.API.Host, cfg.API.RestPort))
+	logger.Infow(fmt.Sprintf("🚀 Starting REST server at %s://%s:%s", scheme, cfg.API.Host, cfg.API.RestPort))
 
 	if err := server.Serve(listen); err != nil {
 		switch {
@@ -0,0 +1,64 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	"github.com/qulaz/artforintrovert-test/internal/config"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/usecase/repo"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+	"github.com/qulaz/artforintrovert-test/pkg/mongodb"
+)
+
+// Переводит цены продуктов из int32 без валюты в Money с валютой DEFAULT_CURRENCY, проставляет createdAt
+// и updatedAt продуктам без них и освобождает артикулы продуктов, удаленных до переноса sku в deletedSku.
+func main() {
+	cfg, err := config.GetConfig()
+	if err != nil {
+		panic(err)
+	}
+
+	currency, ok := entity.LookupCurrency(cfg.API.DefaultCurrency)
+	if !ok {
+		panic(fmt.Sprintf("unsupported default currency %s", cfg.API.DefaultCurrency))
+	}
+
+	mongo, err := mongodb.New(cfg.Database.DSN)
+	if err != nil {
+		panic(err)
+	}
+
+	mongoDatabase := mongo.Client().Database(cfg.Database.Name)
+
+	productRepo := repo.NewMongoRepository(mongoDatabase, repo.Timeouts{}, logging.NewDummyLogger()) //nolint: exhaustruct
+
+	ctx, cancel := context.WithTimeout(context.Background(), time.Minute*5)
+
+	defer func() {
+		cancel()
+		mongo.Close()
+	}()
+
+	migrated, err := productRepo.MigrateLegacyPrices(ctx, currency)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Migrated %d prices to %s\n", migrated, currency.Code)
+
+	backfilled, err := productRepo.BackfillTimestamps(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Backfilled timestamps of %d products\n", backfilled)
+
+	released, err := productRepo.ReleaseDeletedSkus(ctx)
+	if err != nil {
+		panic(err)
+	}
+
+	fmt.Printf("Released sku of %d deleted products\n", released)
+}
@@ -3,7 +3,7 @@ package main
 import (
 	"context"
 	"fmt"
-	"math"
+	"strings"
 	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -16,6 +16,11 @@ import (
 	"github.com/qulaz/artforintrovert-test/pkg/mongodb"
 )
 
+const (
+	maxPriceMajor = 1_000_000
+	maxStock      = 1000
+)
+
 func main() {
 	cfg, err := config.GetConfig()
 	if err != nil {
@@ -29,16 +34,27 @@ func main() {
 
 	mongoDatabase := mongo.Client().Database(cfg.Database.Name)
 
-	productRepo := repo.NewMongoRepository(mongoDatabase, logging.NewDummyLogger())
+	productRepo := repo.NewMongoRepository(mongoDatabase, repo.Timeouts{}, logging.NewDummyLogger()) //nolint: exhaustruct
+
+	currency, ok := entity.LookupCurrency(cfg.API.DefaultCurrency)
+	if !ok {
+		panic(fmt.Sprintf("unsupported default currency %s", cfg.API.DefaultCurrency))
+	}
 
 	products := make([]*entity.Product, 50_000)
 
 	for i := range products {
-		products[i] = &entity.Product{
-			Id:          primitive.NewObjectID(),
+		id := primitive.NewObjectID()
+		products[i] = &entity.Product{ //nolint: exhaustruct
+			Id:          id,
 			Name:        gofakeit.Name(),
 			Description: gofakeit.JobDescriptor(),
-			Price:       int32(gofakeit.IntRange(1, math.MaxInt32)),
+			Sku:         "SKU-" + strings.ToUpper(id.Hex()),
+			Stock:       int64(gofakeit.IntRange(0, maxStock)),
+			Price: entity.Money{
+				Amount:   int64(gofakeit.IntRange(1, maxPriceMajor)) * currency.Multiplier(),
+				Currency: currency.Code,
+			},
 		}
 	}
 
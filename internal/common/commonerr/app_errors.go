@@ -3,6 +3,8 @@ package commonerr
 import (
 	"errors"
 	"fmt"
+	"strings"
+	"time"
 )
 
 type ErrorType struct {
@@ -13,12 +15,44 @@ var (
 	ErrorTypeUnknown        = ErrorType{"unknown"}
 	ErrorTypeIncorrectInput = ErrorType{"incorrect-input"}
 	ErrorTypeNotFound       = ErrorType{"not-found"}
+	// ErrorTypeAlreadyExists создаваемый ресурс или его уникальный ключ уже существует
+	ErrorTypeAlreadyExists = ErrorType{"already-exists"}
+	// ErrorTypeConflict ресурс изменен параллельно, запрос можно повторить
+	ErrorTypeConflict = ErrorType{"conflict"}
+	// ErrorTypeFailedPrecondition состояние ресурса не позволяет выполнить операцию, например не хватает остатка
+	ErrorTypeFailedPrecondition = ErrorType{"failed-precondition"}
+	ErrorTypePermissionDenied   = ErrorType{"permission-denied"}
+	ErrorTypeUnauthenticated    = ErrorType{"unauthenticated"}
+	// ErrorTypeUnavailable временно недоступна зависимость, например база
+	ErrorTypeUnavailable      = ErrorType{"unavailable"}
+	ErrorTypeDeadlineExceeded = ErrorType{"deadline-exceeded"}
+	ErrorTypeRateLimited      = ErrorType{"rate-limited"}
+	// ErrorTypeCanceled клиент отменил запрос или отключился, ошибкой сервиса не считается
+	ErrorTypeCanceled = ErrorType{"canceled"}
 )
 
 type AppError struct {
-	messagef  string
-	args      []interface{}
-	errorType ErrorType
+	messagef   string
+	args       []interface{}
+	errorType  ErrorType
+	violations []FieldViolation
+	reason     string
+	metadata   map[string]string
+	resource   *ResourceInfo
+	retryDelay time.Duration
+	cause      error
+}
+
+// ResourceInfo ресурс, к которому относится ошибка, например продукт, который не найден.
+type ResourceInfo struct {
+	Type string
+	Name string
+}
+
+// FieldViolation нарушение валидации конкретного поля. Field — путь к полю в терминах API, например `variants[0].sku`.
+type FieldViolation struct {
+	Field       string
+	Description string
 }
 
 func (e AppError) Error() string {
@@ -29,6 +63,64 @@ func (e AppError) ErrorType() ErrorType {
 	return e.errorType
 }
 
+// Violations нарушения по полям, если ошибка создана через NewValidationError.
+func (e AppError) Violations() []FieldViolation {
+	return e.violations
+}
+
+// Reason машиночитаемая причина ошибки в UPPER_SNAKE_CASE. По умолчанию выводится из типа ошибки.
+func (e AppError) Reason() string {
+	if e.reason != "" {
+		return e.reason
+	}
+
+	return strings.ToUpper(strings.ReplaceAll(e.errorType.t, "-", "_"))
+}
+
+func (e AppError) Metadata() map[string]string {
+	return e.metadata
+}
+
+func (e AppError) Resource() *ResourceInfo {
+	return e.resource
+}
+
+// RetryDelay через сколько имеет смысл повторить запрос. 0 — повтор не поможет.
+func (e AppError) RetryDelay() time.Duration {
+	return e.retryDelay
+}
+
+// WithReason возвращает копию ошибки с уточненной причиной и дополнительными данными для клиента.
+func (e AppError) WithReason(reason string, metadata map[string]string) AppError {
+	e.reason = reason
+	e.metadata = metadata
+
+	return e
+}
+
+func (e AppError) WithResource(resourceType string, name string) AppError {
+	e.resource = &ResourceInfo{Type: resourceType, Name: name}
+
+	return e
+}
+
+func (e AppError) WithRetryDelay(delay time.Duration) AppError {
+	e.retryDelay = delay
+
+	return e
+}
+
+// WithCause сохраняет исходную ошибку для логов. Клиенту она не отдается.
+func (e AppError) WithCause(cause error) AppError {
+	e.cause = cause
+
+	return e
+}
+
+func (e AppError) Unwrap() error {
+	return e.cause
+}
+
 func (e AppError) Is(target error) bool {
 	var appErr AppError
 
@@ -45,6 +137,13 @@ func IsAppError(err error) bool {
 	return errors.As(err, &appErr)
 }
 
+// IsErrorType проверяет, что в цепочке err есть AppError с типом errorType.
+func IsErrorType(err error, errorType ErrorType) bool {
+	var appErr AppError
+
+	return errors.As(err, &appErr) && appErr.ErrorType() == errorType
+}
+
 func NewUnknownAppErrorf(messagef string, args ...interface{}) AppError {
 	return AppError{
 		messagef:  messagef,
@@ -68,3 +167,101 @@ func NewNotFoundError(messagef string, args ...interface{}) AppError {
 		errorType: ErrorTypeNotFound,
 	}
 }
+
+func NewAlreadyExistsError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypeAlreadyExists,
+	}
+}
+
+func NewConflictError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypeConflict,
+	}
+}
+
+func NewFailedPreconditionError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypeFailedPrecondition,
+	}
+}
+
+func NewPermissionDeniedError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypePermissionDenied,
+	}
+}
+
+func NewUnauthenticatedError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypeUnauthenticated,
+	}
+}
+
+func NewUnavailableError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypeUnavailable,
+	}
+}
+
+func NewDeadlineExceededError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypeDeadlineExceeded,
+	}
+}
+
+func NewCanceledError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypeCanceled,
+	}
+}
+
+func NewRateLimitedError(messagef string, args ...interface{}) AppError {
+	return AppError{
+		messagef:  messagef,
+		args:      args,
+		errorType: ErrorTypeRateLimited,
+	}
+}
+
+// NewValidationError ошибка некорректного ввода со всеми найденными нарушениями сразу.
+func NewValidationError(violations []FieldViolation) AppError {
+	messages := make([]string, len(violations))
+	for i, violation := range violations {
+		messages[i] = fmt.Sprintf("%s: %s", violation.Field, violation.Description)
+	}
+
+	return AppError{
+		messagef:   "validation failed: %s",
+		args:       []interface{}{strings.Join(messages, "; ")},
+		errorType:  ErrorTypeIncorrectInput,
+		violations: violations,
+	}
+}
+
+// FieldViolations возвращает нарушения по полям из цепочки err.
+func FieldViolations(err error) []FieldViolation {
+	var appErr AppError
+
+	if !errors.As(err, &appErr) {
+		return nil
+	}
+
+	return appErr.Violations()
+}
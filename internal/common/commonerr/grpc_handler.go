@@ -4,30 +4,118 @@ import (
 	"context"
 	"errors"
 
+	"github.com/golang/protobuf/proto" //nolint: staticcheck // status.WithDetails принимает proto v1
 	pkgErrors "github.com/pkg/errors"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
 	"google.golang.org/grpc/codes"
 	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/types/known/durationpb"
 
 	"github.com/qulaz/artforintrovert-test/pkg/logging"
 )
 
+// ErrorDomain домен ошибок сервиса в google.rpc.ErrorInfo.
+const ErrorDomain = "artforintrovert-test"
+
 var ErrInternalServerError = status.Error(codes.Internal, "Internal server error")
 
+// grpcCodes коды статусов для типов ошибок. HTTP код из них выводит grpc-gateway:
+// например Aborted и AlreadyExists — 409, ResourceExhausted — 429, Unavailable — 503.
+var grpcCodes = map[ErrorType]codes.Code{
+	ErrorTypeIncorrectInput:     codes.InvalidArgument,
+	ErrorTypeNotFound:           codes.NotFound,
+	ErrorTypeAlreadyExists:      codes.AlreadyExists,
+	ErrorTypeConflict:           codes.Aborted,
+	ErrorTypeFailedPrecondition: codes.FailedPrecondition,
+	ErrorTypePermissionDenied:   codes.PermissionDenied,
+	ErrorTypeUnauthenticated:    codes.Unauthenticated,
+	ErrorTypeUnavailable:        codes.Unavailable,
+	ErrorTypeDeadlineExceeded:   codes.DeadlineExceeded,
+	ErrorTypeRateLimited:        codes.ResourceExhausted,
+	ErrorTypeCanceled:           codes.Canceled,
+}
+
+// GrpcCode код статуса для типа ошибки. Неизвестные типы считаются внутренней ошибкой.
+func GrpcCode(errorType ErrorType) codes.Code {
+	code, ok := grpcCodes[errorType]
+	if !ok {
+		return codes.Internal
+	}
+
+	return code
+}
+
 func GrpcErrHandler(ctx context.Context, err error, sentryInfo *SentryInfo) error {
 	var appError AppError
 
-	if errors.As(err, &appError) {
-		switch appError.errorType {
-		case ErrorTypeIncorrectInput:
-			return status.Error(codes.InvalidArgument, err.Error())
-		case ErrorTypeNotFound:
-			return status.Error(codes.NotFound, err.Error())
-		case ErrorTypeUnknown:
-			return internalServerErrorHandler(ctx, err, sentryInfo)
+	isAppError := errors.As(err, &appError)
+	if !isAppError && errors.Is(err, context.Canceled) {
+		// клиент отключился, не дождавшись ответа: не логируем как внутреннюю ошибку и не отправляем в Sentry
+		return status.Error(codes.Canceled, "request canceled")
+	}
+
+	if !isAppError || appError.errorType == ErrorTypeUnknown {
+		return internalServerErrorHandler(ctx, err, sentryInfo)
+	}
+
+	code := GrpcCode(appError.errorType)
+	if code == codes.Internal {
+		return internalServerErrorHandler(ctx, err, sentryInfo)
+	}
+
+	if appError.errorType == ErrorTypeUnavailable || appError.errorType == ErrorTypeDeadlineExceeded {
+		// проблема на нашей стороне, клиенту уходит только сообщение, исходная ошибка — в лог
+		logging.FromContextOrDummy(ctx).Warnw("⚠️ Dependency error", "err", err, "cause", appError.Unwrap())
+	}
+
+	return appErrorStatus(code, err, appError)
+}
+
+// appErrorStatus собирает статус с деталями google.rpc, по которым клиент может разобрать ошибку без парсинга текста.
+func appErrorStatus(code codes.Code, err error, appError AppError) error {
+	st := status.New(code, err.Error())
+
+	details := []proto.Message{
+		&errdetails.ErrorInfo{
+			Reason:   appError.Reason(),
+			Domain:   ErrorDomain,
+			Metadata: appError.Metadata(),
+		},
+	}
+
+	if violations := appError.Violations(); len(violations) > 0 {
+		badRequest := &errdetails.BadRequest{
+			FieldViolations: make([]*errdetails.BadRequest_FieldViolation, len(violations)),
+		}
+
+		for i, violation := range violations {
+			badRequest.FieldViolations[i] = &errdetails.BadRequest_FieldViolation{
+				Field:       violation.Field,
+				Description: violation.Description,
+			}
 		}
+
+		details = append(details, badRequest)
+	}
+
+	if resource := appError.Resource(); resource != nil {
+		details = append(details, &errdetails.ResourceInfo{ //nolint: exhaustruct
+			ResourceType: resource.Type,
+			ResourceName: resource.Name,
+			Description:  appError.Error(),
+		})
+	}
+
+	if delay := appError.RetryDelay(); delay > 0 {
+		details = append(details, &errdetails.RetryInfo{RetryDelay: durationpb.New(delay)})
+	}
+
+	stWithDetails, detailsErr := st.WithDetails(details...)
+	if detailsErr != nil {
+		return st.Err()
 	}
 
-	return internalServerErrorHandler(ctx, err, sentryInfo)
+	return stWithDetails.Err()
 }
 
 func internalServerErrorHandler(ctx context.Context, err error, sentryInfo *SentryInfo) error {
@@ -0,0 +1,127 @@
+package commonerr
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+)
+
+func TestGrpcErrHandler_Details(t *testing.T) {
+	t.Run("field violations", func(t *testing.T) {
+		err := NewValidationError([]FieldViolation{
+			{Field: "name", Description: "name is required"},
+			{Field: "variants[0].sku", Description: "duplicate sku"},
+		})
+
+		st := status.Convert(GrpcErrHandler(context.Background(), fmt.Errorf("wrapped: %w", err), nil))
+		assert.Equal(t, codes.InvalidArgument, st.Code())
+
+		details := st.Details()
+		require.Len(t, details, 2)
+
+		errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, "INCORRECT_INPUT", errorInfo.GetReason())
+		assert.Equal(t, ErrorDomain, errorInfo.GetDomain())
+
+		badRequest, ok := details[1].(*errdetails.BadRequest)
+		require.True(t, ok)
+		require.Len(t, badRequest.GetFieldViolations(), 2)
+		assert.Equal(t, "variants[0].sku", badRequest.GetFieldViolations()[1].GetField())
+		assert.Equal(t, "duplicate sku", badRequest.GetFieldViolations()[1].GetDescription())
+	})
+	t.Run("resource info", func(t *testing.T) {
+		err := NewNotFoundError("product %s not found", "42").WithResource("product", "42")
+
+		st := status.Convert(GrpcErrHandler(context.Background(), err, nil))
+		assert.Equal(t, codes.NotFound, st.Code())
+
+		details := st.Details()
+		require.Len(t, details, 2)
+
+		errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, "NOT_FOUND", errorInfo.GetReason())
+
+		resourceInfo, ok := details[1].(*errdetails.ResourceInfo)
+		require.True(t, ok)
+		assert.Equal(t, "product", resourceInfo.GetResourceType())
+		assert.Equal(t, "42", resourceInfo.GetResourceName())
+	})
+	t.Run("reason and retry info", func(t *testing.T) {
+		err := NewIncorrectInputError("modified concurrently").
+			WithReason("CONCURRENT_MODIFICATION", map[string]string{"productId": "42"}).
+			WithRetryDelay(time.Second)
+
+		st := status.Convert(GrpcErrHandler(context.Background(), err, nil))
+		details := st.Details()
+		require.Len(t, details, 2)
+
+		errorInfo, ok := details[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, "CONCURRENT_MODIFICATION", errorInfo.GetReason())
+		assert.Equal(t, map[string]string{"productId": "42"}, errorInfo.GetMetadata())
+
+		retryInfo, ok := details[1].(*errdetails.RetryInfo)
+		require.True(t, ok)
+		assert.Equal(t, time.Second, retryInfo.GetRetryDelay().AsDuration())
+	})
+	t.Run("unknown error has no details", func(t *testing.T) {
+		err := GrpcErrHandler(context.Background(), errors.New("boom"), nil)
+
+		assert.Equal(t, ErrInternalServerError, err)
+		assert.Empty(t, status.Convert(err).Details())
+	})
+}
+
+func TestAppError_DetailsKeepType(t *testing.T) {
+	err := NewIncorrectInputError("insufficient stock").WithReason("INSUFFICIENT_STOCK", nil)
+
+	assert.True(t, IsErrorType(err, ErrorTypeIncorrectInput))
+	assert.True(t, errors.Is(fmt.Errorf("wrapped: %w", err), NewIncorrectInputError("")))
+}
+
+func TestGrpcErrHandler_Codes(t *testing.T) {
+	testCases := []struct {
+		err  error
+		code codes.Code
+	}{
+		{err: NewIncorrectInputError("bad"), code: codes.InvalidArgument},
+		{err: NewNotFoundError("missing"), code: codes.NotFound},
+		{err: NewAlreadyExistsError("exists"), code: codes.AlreadyExists},
+		{err: NewConflictError("conflict"), code: codes.Aborted},
+		{err: NewFailedPreconditionError("precondition"), code: codes.FailedPrecondition},
+		{err: NewPermissionDeniedError("denied"), code: codes.PermissionDenied},
+		{err: NewUnauthenticatedError("who are you"), code: codes.Unauthenticated},
+		{err: NewUnavailableError("db is down").WithCause(errors.New("dial tcp")), code: codes.Unavailable},
+		{err: NewDeadlineExceededError("too slow"), code: codes.DeadlineExceeded},
+		{err: NewRateLimitedError("slow down"), code: codes.ResourceExhausted},
+		{err: NewCanceledError("canceled").WithCause(context.Canceled), code: codes.Canceled},
+		{err: fmt.Errorf("can't get product: %w", context.Canceled), code: codes.Canceled},
+		{err: NewUnknownAppErrorf("unknown"), code: codes.Internal},
+	}
+
+	for _, tc := range testCases {
+		st := status.Convert(GrpcErrHandler(context.Background(), tc.err, nil))
+		assert.Equal(t, tc.code, st.Code(), tc.err.Error())
+	}
+}
+
+func TestAppError_Cause(t *testing.T) {
+	cause := errors.New("connection refused")
+	err := NewUnavailableError("database is unavailable").WithCause(cause)
+
+	assert.ErrorIs(t, err, cause)
+	assert.Equal(t, "database is unavailable", err.Error())
+
+	st := status.Convert(GrpcErrHandler(context.Background(), err, nil))
+	assert.NotContains(t, st.Message(), cause.Error())
+}
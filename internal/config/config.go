@@ -1,6 +1,7 @@
 package config
 
 import (
+	"errors"
 	"fmt"
 	"sync"
 	"time"
@@ -15,11 +16,70 @@ type APIConfig struct {
 	GrpcPort         string        `envconfig:"GRPC_PORT" default:"50051"`
 	RestPort         string        `envconfig:"REST_PORT" default:"8000"`
 	ProductsCacheTtl time.Duration `envconfig:"PRODUCTS_CACHE_TTL" default:"1m"`
+	// ProductsCacheSyncMinBackoff задержка перед повтором синхронизации после ошибки. Удваивается до MaxBackoff
+	ProductsCacheSyncMinBackoff time.Duration `envconfig:"PRODUCTS_CACHE_SYNC_MIN_BACKOFF" default:"1s"`
+	ProductsCacheSyncMaxBackoff time.Duration `envconfig:"PRODUCTS_CACHE_SYNC_MAX_BACKOFF" default:"1m"`
+	// ProductsCacheSyncTimeout ограничение времени одной синхронизации кеша
+	ProductsCacheSyncTimeout time.Duration `envconfig:"PRODUCTS_CACHE_SYNC_TIMEOUT" default:"30s"`
+	// ProductsCacheSyncJitter доля случайного отклонения интервала синхронизации в диапазоне [0, 1)
+	ProductsCacheSyncJitter float64 `envconfig:"PRODUCTS_CACHE_SYNC_JITTER" default:"0.1"`
+	// ProductsCacheMaxStaleness время без успешной синхронизации, после которого поднимается алерт
+	ProductsCacheMaxStaleness time.Duration `envconfig:"PRODUCTS_CACHE_MAX_STALENESS" default:"5m"`
+	// ProductsNegativeCacheTtl время, на которое запоминается отсутствие продукта в базе
+	ProductsNegativeCacheTtl time.Duration `envconfig:"PRODUCTS_NEGATIVE_CACHE_TTL" default:"30s"`
+	// ProductsCacheVerifyInterval период сверки кеша с базой. 0 — сверка отключена
+	ProductsCacheVerifyInterval time.Duration `envconfig:"PRODUCTS_CACHE_VERIFY_INTERVAL" default:"10m"`
+	// ProductsCacheVerifySampleSize число случайных записей для сверки. 0 — полная сверка
+	ProductsCacheVerifySampleSize int  `envconfig:"PRODUCTS_CACHE_VERIFY_SAMPLE_SIZE" default:"0"`
+	ProductsCacheVerifySelfHeal   bool `envconfig:"PRODUCTS_CACHE_VERIFY_SELF_HEAL" default:"false"`
+	// ProductsCacheSnapshotPath путь к файлу снапшота кеша. Пустое значение отключает снапшоты
+	ProductsCacheSnapshotPath string `envconfig:"PRODUCTS_CACHE_SNAPSHOT_PATH"`
+	// ProductsCacheSnapshotInterval период сохранения снапшота. 0 — снапшот сохраняется только при остановке
+	ProductsCacheSnapshotInterval time.Duration `envconfig:"PRODUCTS_CACHE_SNAPSHOT_INTERVAL" default:"5m"`
+	// CategoriesCacheTtl период перечитывания дерева категорий из базы
+	CategoriesCacheTtl time.Duration `envconfig:"CATEGORIES_CACHE_TTL" default:"1m"`
+	// DefaultCurrency валюта по ISO 4217 для тестовых данных и миграции цен без валюты
+	DefaultCurrency string `envconfig:"DEFAULT_CURRENCY" default:"RUB"`
+	// DefaultLocale локаль полей name и description продукта. Отдается, если запрошенная локаль не поддерживается
+	DefaultLocale    string   `envconfig:"DEFAULT_LOCALE" default:"ru"`
+	SupportedLocales []string `envconfig:"SUPPORTED_LOCALES" default:"ru,en"`
+	// RequiredLocales локали, без перевода на которые продукт нельзя сохранить
+	RequiredLocales []string `envconfig:"REQUIRED_LOCALES"`
+	// ProductValidationRulesPath путь к JSON файлу с правилами валидации продуктов витрины. Пустое значение —
+	// только встроенные проверки
+	ProductValidationRulesPath string `envconfig:"PRODUCT_VALIDATION_RULES_PATH"`
+	// IdempotencyKeyTtl время хранения ответа на запрос с ключом идемпотентности
+	IdempotencyKeyTtl time.Duration `envconfig:"IDEMPOTENCY_KEY_TTL" default:"24h"`
+	// IdempotencyLockTimeout время, после которого незавершенный запрос с тем же ключом выполняется повторно
+	IdempotencyLockTimeout time.Duration `envconfig:"IDEMPOTENCY_LOCK_TIMEOUT" default:"1m"`
+	// RequestDefaultTimeout дедлайн вызова gRPC, если клиент его не передал. 0 — без дедлайна
+	RequestDefaultTimeout time.Duration `envconfig:"REQUEST_DEFAULT_TIMEOUT" default:"10s"`
+	// RequestMaxTimeout верхняя граница дедлайна, переданного клиентом. 0 — без ограничения
+	RequestMaxTimeout time.Duration `envconfig:"REQUEST_MAX_TIMEOUT" default:"1m"`
 }
 
 type DatabaseConfig struct {
 	DSN  string `envconfig:"DATABASE_DSN" required:"true"`
 	Name string `envconfig:"DATABASE_NAME" required:"true"`
+	// ReadTimeout, WriteTimeout ограничения времени чтения и изменения одного документа. 0 — без ограничения
+	ReadTimeout  time.Duration `envconfig:"DATABASE_READ_TIMEOUT" default:"5s"`
+	WriteTimeout time.Duration `envconfig:"DATABASE_WRITE_TIMEOUT" default:"10s"`
+	// BulkTimeout ограничение времени запросов ко всей коллекции, например чтения продуктов для кеша
+	BulkTimeout time.Duration `envconfig:"DATABASE_BULK_TIMEOUT" default:"1m"`
+}
+
+// RedisConfig общий кеш продуктов и шина инвалидации между репликами. Пустой REDIS_ADDR — кеш только в памяти
+// процесса, реплики согласуются лишь периодической синхронизацией
+type RedisConfig struct {
+	Addr     string `envconfig:"REDIS_ADDR"`
+	Password string `envconfig:"REDIS_PASSWORD"`
+	DB       int    `envconfig:"REDIS_DB" default:"0"`
+	// ProductsCacheKey ключ хеша с продуктами
+	ProductsCacheKey string `envconfig:"REDIS_PRODUCTS_CACHE_KEY" default:"products"`
+	// InvalidationChannel канал pub/sub, через который реплики оповещают друг друга об изменении продуктов
+	InvalidationChannel string `envconfig:"REDIS_INVALIDATION_CHANNEL" default:"products:invalidation"`
+	// Timeout ограничение времени одной операции с Redis
+	Timeout time.Duration `envconfig:"REDIS_TIMEOUT" default:"1s"`
 }
 
 type TracingConfig struct {
@@ -32,11 +92,55 @@ type SentryConfig struct {
 	Env string `envconfig:"SENTRY_ENV" default:"dev"`
 }
 
+// AuthConfig настройки аутентификации запросов к ProductService
+type AuthConfig struct {
+	// Disabled отключает аутентификацию. Только для локальной разработки
+	Disabled bool `envconfig:"AUTH_DISABLED" default:"false"`
+	// APIKeys статические ключи в формате name:key,name2:key2. Имя ключа используется как principal
+	APIKeys map[string]string `envconfig:"AUTH_API_KEYS"`
+	// JWTKeyFile путь к JWKS или PEM файлу с публичными ключами для проверки подписи JWT
+	JWTKeyFile  string `envconfig:"AUTH_JWT_KEY_FILE"`
+	JWTIssuer   string `envconfig:"AUTH_JWT_ISSUER"`
+	JWTAudience string `envconfig:"AUTH_JWT_AUDIENCE"`
+	// PolicyPath путь к JSON файлу с ролями, необходимыми для вызова методов. Обязателен при включенной
+	// аутентификации
+	PolicyPath string `envconfig:"AUTH_POLICY_PATH"`
+}
+
+// RateLimitConfig ограничения частоты запросов одного клиента к методу в формате `<rps>/<burst>`
+type RateLimitConfig struct {
+	// Default ограничение методов без своего. 0 — без ограничений
+	Default string `envconfig:"RATE_LIMIT_DEFAULT" default:"50/100"`
+	// Methods ограничения отдельных методов: /ProductService/DeleteProduct:1/5,/ProductService/GetProducts:20/40
+	Methods map[string]string `envconfig:"RATE_LIMIT_METHODS"`
+}
+
+// TLSConfig сертификаты gRPC и REST серверов и шлюза. Пустой TLS_CERT_FILE — соединения без шифрования
+type TLSConfig struct {
+	CertFile string `envconfig:"TLS_CERT_FILE"`
+	KeyFile  string `envconfig:"TLS_KEY_FILE"`
+	// ClientCAFile CA клиентских сертификатов. Если задан, gRPC сервер требует mutual TLS
+	ClientCAFile string `envconfig:"TLS_CLIENT_CA_FILE"`
+	// GatewayCAFile CA, которым шлюз проверяет сертификат gRPC сервера. Пустое значение — системные CA
+	GatewayCAFile string `envconfig:"TLS_GATEWAY_CA_FILE"`
+	// GatewayCertFile, GatewayKeyFile клиентский сертификат шлюза для mutual TLS
+	GatewayCertFile string `envconfig:"TLS_GATEWAY_CERT_FILE"`
+	GatewayKeyFile  string `envconfig:"TLS_GATEWAY_KEY_FILE"`
+	// GatewayServerName имя, с которым шлюз сверяет сертификат gRPC сервера
+	GatewayServerName string `envconfig:"TLS_GATEWAY_SERVER_NAME" default:"localhost"`
+	// ReloadInterval период проверки файлов сертификатов на изменение. 0 — сертификаты не перечитываются
+	ReloadInterval time.Duration `envconfig:"TLS_RELOAD_INTERVAL" default:"30s"`
+}
+
 type Config struct {
-	API      *APIConfig
-	Database *DatabaseConfig
-	Tracing  *TracingConfig
-	Sentry   *SentryConfig
+	API       *APIConfig
+	Database  *DatabaseConfig
+	Auth      *AuthConfig
+	TLS       *TLSConfig
+	RateLimit *RateLimitConfig
+	Redis     *RedisConfig
+	Tracing   *TracingConfig
+	Sentry    *SentryConfig
 }
 
 var (
@@ -57,8 +161,31 @@ func GetConfig(envfiles ...string) (*Config, error) {
 			err = fmt.Errorf("error parse config from env variables: %w", err)
 			return
 		}
+
+		if err = c.validate(); err != nil {
+			err = fmt.Errorf("invalid config: %w", err)
+			return
+		}
 		config = &c
 	})
 
 	return config, err
 }
+
+// validate проверяет согласованность значений, которые envconfig проверить не может.
+func (c *Config) validate() error {
+	// нулевые задержки превращают синхронизацию кеша в цикл запросов к базе без пауз
+	if c.API.ProductsCacheTtl <= 0 {
+		return errors.New("PRODUCTS_CACHE_TTL must be greater than 0")
+	}
+
+	if c.API.ProductsCacheSyncMinBackoff <= 0 {
+		return errors.New("PRODUCTS_CACHE_SYNC_MIN_BACKOFF must be greater than 0")
+	}
+
+	if c.API.ProductsCacheSyncMaxBackoff < c.API.ProductsCacheSyncMinBackoff {
+		return errors.New("PRODUCTS_CACHE_SYNC_MAX_BACKOFF must not be less than PRODUCTS_CACHE_SYNC_MIN_BACKOFF")
+	}
+
+	return nil
+}
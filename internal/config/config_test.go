@@ -34,14 +34,64 @@ func TestGetConfig_Defaults(t *testing.T) {
 	c, err := GetConfig()
 	require.NoError(t, err)
 
-	assert.Equal(t, false, c.API.Debug)                    // default
-	assert.Equal(t, "0.0.0.0", c.API.Host)                 // default
-	assert.Equal(t, "8000", c.API.RestPort)                // default
-	assert.Equal(t, "50051", c.API.GrpcPort)               // default
-	assert.Equal(t, time.Minute*1, c.API.ProductsCacheTtl) // default
+	assert.Equal(t, false, c.API.Debug)                                 // default
+	assert.Equal(t, "0.0.0.0", c.API.Host)                              // default
+	assert.Equal(t, "8000", c.API.RestPort)                             // default
+	assert.Equal(t, "50051", c.API.GrpcPort)                            // default
+	assert.Equal(t, time.Minute*1, c.API.ProductsCacheTtl)              // default
+	assert.Equal(t, time.Second*1, c.API.ProductsCacheSyncMinBackoff)   // default
+	assert.Equal(t, time.Minute*1, c.API.ProductsCacheSyncMaxBackoff)   // default
+	assert.Equal(t, time.Second*30, c.API.ProductsCacheSyncTimeout)     // default
+	assert.Equal(t, 0.1, c.API.ProductsCacheSyncJitter)                 // default
+	assert.Equal(t, time.Minute*5, c.API.ProductsCacheMaxStaleness)     // default
+	assert.Equal(t, time.Second*30, c.API.ProductsNegativeCacheTtl)     // default
+	assert.Equal(t, time.Minute*10, c.API.ProductsCacheVerifyInterval)  // default
+	assert.Equal(t, 0, c.API.ProductsCacheVerifySampleSize)             // default
+	assert.Equal(t, false, c.API.ProductsCacheVerifySelfHeal)           // default
+	assert.Equal(t, "", c.API.ProductsCacheSnapshotPath)                // default
+	assert.Equal(t, time.Minute*5, c.API.ProductsCacheSnapshotInterval) // default
+	assert.Equal(t, time.Minute, c.API.CategoriesCacheTtl)              // default
+	assert.Equal(t, "RUB", c.API.DefaultCurrency)                       // default
+	assert.Equal(t, "ru", c.API.DefaultLocale)                          // default
+	assert.Equal(t, []string{"ru", "en"}, c.API.SupportedLocales)       // default
+	assert.Empty(t, c.API.RequiredLocales)                              // default
+	assert.Equal(t, "", c.API.ProductValidationRulesPath)               // default
+	assert.Equal(t, time.Hour*24, c.API.IdempotencyKeyTtl)              // default
+	assert.Equal(t, time.Minute, c.API.IdempotencyLockTimeout)          // default
+	assert.Equal(t, time.Second*10, c.API.RequestDefaultTimeout)        // default
+	assert.Equal(t, time.Minute, c.API.RequestMaxTimeout)               // default
 
 	assert.Equal(t, requiredVars["DATABASE_DSN"], c.Database.DSN)   // from env
 	assert.Equal(t, requiredVars["DATABASE_NAME"], c.Database.Name) // from env
+	assert.Equal(t, time.Second*5, c.Database.ReadTimeout)          // default
+	assert.Equal(t, time.Second*10, c.Database.WriteTimeout)        // default
+	assert.Equal(t, time.Minute, c.Database.BulkTimeout)            // default
+
+	assert.Equal(t, false, c.Auth.Disabled) // default
+	assert.Empty(t, c.Auth.APIKeys)         // default
+	assert.Equal(t, "", c.Auth.JWTKeyFile)  // default
+	assert.Equal(t, "", c.Auth.JWTIssuer)   // default
+	assert.Equal(t, "", c.Auth.JWTAudience) // default
+	assert.Equal(t, "", c.Auth.PolicyPath)  // default
+
+	assert.Equal(t, "", c.TLS.CertFile)                   // default
+	assert.Equal(t, "", c.TLS.KeyFile)                    // default
+	assert.Equal(t, "", c.TLS.ClientCAFile)               // default
+	assert.Equal(t, "", c.TLS.GatewayCAFile)              // default
+	assert.Equal(t, "", c.TLS.GatewayCertFile)            // default
+	assert.Equal(t, "", c.TLS.GatewayKeyFile)             // default
+	assert.Equal(t, "localhost", c.TLS.GatewayServerName) // default
+	assert.Equal(t, time.Second*30, c.TLS.ReloadInterval) // default
+
+	assert.Equal(t, "50/100", c.RateLimit.Default) // default
+	assert.Empty(t, c.RateLimit.Methods)           // default
+
+	assert.Equal(t, "", c.Redis.Addr)                                     // default
+	assert.Equal(t, "", c.Redis.Password)                                 // default
+	assert.Equal(t, 0, c.Redis.DB)                                        // default
+	assert.Equal(t, "products", c.Redis.ProductsCacheKey)                 // default
+	assert.Equal(t, "products:invalidation", c.Redis.InvalidationChannel) // default
+	assert.Equal(t, time.Second, c.Redis.Timeout)                         // default
 
 	assert.Equal(t, "", c.Tracing.ExporterAddress) // default
 	assert.Equal(t, "", c.Tracing.ExporterPort)    // default
@@ -52,17 +102,63 @@ func TestGetConfig_Defaults(t *testing.T) {
 
 func TestGetConfig_RewriteDefaults(t *testing.T) {
 	env := map[string]string{
-		"DEBUG":                    "true",
-		"HOST":                     "localhost",
-		"REST_PORT":                "5555",
-		"GRPC_PORT":                "6666",
-		"PRODUCTS_CACHE_TTL":       "12h",
-		"DATABASE_DSN":             "mongodb://test@test:localhost:27017/?replicaSet=rs0",
-		"DATABASE_NAME":            "test",
-		"TRACING_EXPORTER_ADDRESS": "localhost",
-		"TRACING_EXPORTER_PORT":    "16686",
-		"SENTRY_DSN":               "https://sentry.com/test",
-		"SENTRY_ENV":               "stage",
+		"DEBUG":                             "true",
+		"HOST":                              "localhost",
+		"REST_PORT":                         "5555",
+		"GRPC_PORT":                         "6666",
+		"PRODUCTS_CACHE_TTL":                "12h",
+		"PRODUCTS_CACHE_SYNC_MIN_BACKOFF":   "2s",
+		"PRODUCTS_CACHE_SYNC_MAX_BACKOFF":   "30s",
+		"PRODUCTS_CACHE_SYNC_TIMEOUT":       "2m",
+		"PRODUCTS_CACHE_SYNC_JITTER":        "0.2",
+		"PRODUCTS_CACHE_MAX_STALENESS":      "1h",
+		"PRODUCTS_NEGATIVE_CACHE_TTL":       "5m",
+		"PRODUCTS_CACHE_VERIFY_INTERVAL":    "1h",
+		"PRODUCTS_CACHE_VERIFY_SAMPLE_SIZE": "500",
+		"PRODUCTS_CACHE_VERIFY_SELF_HEAL":   "true",
+		"PRODUCTS_CACHE_SNAPSHOT_PATH":      "/tmp/products.snapshot",
+		"PRODUCTS_CACHE_SNAPSHOT_INTERVAL":  "10m",
+		"CATEGORIES_CACHE_TTL":              "5m",
+		"DEFAULT_CURRENCY":                  "USD",
+		"DEFAULT_LOCALE":                    "en",
+		"SUPPORTED_LOCALES":                 "en,ru,kk",
+		"REQUIRED_LOCALES":                  "ru",
+		"PRODUCT_VALIDATION_RULES_PATH":     "/etc/app/rules.json",
+		"IDEMPOTENCY_KEY_TTL":               "1h",
+		"IDEMPOTENCY_LOCK_TIMEOUT":          "30s",
+		"REQUEST_DEFAULT_TIMEOUT":           "5s",
+		"REQUEST_MAX_TIMEOUT":               "0",
+		"DATABASE_DSN":                      "mongodb://test@test:localhost:27017/?replicaSet=rs0",
+		"DATABASE_NAME":                     "test",
+		"DATABASE_READ_TIMEOUT":             "1s",
+		"DATABASE_WRITE_TIMEOUT":            "2s",
+		"DATABASE_BULK_TIMEOUT":             "5m",
+		"AUTH_DISABLED":                     "true",
+		"AUTH_API_KEYS":                     "admin:secret,importer:secret2",
+		"AUTH_JWT_KEY_FILE":                 "/etc/app/jwks.json",
+		"AUTH_JWT_ISSUER":                   "https://auth.example.com",
+		"AUTH_JWT_AUDIENCE":                 "products",
+		"AUTH_POLICY_PATH":                  "/etc/app/policy.json",
+		"TLS_CERT_FILE":                     "/etc/tls/tls.crt",
+		"TLS_KEY_FILE":                      "/etc/tls/tls.key",
+		"TLS_CLIENT_CA_FILE":                "/etc/tls/clients-ca.crt",
+		"TLS_GATEWAY_CA_FILE":               "/etc/tls/ca.crt",
+		"TLS_GATEWAY_CERT_FILE":             "/etc/tls/gateway.crt",
+		"TLS_GATEWAY_KEY_FILE":              "/etc/tls/gateway.key",
+		"TLS_GATEWAY_SERVER_NAME":           "products.internal",
+		"TLS_RELOAD_INTERVAL":               "1m",
+		"RATE_LIMIT_DEFAULT":                "0",
+		"RATE_LIMIT_METHODS":                "/ProductService/DeleteProduct:1/5,/ProductService/GetProducts:0.5",
+		"REDIS_ADDR":                        "redis:6379",
+		"REDIS_PASSWORD":                    "secret",
+		"REDIS_DB":                          "2",
+		"REDIS_PRODUCTS_CACHE_KEY":          "shop:products",
+		"REDIS_INVALIDATION_CHANNEL":        "shop:products:invalidation",
+		"REDIS_TIMEOUT":                     "500ms",
+		"TRACING_EXPORTER_ADDRESS":          "localhost",
+		"TRACING_EXPORTER_PORT":             "16686",
+		"SENTRY_DSN":                        "https://sentry.com/test",
+		"SENTRY_ENV":                        "stage",
 	}
 
 	defer setupTest(t, env)()
@@ -75,9 +171,62 @@ func TestGetConfig_RewriteDefaults(t *testing.T) {
 	assert.Equal(t, env["GRPC_PORT"], c.API.GrpcPort)
 	assert.Equal(t, env["REST_PORT"], c.API.RestPort)
 	assert.Equal(t, time.Hour*12, c.API.ProductsCacheTtl)
+	assert.Equal(t, time.Second*2, c.API.ProductsCacheSyncMinBackoff)
+	assert.Equal(t, time.Second*30, c.API.ProductsCacheSyncMaxBackoff)
+	assert.Equal(t, time.Minute*2, c.API.ProductsCacheSyncTimeout)
+	assert.Equal(t, 0.2, c.API.ProductsCacheSyncJitter)
+	assert.Equal(t, time.Hour, c.API.ProductsCacheMaxStaleness)
+	assert.Equal(t, time.Minute*5, c.API.ProductsNegativeCacheTtl)
+	assert.Equal(t, time.Hour, c.API.ProductsCacheVerifyInterval)
+	assert.Equal(t, 500, c.API.ProductsCacheVerifySampleSize)
+	assert.Equal(t, true, c.API.ProductsCacheVerifySelfHeal)
+	assert.Equal(t, env["PRODUCTS_CACHE_SNAPSHOT_PATH"], c.API.ProductsCacheSnapshotPath)
+	assert.Equal(t, time.Minute*10, c.API.ProductsCacheSnapshotInterval)
+	assert.Equal(t, time.Minute*5, c.API.CategoriesCacheTtl)
+	assert.Equal(t, env["DEFAULT_CURRENCY"], c.API.DefaultCurrency)
+	assert.Equal(t, env["DEFAULT_LOCALE"], c.API.DefaultLocale)
+	assert.Equal(t, []string{"en", "ru", "kk"}, c.API.SupportedLocales)
+	assert.Equal(t, []string{"ru"}, c.API.RequiredLocales)
+	assert.Equal(t, env["PRODUCT_VALIDATION_RULES_PATH"], c.API.ProductValidationRulesPath)
+	assert.Equal(t, time.Hour, c.API.IdempotencyKeyTtl)
+	assert.Equal(t, time.Second*30, c.API.IdempotencyLockTimeout)
+	assert.Equal(t, time.Second*5, c.API.RequestDefaultTimeout)
+	assert.Equal(t, time.Duration(0), c.API.RequestMaxTimeout)
 
 	assert.Equal(t, env["DATABASE_DSN"], c.Database.DSN)
 	assert.Equal(t, env["DATABASE_NAME"], c.Database.Name)
+	assert.Equal(t, time.Second, c.Database.ReadTimeout)
+	assert.Equal(t, time.Second*2, c.Database.WriteTimeout)
+	assert.Equal(t, time.Minute*5, c.Database.BulkTimeout)
+
+	assert.Equal(t, true, c.Auth.Disabled)
+	assert.Equal(t, map[string]string{"admin": "secret", "importer": "secret2"}, c.Auth.APIKeys)
+	assert.Equal(t, env["AUTH_JWT_KEY_FILE"], c.Auth.JWTKeyFile)
+	assert.Equal(t, env["AUTH_JWT_ISSUER"], c.Auth.JWTIssuer)
+	assert.Equal(t, env["AUTH_JWT_AUDIENCE"], c.Auth.JWTAudience)
+	assert.Equal(t, env["AUTH_POLICY_PATH"], c.Auth.PolicyPath)
+
+	assert.Equal(t, env["TLS_CERT_FILE"], c.TLS.CertFile)
+	assert.Equal(t, env["TLS_KEY_FILE"], c.TLS.KeyFile)
+	assert.Equal(t, env["TLS_CLIENT_CA_FILE"], c.TLS.ClientCAFile)
+	assert.Equal(t, env["TLS_GATEWAY_CA_FILE"], c.TLS.GatewayCAFile)
+	assert.Equal(t, env["TLS_GATEWAY_CERT_FILE"], c.TLS.GatewayCertFile)
+	assert.Equal(t, env["TLS_GATEWAY_KEY_FILE"], c.TLS.GatewayKeyFile)
+	assert.Equal(t, env["TLS_GATEWAY_SERVER_NAME"], c.TLS.GatewayServerName)
+	assert.Equal(t, time.Minute, c.TLS.ReloadInterval)
+
+	assert.Equal(t, env["RATE_LIMIT_DEFAULT"], c.RateLimit.Default)
+	assert.Equal(t, map[string]string{
+		"/ProductService/DeleteProduct": "1/5",
+		"/ProductService/GetProducts":   "0.5",
+	}, c.RateLimit.Methods)
+
+	assert.Equal(t, env["REDIS_ADDR"], c.Redis.Addr)
+	assert.Equal(t, env["REDIS_PASSWORD"], c.Redis.Password)
+	assert.Equal(t, 2, c.Redis.DB)
+	assert.Equal(t, env["REDIS_PRODUCTS_CACHE_KEY"], c.Redis.ProductsCacheKey)
+	assert.Equal(t, env["REDIS_INVALIDATION_CHANNEL"], c.Redis.InvalidationChannel)
+	assert.Equal(t, time.Millisecond*500, c.Redis.Timeout)
 
 	assert.Equal(t, env["TRACING_EXPORTER_ADDRESS"], c.Tracing.ExporterAddress)
 	assert.Equal(t, env["TRACING_EXPORTER_PORT"], c.Tracing.ExporterPort)
@@ -86,6 +235,29 @@ func TestGetConfig_RewriteDefaults(t *testing.T) {
 	assert.Equal(t, env["SENTRY_ENV"], c.Sentry.Env)
 }
 
+func TestGetConfig_InvalidCacheSync(t *testing.T) {
+	testCases := map[string]map[string]string{
+		"zero ttl":              {"PRODUCTS_CACHE_TTL": "0"},
+		"zero min backoff":      {"PRODUCTS_CACHE_SYNC_MIN_BACKOFF": "0"},
+		"max backoff below min": {"PRODUCTS_CACHE_SYNC_MIN_BACKOFF": "10s", "PRODUCTS_CACHE_SYNC_MAX_BACKOFF": "1s"},
+		"negative ttl":          {"PRODUCTS_CACHE_TTL": "-1m"},
+		"negative min backoff":  {"PRODUCTS_CACHE_SYNC_MIN_BACKOFF": "-1s"},
+	}
+
+	for name, env := range testCases {
+		t.Run(name, func(t *testing.T) {
+			env["DATABASE_NAME"] = "test_db"
+			env["DATABASE_DSN"] = "mongodb://localhost:27017"
+
+			defer setupTest(t, env)()
+
+			c, err := GetConfig()
+			require.Error(t, err)
+			require.Nil(t, c)
+		})
+	}
+}
+
 func TestGetConfig_MissingRequired(t *testing.T) {
 	defer setupTest(t, map[string]string{})()
 
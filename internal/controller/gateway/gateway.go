@@ -0,0 +1,169 @@
+package gateway
+
+import (
+	"context"
+	"errors"
+	"io"
+	"math"
+	"net/http"
+	"strconv"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"google.golang.org/genproto/googleapis/rpc/code"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/grpclog"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/qulaz/artforintrovert-test/gen/api/v1"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/idempotency"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/requestid"
+)
+
+// fallbackErrorBody отдается, если не удалось сериализовать саму ошибку
+const fallbackErrorBody = `{"code": "INTERNAL", "message": "failed to marshal error message"}`
+
+// NewServeMux создает mux grpc-gateway, который отдает ошибки в едином формате api.Error
+// и пробрасывает идентификатор запроса, API ключ и ключ идемпотентности в метаданные gRPC.
+// Идентификатор в контекст запроса кладет requestid.HTTPMiddleware.
+func NewServeMux(opts ...runtime.ServeMuxOption) *runtime.ServeMux {
+	return runtime.NewServeMux(append(
+		[]runtime.ServeMuxOption{
+			runtime.WithErrorHandler(ErrorHandler),
+			runtime.WithRoutingErrorHandler(RoutingErrorHandler),
+			runtime.WithMetadata(forwardRequestId),
+			runtime.WithIncomingHeaderMatcher(incomingHeaderMatcher),
+			runtime.WithOutgoingHeaderMatcher(outgoingHeaderMatcher),
+		},
+		opts...,
+	)...)
+}
+
+// ErrorHandler пишет ошибку в формате api.Error. HTTP статус выводится из кода gRPC,
+// при наличии google.rpc.RetryInfo выставляется заголовок Retry-After.
+func ErrorHandler(
+	ctx context.Context,
+	mux *runtime.ServeMux,
+	marshaler runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	err error,
+) {
+	var customStatus *runtime.HTTPStatusError
+	if errors.As(err, &customStatus) {
+		err = customStatus.Err
+	}
+
+	st := status.Convert(err)
+
+	httpStatus := runtime.HTTPStatusFromCode(st.Code())
+	if customStatus != nil {
+		httpStatus = customStatus.HTTPStatus
+	}
+
+	body := &api.Error{
+		Code:      code.Code(st.Code()).String(),
+		Message:   st.Message(),
+		Details:   st.Proto().GetDetails(),
+		RequestId: requestid.FromContext(r.Context()),
+	}
+
+	w.Header().Del("Trailer")
+	w.Header().Del("Transfer-Encoding")
+	w.Header().Set("Content-Type", marshaler.ContentType(body))
+
+	buf, err := marshaler.Marshal(body)
+	if err != nil {
+		grpclog.Infof("Failed to marshal error message %q: %v", st, err)
+		w.WriteHeader(http.StatusInternalServerError)
+
+		if _, err := io.WriteString(w, fallbackErrorBody); err != nil {
+			grpclog.Infof("Failed to write response: %v", err)
+		}
+
+		return
+	}
+
+	if retryAfter, ok := retryAfterSeconds(st); ok {
+		w.Header().Set("Retry-After", strconv.Itoa(retryAfter))
+	}
+
+	w.WriteHeader(httpStatus)
+
+	if _, err := w.Write(buf); err != nil {
+		grpclog.Infof("Failed to write response: %v", err)
+	}
+}
+
+// RoutingErrorHandler отдает ошибки маршрутизации, например неизвестный путь или метод,
+// в том же формате, что и ошибки gRPC, сохраняя исходный HTTP статус.
+func RoutingErrorHandler(
+	ctx context.Context,
+	mux *runtime.ServeMux,
+	marshaler runtime.Marshaler,
+	w http.ResponseWriter,
+	r *http.Request,
+	httpStatus int,
+) {
+	grpcCode := codes.Internal
+
+	switch httpStatus {
+	case http.StatusBadRequest:
+		grpcCode = codes.InvalidArgument
+	case http.StatusNotFound:
+		grpcCode = codes.NotFound
+	case http.StatusMethodNotAllowed:
+		grpcCode = codes.Unimplemented
+	}
+
+	runtime.HTTPError(ctx, mux, marshaler, w, r, &runtime.HTTPStatusError{
+		HTTPStatus: httpStatus,
+		Err:        status.Error(grpcCode, http.StatusText(httpStatus)),
+	})
+}
+
+func forwardRequestId(_ context.Context, r *http.Request) metadata.MD {
+	requestId := requestid.FromContext(r.Context())
+	if requestId == "" {
+		return nil
+	}
+
+	return metadata.Pairs(requestid.MetadataKey, requestId)
+}
+
+// forwardedHeaders заголовки, которые передаются в метаданные gRPC без префикса Grpc-Metadata-.
+// Authorization шлюз пробрасывает сам.
+var forwardedHeaders = map[string]string{
+	"X-Api-Key":       auth.APIKeyMetadataKey,
+	"Idempotency-Key": idempotency.MetadataKey,
+}
+
+func incomingHeaderMatcher(key string) (string, bool) {
+	if metadataKey, ok := forwardedHeaders[http.CanonicalHeaderKey(key)]; ok {
+		return metadataKey, true
+	}
+
+	return runtime.DefaultHeaderMatcher(key)
+}
+
+// outgoingHeaderMatcher не дублирует x-request-id из ответа gRPC: клиенту его уже вернул requestid.HTTPMiddleware,
+// а сервер получает тот же идентификатор от шлюза.
+func outgoingHeaderMatcher(key string) (string, bool) {
+	if key == requestid.MetadataKey {
+		return "", false
+	}
+
+	return runtime.MetadataHeaderPrefix + key, true
+}
+
+func retryAfterSeconds(st *status.Status) (int, bool) {
+	for _, detail := range st.Details() {
+		if retryInfo, ok := detail.(*errdetails.RetryInfo); ok {
+			return int(math.Ceil(retryInfo.GetRetryDelay().AsDuration().Seconds())), true
+		}
+	}
+
+	return 0, false
+}
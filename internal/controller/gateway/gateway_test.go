@@ -0,0 +1,155 @@
+package gateway
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/grpc-ecosystem/grpc-gateway/v2/runtime"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/idempotency"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/requestid"
+)
+
+type errorBody struct {
+	Code      string                   `json:"code"`
+	Message   string                   `json:"message"`
+	Details   []map[string]interface{} `json:"details"`
+	RequestId string                   `json:"requestId"`
+}
+
+func newTestServer(t *testing.T, err error) http.Handler {
+	t.Helper()
+
+	mux := NewServeMux()
+	require.NoError(t, mux.HandlePath(
+		http.MethodGet,
+		"/products/{id}",
+		func(w http.ResponseWriter, r *http.Request, pathParams map[string]string) {
+			_, outboundMarshaler := runtime.MarshalerForRequest(mux, r)
+			grpcErr := commonerr.GrpcErrHandler(context.Background(), err, nil)
+			runtime.HTTPError(r.Context(), mux, outboundMarshaler, w, r, grpcErr)
+		},
+	))
+
+	return requestid.HTTPMiddleware(mux)
+}
+
+func doRequest(t *testing.T, handler http.Handler, method string, path string) (*httptest.ResponseRecorder, errorBody) {
+	t.Helper()
+
+	req := httptest.NewRequest(method, path, nil)
+	req.Header.Set(requestid.HeaderName, "test-request")
+
+	rec := httptest.NewRecorder()
+	handler.ServeHTTP(rec, req)
+
+	var body errorBody
+	require.NoError(t, json.Unmarshal(rec.Body.Bytes(), &body), rec.Body.String())
+
+	return rec, body
+}
+
+func TestErrorHandler_Envelope(t *testing.T) {
+	err := commonerr.NewValidationError([]commonerr.FieldViolation{{Field: "name", Description: "name is required"}})
+
+	rec, body := doRequest(t, newTestServer(t, err), http.MethodGet, "/products/1")
+
+	assert.Equal(t, http.StatusBadRequest, rec.Code)
+	assert.Equal(t, "application/json", rec.Header().Get("Content-Type"))
+	assert.Equal(t, "INVALID_ARGUMENT", body.Code)
+	assert.Equal(t, "validation failed: name: name is required", body.Message)
+	assert.Equal(t, "test-request", body.RequestId)
+	require.Len(t, body.Details, 2)
+	assert.Equal(t, "type.googleapis.com/google.rpc.ErrorInfo", body.Details[0]["@type"])
+	assert.Equal(t, "type.googleapis.com/google.rpc.BadRequest", body.Details[1]["@type"])
+}
+
+func TestErrorHandler_HTTPStatuses(t *testing.T) {
+	testCases := []struct {
+		err        error
+		httpStatus int
+		code       string
+	}{
+		{err: commonerr.NewNotFoundError("missing"), httpStatus: http.StatusNotFound, code: "NOT_FOUND"},
+		{err: commonerr.NewAlreadyExistsError("exists"), httpStatus: http.StatusConflict, code: "ALREADY_EXISTS"},
+		{err: commonerr.NewConflictError("conflict"), httpStatus: http.StatusConflict, code: "ABORTED"},
+		{
+			err:        commonerr.NewFailedPreconditionError("precondition"),
+			httpStatus: http.StatusBadRequest,
+			code:       "FAILED_PRECONDITION",
+		},
+		{err: commonerr.NewPermissionDeniedError("denied"), httpStatus: http.StatusForbidden, code: "PERMISSION_DENIED"},
+		{err: commonerr.NewUnauthenticatedError("who"), httpStatus: http.StatusUnauthorized, code: "UNAUTHENTICATED"},
+		{err: commonerr.NewUnavailableError("down"), httpStatus: http.StatusServiceUnavailable, code: "UNAVAILABLE"},
+		{
+			err:        commonerr.NewDeadlineExceededError("slow"),
+			httpStatus: http.StatusGatewayTimeout,
+			code:       "DEADLINE_EXCEEDED",
+		},
+		{err: commonerr.NewRateLimitedError("slow down"), httpStatus: http.StatusTooManyRequests, code: "RESOURCE_EXHAUSTED"},
+		{err: commonerr.NewUnknownAppErrorf("boom"), httpStatus: http.StatusInternalServerError, code: "INTERNAL"},
+	}
+
+	for _, tc := range testCases {
+		rec, body := doRequest(t, newTestServer(t, tc.err), http.MethodGet, "/products/1")
+
+		assert.Equal(t, tc.httpStatus, rec.Code, tc.err.Error())
+		assert.Equal(t, tc.code, body.Code, tc.err.Error())
+	}
+}
+
+func TestErrorHandler_RetryAfter(t *testing.T) {
+	err := commonerr.NewConflictError("modified concurrently").WithRetryDelay(1500 * time.Millisecond)
+
+	rec, _ := doRequest(t, newTestServer(t, err), http.MethodGet, "/products/1")
+
+	assert.Equal(t, http.StatusConflict, rec.Code)
+	assert.Equal(t, "2", rec.Header().Get("Retry-After"))
+}
+
+func TestRoutingErrorHandler(t *testing.T) {
+	handler := newTestServer(t, nil)
+
+	rec, body := doRequest(t, handler, http.MethodGet, "/unknown")
+	assert.Equal(t, http.StatusNotFound, rec.Code)
+	assert.Equal(t, "NOT_FOUND", body.Code)
+	assert.Equal(t, "test-request", body.RequestId)
+
+	rec, body = doRequest(t, handler, http.MethodDelete, "/products/1")
+	assert.Equal(t, http.StatusMethodNotAllowed, rec.Code)
+	assert.Equal(t, "UNIMPLEMENTED", body.Code)
+}
+
+func TestOutgoingHeaderMatcher(t *testing.T) {
+	_, ok := outgoingHeaderMatcher(requestid.MetadataKey)
+	assert.False(t, ok)
+
+	header, ok := outgoingHeaderMatcher("x-actor")
+	assert.True(t, ok)
+	assert.Equal(t, "Grpc-Metadata-x-actor", header)
+}
+
+func TestIncomingHeaderMatcher(t *testing.T) {
+	key, ok := incomingHeaderMatcher("X-Api-Key")
+	assert.True(t, ok)
+	assert.Equal(t, auth.APIKeyMetadataKey, key)
+
+	key, ok = incomingHeaderMatcher("idempotency-key")
+	assert.True(t, ok)
+	assert.Equal(t, idempotency.MetadataKey, key)
+
+	key, ok = incomingHeaderMatcher("Grpc-Metadata-X-Actor")
+	assert.True(t, ok)
+	assert.Equal(t, "X-Actor", key)
+
+	_, ok = incomingHeaderMatcher("X-Unknown")
+	assert.False(t, ok)
+}
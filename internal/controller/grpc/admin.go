@@ -0,0 +1,62 @@
+package grpc
+
+import (
+	"context"
+	"time"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/qulaz/artforintrovert-test/gen/api/v1"
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+	"github.com/qulaz/artforintrovert-test/internal/usecase"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+var _ api.AdminServiceServer = (*AdminGrpcServer)(nil)
+
+type AdminGrpcServer struct {
+	api.UnimplementedAdminServiceServer
+	useCase usecase.Admin
+	logger  logging.ContextLogger
+}
+
+func NewAdminGrpcServer(useCase usecase.Admin, logger logging.ContextLogger) *AdminGrpcServer {
+	return &AdminGrpcServer{
+		UnimplementedAdminServiceServer: api.UnimplementedAdminServiceServer{},
+		useCase:                         useCase,
+		logger:                          logger,
+	}
+}
+
+func (a *AdminGrpcServer) ResyncProductsCache(ctx context.Context, _ *emptypb.Empty) (*emptypb.Empty, error) {
+	ctx, _ = a.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "AdminGrpcServer.ResyncProductsCache")
+	defer span.End()
+
+	if err := a.useCase.ResyncProductsCache(ctx); err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, nil)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (a *AdminGrpcServer) PurgeDeletedProducts(
+	ctx context.Context,
+	req *api.PurgeDeletedProductsRequest,
+) (*api.PurgeDeletedProductsResponse, error) {
+	ctx, _ = a.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "AdminGrpcServer.PurgeDeletedProducts")
+	defer span.End()
+
+	olderThan := time.Duration(req.GetOlderThanDays()) * time.Hour * 24
+
+	purged, err := a.useCase.PurgeDeletedProducts(ctx, olderThan)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, &commonerr.SentryInfo{
+			Contexts: map[string]interface{}{"request": req},
+		})
+	}
+
+	return &api.PurgeDeletedProductsResponse{Purged: purged}, nil
+}
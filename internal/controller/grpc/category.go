@@ -0,0 +1,214 @@
+package grpc
+
+import (
+	"context"
+
+	"google.golang.org/protobuf/types/known/emptypb"
+
+	"github.com/qulaz/artforintrovert-test/gen/api/v1"
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/entity/mapper"
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+	"github.com/qulaz/artforintrovert-test/internal/usecase"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/locale"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+var _ api.CategoryServiceServer = (*CategoryGrpcServer)(nil)
+
+type CategoryGrpcServer struct {
+	api.UnimplementedCategoryServiceServer
+	useCase usecase.Category
+	logger  logging.ContextLogger
+}
+
+func NewCategoryGrpcServer(useCase usecase.Category, logger logging.ContextLogger) *CategoryGrpcServer {
+	return &CategoryGrpcServer{
+		UnimplementedCategoryServiceServer: api.UnimplementedCategoryServiceServer{},
+		useCase:                            useCase,
+		logger:                             logger,
+	}
+}
+
+func (c *CategoryGrpcServer) GetCategoryTree(
+	ctx context.Context,
+	req *api.GetCategoryTreeRequest,
+) (*api.CategoryTree, error) {
+	ctx, _ = c.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "CategoryGrpcServer.GetCategoryTree")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	rootId, err := mapper.GrpcToOptionalId(req.GetRootId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	tree, err := c.useCase.GetCategoryTree(ctx, rootId)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return &api.CategoryTree{Roots: mapper.CategoryTreeToGrpc(tree)}, nil
+}
+
+func (c *CategoryGrpcServer) GetCategory(ctx context.Context, id *api.Id) (*api.Category, error) {
+	ctx, _ = c.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "CategoryGrpcServer.GetCategory")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"categoryId": id.Id},
+	}
+
+	categoryId, err := types.NewIdFromString(id.Id)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	category, err := c.useCase.GetCategory(ctx, categoryId)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.CategoryToGrpc(category), nil
+}
+
+func (c *CategoryGrpcServer) CreateCategory(
+	ctx context.Context,
+	req *api.CreateCategoryRequest,
+) (*api.Category, error) {
+	ctx, _ = c.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "CategoryGrpcServer.CreateCategory")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	parentId, err := mapper.GrpcToOptionalId(req.GetParentId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	category, err := c.useCase.CreateCategory(ctx, &entity.Category{ //nolint: exhaustruct
+		Name:     req.GetName(),
+		ParentId: parentId,
+	})
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.CategoryToGrpc(category), nil
+}
+
+func (c *CategoryGrpcServer) UpdateCategory(ctx context.Context, category *api.Category) (*api.Category, error) {
+	ctx, _ = c.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "CategoryGrpcServer.UpdateCategory")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"category": category},
+	}
+
+	categoryId, err := types.NewIdFromString(category.Id)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	updatedCategory, err := c.useCase.UpdateCategory(ctx, categoryId, &entity.Category{ //nolint: exhaustruct
+		Id:   categoryId,
+		Name: category.GetName(),
+	})
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.CategoryToGrpc(updatedCategory), nil
+}
+
+func (c *CategoryGrpcServer) DeleteCategory(ctx context.Context, id *api.Id) (*emptypb.Empty, error) {
+	ctx, _ = c.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "CategoryGrpcServer.DeleteCategory")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"categoryId": id.Id},
+	}
+
+	categoryId, err := types.NewIdFromString(id.Id)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	if err := c.useCase.DeleteCategory(ctx, categoryId); err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func (c *CategoryGrpcServer) MoveCategory(ctx context.Context, req *api.MoveCategoryRequest) (*api.Category, error) {
+	ctx, _ = c.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "CategoryGrpcServer.MoveCategory")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	categoryId, err := types.NewIdFromString(req.GetId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	parentId, err := mapper.GrpcToOptionalId(req.GetParentId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	category, err := c.useCase.MoveCategory(ctx, categoryId, parentId)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.CategoryToGrpc(category), nil
+}
+
+func (c *CategoryGrpcServer) GetCategoryProducts(
+	ctx context.Context,
+	req *api.GetCategoryProductsRequest,
+) (*api.ProductList, error) {
+	ctx, _ = c.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "CategoryGrpcServer.GetCategoryProducts")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	categoryId, err := types.NewIdFromString(req.GetId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	products, err := c.useCase.GetCategoryProducts(
+		ctx,
+		categoryId,
+		req.GetIncludeDescendants(),
+		uint(req.GetLimit()),
+		uint(req.GetOffset()),
+	)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return &api.ProductList{
+		Products: mapper.ManyProductsToGrpc(entity.LocalizeProducts(products, locale.FromContext(ctx))),
+	}, nil
+}
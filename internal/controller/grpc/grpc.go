@@ -12,6 +12,7 @@ import (
 	"github.com/qulaz/artforintrovert-test/internal/tracing"
 	"github.com/qulaz/artforintrovert-test/internal/types"
 	"github.com/qulaz/artforintrovert-test/internal/usecase"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/locale"
 	"github.com/qulaz/artforintrovert-test/pkg/logging"
 )
 
@@ -36,18 +37,47 @@ func (p *ProductGrpcServer) GetProducts(ctx context.Context, req *api.GetProduct
 	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.GetProducts")
 	defer span.End()
 
-	products, err := p.useCase.GetProducts(ctx, uint(req.GetLimit()), uint(req.GetOffset()))
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	filter, err := mapper.GrpcProductsRequestToFilter(req)
 	if err != nil {
-		return nil, commonerr.GrpcErrHandler(ctx, err, &commonerr.SentryInfo{
-			Contexts: map[string]interface{}{"limit": req.Limit, "offset": req.Offset},
-		})
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	products, err := p.useCase.GetProducts(ctx, filter, uint(req.GetLimit()), uint(req.GetOffset()))
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
 	}
 
 	return &api.ProductList{
-		Products: mapper.ManyProductsToGrpc(products),
+		Products: mapper.ManyProductsToGrpc(entity.LocalizeProducts(products, locale.FromContext(ctx))),
 	}, nil
 }
 
+func (p *ProductGrpcServer) GetProduct(ctx context.Context, id *api.Id) (*api.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.GetProduct")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"productId": id.Id},
+	}
+
+	productId, err := types.NewIdFromString(id.Id)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	product, err := p.useCase.GetProduct(ctx, productId)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.OneProductToGrpc(product.Localize(locale.FromContext(ctx))), nil
+}
+
 func (p *ProductGrpcServer) UpdateProduct(ctx context.Context, product *api.Product) (*api.Product, error) {
 	ctx, _ = p.logger.FromContext(ctx)
 	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.UpdateProduct")
@@ -57,27 +87,17 @@ func (p *ProductGrpcServer) UpdateProduct(ctx context.Context, product *api.Prod
 		Contexts: map[string]interface{}{"product": product},
 	}
 
-	productId, err := types.NewIdFromString(product.Id)
+	productEntity, err := mapper.OneGrpcProductToEntity(product)
 	if err != nil {
 		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
 	}
 
-	updatedProduct, err := p.useCase.UpdateProduct(ctx, productId, &entity.Product{
-		Id:          productId,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
-	})
+	updatedProduct, err := p.useCase.UpdateProduct(ctx, productEntity.Id, productEntity)
 	if err != nil {
 		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
 	}
 
-	return &api.Product{
-		Id:          updatedProduct.Id.Hex(),
-		Name:        updatedProduct.Name,
-		Description: updatedProduct.Description,
-		Price:       updatedProduct.Price,
-	}, nil
+	return mapper.OneProductToGrpc(updatedProduct), nil
 }
 
 func (p *ProductGrpcServer) DeleteProduct(ctx context.Context, id *api.Id) (*emptypb.Empty, error) {
@@ -100,3 +120,195 @@ func (p *ProductGrpcServer) DeleteProduct(ctx context.Context, id *api.Id) (*emp
 
 	return &emptypb.Empty{}, nil
 }
+
+func (p *ProductGrpcServer) RestoreProduct(ctx context.Context, id *api.Id) (*api.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.RestoreProduct")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"productId": id.Id},
+	}
+
+	productId, err := types.NewIdFromString(id.Id)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	product, err := p.useCase.RestoreProduct(ctx, productId)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.OneProductToGrpc(product), nil
+}
+
+func (p *ProductGrpcServer) GetProductHistory(
+	ctx context.Context,
+	req *api.GetProductHistoryRequest,
+) (*api.ProductHistory, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.GetProductHistory")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	productId, err := types.NewIdFromString(req.Id)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	changes, err := p.useCase.GetProductHistory(ctx, productId, uint(req.GetLimit()), uint(req.GetOffset()))
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return &api.ProductHistory{Changes: mapper.ManyProductChangesToGrpc(changes)}, nil
+}
+
+func (p *ProductGrpcServer) AddVariant(ctx context.Context, req *api.AddVariantRequest) (*api.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.AddVariant")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	productId, err := types.NewIdFromString(req.GetProductId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	product, err := p.useCase.AddVariant(ctx, productId, mapper.GrpcToVariant(req.GetVariant()))
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.OneProductToGrpc(product), nil
+}
+
+func (p *ProductGrpcServer) UpdateVariant(ctx context.Context, req *api.UpdateVariantRequest) (*api.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.UpdateVariant")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	productId, err := types.NewIdFromString(req.GetProductId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	variantId, err := types.NewIdFromString(req.GetVariantId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	variant := mapper.GrpcToVariant(req.GetVariant())
+	variant.Id = variantId
+
+	product, err := p.useCase.UpdateVariant(ctx, productId, variant)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.OneProductToGrpc(product), nil
+}
+
+func (p *ProductGrpcServer) DeleteVariant(ctx context.Context, req *api.DeleteVariantRequest) (*api.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.DeleteVariant")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	productId, err := types.NewIdFromString(req.GetProductId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	variantId, err := types.NewIdFromString(req.GetVariantId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	product, err := p.useCase.DeleteVariant(ctx, productId, variantId)
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.OneProductToGrpc(product), nil
+}
+
+func (p *ProductGrpcServer) ReserveStock(ctx context.Context, req *api.StockQuantityRequest) (*api.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.ReserveStock")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	productId, err := types.NewIdFromString(req.GetId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	product, err := p.useCase.ReserveStock(ctx, productId, req.GetQuantity())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.OneProductToGrpc(product), nil
+}
+
+func (p *ProductGrpcServer) ReleaseStock(ctx context.Context, req *api.StockQuantityRequest) (*api.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.ReleaseStock")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	productId, err := types.NewIdFromString(req.GetId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	product, err := p.useCase.ReleaseStock(ctx, productId, req.GetQuantity())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.OneProductToGrpc(product), nil
+}
+
+func (p *ProductGrpcServer) AdjustStock(ctx context.Context, req *api.AdjustStockRequest) (*api.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx)
+	ctx, span := tracing.Tracer.Start(ctx, "ProductGrpcServer.AdjustStock")
+	defer span.End()
+
+	sentryInfo := &commonerr.SentryInfo{
+		Contexts: map[string]interface{}{"request": req},
+	}
+
+	productId, err := types.NewIdFromString(req.GetId())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	product, err := p.useCase.AdjustStock(ctx, productId, req.GetDelta())
+	if err != nil {
+		return nil, commonerr.GrpcErrHandler(ctx, err, sentryInfo)
+	}
+
+	return mapper.OneProductToGrpc(product), nil
+}
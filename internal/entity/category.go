@@ -0,0 +1,101 @@
+package entity
+
+import (
+	"time"
+	"unicode/utf8"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+const maxCategoryNameLength = 100
+
+// Category узел дерева категорий. Дерево хранится материализованным путем:
+// AncestorIds содержит id всех предков от корня до непосредственного родителя.
+type Category struct {
+	Id   types.Id `json:"id" bson:"_id"`
+	Name string   `json:"name" bson:"name"`
+	// ParentId нулевой у корневых категорий
+	ParentId    types.Id   `json:"parentId" bson:"parentId,omitempty"`
+	AncestorIds []types.Id `json:"ancestorIds" bson:"ancestorIds"`
+	CreatedAt   time.Time  `json:"createdAt" bson:"createdAt"`
+	UpdatedAt   time.Time  `json:"updatedAt" bson:"updatedAt"`
+}
+
+func (c *Category) Hash() string {
+	return c.Id.Hex()
+}
+
+func (c *Category) IsRoot() bool {
+	return c.ParentId.IsZero()
+}
+
+// IsDescendantOf проверяет, что категория лежит в поддереве categoryId.
+func (c *Category) IsDescendantOf(categoryId types.Id) bool {
+	for _, id := range c.AncestorIds {
+		if id == categoryId {
+			return true
+		}
+	}
+
+	return false
+}
+
+// PathIds путь от корня до категории включительно. Используется как AncestorIds дочерних категорий.
+func (c *Category) PathIds() []types.Id {
+	path := make([]types.Id, 0, len(c.AncestorIds)+1)
+	path = append(path, c.AncestorIds...)
+
+	return append(path, c.Id)
+}
+
+func (c *Category) Validate() error {
+	if c.Name == "" {
+		return commonerr.NewIncorrectInputError("name is required")
+	}
+
+	if utf8.RuneCountInString(c.Name) > maxCategoryNameLength {
+		return commonerr.NewIncorrectInputError("name must not be longer than %d characters", maxCategoryNameLength)
+	}
+
+	return nil
+}
+
+// CategoryNode категория с дочерними узлами.
+type CategoryNode struct {
+	Category *Category
+	Children []*CategoryNode
+}
+
+// BuildCategoryTree собирает дерево из плоского списка категорий.
+// Если rootId нулевой, возвращаются все корневые узлы, иначе — единственный узел rootId с поддеревом.
+// Порядок детей совпадает с порядком категорий во входном списке.
+func BuildCategoryTree(categories []*Category, rootId types.Id) []*CategoryNode {
+	nodes := make(map[types.Id]*CategoryNode, len(categories))
+	for _, category := range categories {
+		nodes[category.Id] = &CategoryNode{Category: category, Children: nil}
+	}
+
+	roots := make([]*CategoryNode, 0)
+
+	for _, category := range categories {
+		node := nodes[category.Id]
+
+		if category.Id == rootId {
+			roots = append(roots, node)
+		}
+
+		parent, ok := nodes[category.ParentId]
+		if !ok {
+			if rootId.IsZero() {
+				roots = append(roots, node)
+			}
+
+			continue
+		}
+
+		parent.Children = append(parent.Children, node)
+	}
+
+	return roots
+}
@@ -0,0 +1,89 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func newCategory(name string, parent *Category) *Category {
+	category := &Category{ //nolint: exhaustruct
+		Id:          types.NewId(),
+		Name:        name,
+		AncestorIds: []types.Id{},
+	}
+
+	if parent != nil {
+		category.ParentId = parent.Id
+		category.AncestorIds = parent.PathIds()
+	}
+
+	return category
+}
+
+func TestCategory_Validate(t *testing.T) {
+	require.NoError(t, newCategory("Одежда", nil).Validate())
+
+	err := newCategory("", nil).Validate()
+	require.Error(t, err)
+	isAppError(t, err)
+
+	err = newCategory(strings.Repeat("я", maxCategoryNameLength+1), nil).Validate()
+	require.Error(t, err)
+	isAppError(t, err)
+}
+
+func TestCategory_IsDescendantOf(t *testing.T) {
+	root := newCategory("root", nil)
+	child := newCategory("child", root)
+	grandchild := newCategory("grandchild", child)
+
+	assert.True(t, grandchild.IsDescendantOf(root.Id))
+	assert.True(t, grandchild.IsDescendantOf(child.Id))
+	assert.False(t, grandchild.IsDescendantOf(grandchild.Id))
+	assert.False(t, root.IsDescendantOf(child.Id))
+	assert.Equal(t, []types.Id{root.Id, child.Id, grandchild.Id}, grandchild.PathIds())
+}
+
+func TestBuildCategoryTree(t *testing.T) {
+	clothes := newCategory("clothes", nil)
+	shoes := newCategory("shoes", clothes)
+	boots := newCategory("boots", shoes)
+	hats := newCategory("hats", clothes)
+	books := newCategory("books", nil)
+	categories := []*Category{clothes, shoes, boots, hats, books}
+
+	t.Run("whole tree", func(t *testing.T) {
+		tree := BuildCategoryTree(categories, types.Id{})
+
+		require.Len(t, tree, 2)
+		assert.Equal(t, clothes, tree[0].Category)
+		assert.Equal(t, books, tree[1].Category)
+		assert.Empty(t, tree[1].Children)
+
+		require.Len(t, tree[0].Children, 2)
+		assert.Equal(t, shoes, tree[0].Children[0].Category)
+		assert.Equal(t, hats, tree[0].Children[1].Category)
+
+		require.Len(t, tree[0].Children[0].Children, 1)
+		assert.Equal(t, boots, tree[0].Children[0].Children[0].Category)
+	})
+	t.Run("subtree", func(t *testing.T) {
+		tree := BuildCategoryTree(categories, shoes.Id)
+
+		require.Len(t, tree, 1)
+		assert.Equal(t, shoes, tree[0].Category)
+		require.Len(t, tree[0].Children, 1)
+		assert.Equal(t, boots, tree[0].Children[0].Category)
+	})
+	t.Run("child listed before parent", func(t *testing.T) {
+		tree := BuildCategoryTree([]*Category{boots, shoes, clothes}, types.Id{})
+
+		require.Len(t, tree, 1)
+		assert.Equal(t, boots, tree[0].Children[0].Children[0].Category)
+	})
+}
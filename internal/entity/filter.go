@@ -0,0 +1,47 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+// ProductFilter условия выборки продуктов. Нулевые значения полей не ограничивают выборку.
+type ProductFilter struct {
+	// UpdatedSince продукты, измененные начиная с этого момента
+	UpdatedSince time.Time
+	// CategoryIds продукты, входящие хотя бы в одну из категорий
+	CategoryIds []types.Id
+	// Tag продукты с тегом
+	Tag string
+	// InStockOnly только продукты, доступные для продажи
+	InStockOnly bool
+}
+
+func (f *ProductFilter) IsEmpty() bool {
+	return f == nil || (f.UpdatedSince.IsZero() && len(f.CategoryIds) == 0 && f.Tag == "" && !f.InStockOnly)
+}
+
+func (f *ProductFilter) Match(p *Product) bool {
+	if f.IsEmpty() {
+		return true
+	}
+
+	if !f.UpdatedSince.IsZero() && p.UpdatedAt.Before(f.UpdatedSince) {
+		return false
+	}
+
+	if len(f.CategoryIds) > 0 && !p.HasAnyCategory(f.CategoryIds) {
+		return false
+	}
+
+	if f.Tag != "" && !p.HasTag(f.Tag) {
+		return false
+	}
+
+	if f.InStockOnly && !p.InStock() {
+		return false
+	}
+
+	return true
+}
@@ -0,0 +1,50 @@
+package entity
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func TestProductFilter_Match(t *testing.T) {
+	now := time.Now()
+	categoryId := types.NewId()
+	product := &Product{UpdatedAt: now, CategoryIds: []types.Id{categoryId}, Tags: []string{"sale"}} //nolint: exhaustruct
+
+	testCases := []struct {
+		name     string
+		filter   *ProductFilter
+		expected bool
+	}{
+		{name: "nil filter", filter: nil, expected: true},
+		{name: "empty filter", filter: &ProductFilter{}, expected: true}, //nolint: exhaustruct
+		{name: "updated after", filter: &ProductFilter{UpdatedSince: now.Add(-time.Minute)}, expected: true},
+		{name: "updated at the same time", filter: &ProductFilter{UpdatedSince: now}, expected: true},
+		{name: "updated before", filter: &ProductFilter{UpdatedSince: now.Add(time.Minute)}, expected: false},
+		{name: "in category", filter: &ProductFilter{CategoryIds: []types.Id{categoryId}}, expected: true},        //nolint: exhaustruct
+		{name: "other category", filter: &ProductFilter{CategoryIds: []types.Id{types.NewId()}}, expected: false}, //nolint: exhaustruct
+		{
+			name:     "in one of categories",
+			filter:   &ProductFilter{CategoryIds: []types.Id{types.NewId(), categoryId}}, //nolint: exhaustruct
+			expected: true,
+		},
+		{name: "has tag", filter: &ProductFilter{Tag: "sale"}, expected: true}, //nolint: exhaustruct
+		{name: "no tag", filter: &ProductFilter{Tag: "new"}, expected: false},
+		{name: "out of stock", filter: &ProductFilter{InStockOnly: true}, expected: false}, //nolint: exhaustruct
+		{
+			name:     "all conditions",
+			filter:   &ProductFilter{UpdatedSince: now, CategoryIds: []types.Id{categoryId}, Tag: "sale"},
+			expected: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			assert.Equal(t, tc.expected, tc.filter.Match(product))
+		})
+	}
+}
@@ -0,0 +1,30 @@
+package entity
+
+import (
+	"time"
+
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+type ProductOperation string
+
+const (
+	ProductOperationUpdate  ProductOperation = "update"
+	ProductOperationDelete  ProductOperation = "delete"
+	ProductOperationRestore ProductOperation = "restore"
+	// ProductOperationStock изменение доступного или зарезервированного остатка
+	ProductOperationStock ProductOperation = "stock"
+)
+
+// ProductChange запись истории изменений продукта.
+type ProductChange struct {
+	Id        types.Id         `json:"id" bson:"_id"`
+	ProductId types.Id         `json:"productId" bson:"productId"`
+	Operation ProductOperation `json:"operation" bson:"operation"`
+	// Before, After состояние продукта до и после изменения
+	Before    *Product  `json:"before" bson:"before"`
+	After     *Product  `json:"after" bson:"after"`
+	Actor     string    `json:"actor" bson:"actor,omitempty"`
+	RequestId string    `json:"requestId" bson:"requestId,omitempty"`
+	ChangedAt time.Time `json:"changedAt" bson:"changedAt"`
+}
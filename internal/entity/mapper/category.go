@@ -0,0 +1,45 @@
+package mapper
+
+import (
+	"github.com/qulaz/artforintrovert-test/gen/api/v1"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func CategoryToGrpc(category *entity.Category) *api.Category {
+	grpcCategory := &api.Category{ //nolint: exhaustruct
+		Id:          category.Id.Hex(),
+		Name:        category.Name,
+		AncestorIds: IdsToGrpc(category.AncestorIds),
+		CreatedAt:   TimeToGrpc(category.CreatedAt),
+		UpdatedAt:   TimeToGrpc(category.UpdatedAt),
+	}
+
+	if !category.IsRoot() {
+		grpcCategory.ParentId = category.ParentId.Hex()
+	}
+
+	return grpcCategory
+}
+
+func CategoryTreeToGrpc(nodes []*entity.CategoryNode) []*api.CategoryTreeNode {
+	grpcNodes := make([]*api.CategoryTreeNode, len(nodes))
+
+	for i, node := range nodes {
+		grpcNodes[i] = &api.CategoryTreeNode{
+			Category: CategoryToGrpc(node.Category),
+			Children: CategoryTreeToGrpc(node.Children),
+		}
+	}
+
+	return grpcNodes
+}
+
+// GrpcToOptionalId разбирает необязательный id. Пустая строка соответствует нулевому id.
+func GrpcToOptionalId(rawId string) (types.Id, error) {
+	if rawId == "" {
+		return types.Id{}, nil
+	}
+
+	return types.NewIdFromString(rawId)
+}
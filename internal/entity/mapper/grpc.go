@@ -1,6 +1,10 @@
 package mapper
 
 import (
+	"time"
+
+	"google.golang.org/protobuf/types/known/timestamppb"
+
 	"github.com/qulaz/artforintrovert-test/gen/api/v1"
 	"github.com/qulaz/artforintrovert-test/internal/entity"
 	"github.com/qulaz/artforintrovert-test/internal/types"
@@ -8,11 +12,115 @@ import (
 
 func OneProductToGrpc(product *entity.Product) *api.Product {
 	return &api.Product{
-		Id:          product.Id.Hex(),
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
+		Id:           product.Id.Hex(),
+		Name:         product.Name,
+		Description:  product.Description,
+		Price:        MoneyToGrpc(product.Price),
+		CreatedAt:    TimeToGrpc(product.CreatedAt),
+		UpdatedAt:    TimeToGrpc(product.UpdatedAt),
+		UpdatedBy:    product.UpdatedBy,
+		Sku:          product.Sku,
+		CategoryIds:  IdsToGrpc(product.CategoryIds),
+		Tags:         product.Tags,
+		Images:       product.Images,
+		Attributes:   product.Attributes,
+		Variants:     VariantsToGrpc(product.Variants),
+		Stock:        product.Stock,
+		Reserved:     product.Reserved,
+		Translations: TranslationsToGrpc(product.Translations),
+	}
+}
+
+func TranslationsToGrpc(translations map[string]entity.Translation) map[string]*api.Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	grpcTranslations := make(map[string]*api.Translation, len(translations))
+
+	for locale, translation := range translations {
+		grpcTranslations[locale] = &api.Translation{
+			Name:        translation.Name,
+			Description: translation.Description,
+		}
+	}
+
+	return grpcTranslations
+}
+
+func GrpcToTranslations(translations map[string]*api.Translation) map[string]entity.Translation {
+	if len(translations) == 0 {
+		return nil
+	}
+
+	entityTranslations := make(map[string]entity.Translation, len(translations))
+
+	for locale, translation := range translations {
+		entityTranslations[locale] = entity.Translation{
+			Name:        translation.GetName(),
+			Description: translation.GetDescription(),
+		}
 	}
+
+	return entityTranslations
+}
+
+func VariantsToGrpc(variants []entity.Variant) []*api.Variant {
+	if len(variants) == 0 {
+		return nil
+	}
+
+	grpcVariants := make([]*api.Variant, len(variants))
+
+	for i := range variants {
+		grpcVariants[i] = &api.Variant{
+			Id:      variants[i].Id.Hex(),
+			Sku:     variants[i].Sku,
+			Price:   MoneyToGrpc(variants[i].Price),
+			Options: variants[i].Options,
+		}
+	}
+
+	return grpcVariants
+}
+
+func GrpcToVariant(variant *api.Variant) *entity.Variant {
+	return &entity.Variant{ //nolint: exhaustruct // id назначается при создании или берется из пути запроса
+		Sku:     variant.GetSku(),
+		Price:   GrpcToMoney(variant.GetPrice()),
+		Options: variant.GetOptions(),
+	}
+}
+
+func ProductChangeToGrpc(change *entity.ProductChange) *api.ProductChange {
+	grpcChange := &api.ProductChange{ //nolint: exhaustruct
+		Id:        change.Id.Hex(),
+		ProductId: change.ProductId.Hex(),
+		Operation: string(change.Operation),
+		Actor:     change.Actor,
+		RequestId: change.RequestId,
+		ChangedAt: TimeToGrpc(change.ChangedAt),
+	}
+
+	if change.Before != nil {
+		grpcChange.Before = OneProductToGrpc(change.Before)
+	}
+
+	if change.After != nil {
+		grpcChange.After = OneProductToGrpc(change.After)
+	}
+
+	return grpcChange
+}
+
+func ManyProductChangesToGrpc(changes []*entity.ProductChange) []*api.ProductChange {
+	grpcChanges := make([]*api.ProductChange, len(changes))
+
+	for i, change := range changes {
+		grpcChanges[i] = ProductChangeToGrpc(change)
+	}
+
+	return grpcChanges
 }
 
 func ManyProductsToGrpc(products []*entity.Product) []*api.Product {
@@ -31,10 +139,103 @@ func OneGrpcProductToEntity(product *api.Product) (*entity.Product, error) {
 		return nil, err
 	}
 
-	return &entity.Product{
-		Id:          id,
-		Name:        product.Name,
-		Description: product.Description,
-		Price:       product.Price,
+	categoryIds, err := GrpcToIds(product.GetCategoryIds())
+	if err != nil {
+		return nil, err
+	}
+
+	return &entity.Product{ //nolint: exhaustruct // служебные поля заполняет репозиторий
+		Id:           id,
+		Name:         product.Name,
+		Description:  product.Description,
+		Price:        GrpcToMoney(product.GetPrice()),
+		Sku:          product.GetSku(),
+		CategoryIds:  categoryIds,
+		Tags:         product.GetTags(),
+		Images:       product.GetImages(),
+		Attributes:   product.GetAttributes(),
+		Translations: GrpcToTranslations(product.GetTranslations()),
 	}, nil
 }
+
+func GrpcProductsRequestToFilter(req *api.GetProductsRequest) (*entity.ProductFilter, error) {
+	filter := &entity.ProductFilter{ //nolint: exhaustruct
+		UpdatedSince: GrpcToTime(req.GetUpdatedSince()),
+		Tag:          req.GetTag(),
+		InStockOnly:  req.GetInStockOnly(),
+	}
+
+	if req.GetCategoryId() != "" {
+		categoryId, err := types.NewIdFromString(req.GetCategoryId())
+		if err != nil {
+			return nil, err
+		}
+
+		filter.CategoryIds = []types.Id{categoryId}
+	}
+
+	return filter, nil
+}
+
+func IdsToGrpc(ids []types.Id) []string {
+	if len(ids) == 0 {
+		return nil
+	}
+
+	grpcIds := make([]string, len(ids))
+	for i, id := range ids {
+		grpcIds[i] = id.Hex()
+	}
+
+	return grpcIds
+}
+
+func GrpcToIds(grpcIds []string) ([]types.Id, error) {
+	if len(grpcIds) == 0 {
+		return nil, nil
+	}
+
+	ids := make([]types.Id, len(grpcIds))
+
+	for i, grpcId := range grpcIds {
+		id, err := types.NewIdFromString(grpcId)
+		if err != nil {
+			return nil, err
+		}
+
+		ids[i] = id
+	}
+
+	return ids, nil
+}
+
+func MoneyToGrpc(money entity.Money) *api.Money {
+	return &api.Money{
+		Amount:   money.Amount,
+		Currency: money.Currency,
+	}
+}
+
+func GrpcToMoney(money *api.Money) entity.Money {
+	return entity.Money{
+		Amount:   money.GetAmount(),
+		Currency: money.GetCurrency(),
+	}
+}
+
+// TimeToGrpc возвращает nil для нулевого времени, чтобы не отдавать клиентам 0001-01-01.
+func TimeToGrpc(t time.Time) *timestamppb.Timestamp {
+	if t.IsZero() {
+		return nil
+	}
+
+	return timestamppb.New(t)
+}
+
+func GrpcToTime(t *timestamppb.Timestamp) time.Time {
+	if t == nil {
+		return time.Time{}
+	}
+
+	return t.AsTime()
+}
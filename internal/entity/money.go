@@ -0,0 +1,86 @@
+package entity
+
+import (
+	"fmt"
+	"math"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+)
+
+// maxMajorAmount максимальная цена в основных единицах валюты.
+// Ограничение держит сумму в младших единицах в пределах точности float64 на стороне JS-клиентов.
+const maxMajorAmount = 1_000_000_000_000
+
+// Currency валюта по ISO 4217.
+type Currency struct {
+	Code string
+	// MinorUnits число знаков после запятой: 2 для RUB (копейки), 0 для JPY
+	MinorUnits int
+}
+
+// MaxAmount максимальная сумма в младших единицах.
+func (c Currency) MaxAmount() int64 {
+	return maxMajorAmount * c.Multiplier()
+}
+
+// Multiplier число младших единиц в одной основной.
+func (c Currency) Multiplier() int64 {
+	return int64(math.Pow10(c.MinorUnits))
+}
+
+var currencies = map[string]Currency{
+	"RUB": {Code: "RUB", MinorUnits: 2},
+	"USD": {Code: "USD", MinorUnits: 2},
+	"EUR": {Code: "EUR", MinorUnits: 2},
+	"GBP": {Code: "GBP", MinorUnits: 2},
+	"CNY": {Code: "CNY", MinorUnits: 2},
+	"KZT": {Code: "KZT", MinorUnits: 2},
+	"JPY": {Code: "JPY", MinorUnits: 0},
+	"KWD": {Code: "KWD", MinorUnits: 3},
+}
+
+// LookupCurrency возвращает поддерживаемую валюту по коду ISO 4217.
+func LookupCurrency(code string) (Currency, bool) {
+	currency, ok := currencies[code]
+
+	return currency, ok
+}
+
+// Money денежная сумма в младших единицах валюты, например 1050 RUB — 10 рублей 50 копеек.
+type Money struct {
+	Amount   int64  `json:"amount" bson:"amount"`
+	Currency string `json:"currency" bson:"currency"`
+}
+
+func (m Money) String() string {
+	currency, ok := LookupCurrency(m.Currency)
+	if !ok || currency.MinorUnits == 0 {
+		return fmt.Sprintf("%d %s", m.Amount, m.Currency)
+	}
+
+	multiplier := currency.Multiplier()
+
+	return fmt.Sprintf("%d.%0*d %s", m.Amount/multiplier, currency.MinorUnits, m.Amount%multiplier, m.Currency)
+}
+
+// Validate проверяет, что валюта поддерживается, а сумма положительна и не превышает лимит валюты.
+func (m Money) Validate() error {
+	if m.Currency == "" {
+		return commonerr.NewIncorrectInputError("currency is required")
+	}
+
+	currency, ok := LookupCurrency(m.Currency)
+	if !ok {
+		return commonerr.NewIncorrectInputError("currency %s is not supported", m.Currency)
+	}
+
+	if m.Amount <= 0 {
+		return commonerr.NewIncorrectInputError("amount must be greater than 0")
+	}
+
+	if m.Amount > currency.MaxAmount() {
+		return commonerr.NewIncorrectInputError("amount must not exceed %d for %s", currency.MaxAmount(), m.Currency)
+	}
+
+	return nil
+}
@@ -0,0 +1,49 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMoney_Validate(t *testing.T) {
+	testCases := []struct {
+		name    string
+		money   Money
+		wantErr bool
+	}{
+		{name: "valid", money: Money{Amount: 1050, Currency: "RUB"}, wantErr: false},
+		{name: "valid without minor units", money: Money{Amount: 1, Currency: "JPY"}, wantErr: false},
+		{name: "currency required", money: Money{Amount: 1050, Currency: ""}, wantErr: true},
+		{name: "unsupported currency", money: Money{Amount: 1050, Currency: "XXX"}, wantErr: true},
+		{name: "zero amount", money: Money{Amount: 0, Currency: "RUB"}, wantErr: true},
+		{name: "negative amount", money: Money{Amount: -1, Currency: "RUB"}, wantErr: true},
+		{name: "max amount", money: Money{Amount: maxMajorAmount * 100, Currency: "RUB"}, wantErr: false},
+		{name: "amount too large", money: Money{Amount: maxMajorAmount*100 + 1, Currency: "RUB"}, wantErr: true},
+		{name: "limit depends on currency", money: Money{Amount: maxMajorAmount * 100, Currency: "JPY"}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			err := tc.money.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+				isAppError(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestMoney_String(t *testing.T) {
+	assert.Equal(t, "10.50 RUB", Money{Amount: 1050, Currency: "RUB"}.String())
+	assert.Equal(t, "0.05 USD", Money{Amount: 5, Currency: "USD"}.String())
+	assert.Equal(t, "1.005 KWD", Money{Amount: 1005, Currency: "KWD"}.String())
+	assert.Equal(t, "500 JPY", Money{Amount: 500, Currency: "JPY"}.String())
+	assert.Equal(t, "500 XXX", Money{Amount: 500, Currency: "XXX"}.String())
+}
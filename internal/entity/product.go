@@ -1,33 +1,233 @@
 package entity
 
 import (
-	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"crypto/sha256"
+	"encoding/hex"
+	"encoding/json"
+	"fmt"
+	"net/url"
+	"regexp"
+	"strings"
+	"time"
+	"unicode/utf8"
+
 	"github.com/qulaz/artforintrovert-test/internal/types"
 )
 
+const (
+	maxSkuLength            = 64
+	maxCategories           = 20
+	maxTags                 = 30
+	maxTagLength            = 50
+	maxImages               = 20
+	maxAttributes           = 50
+	maxAttributeKeyLength   = 64
+	maxAttributeValueLength = 1024
+)
+
+var skuRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
 type Product struct {
 	Id          types.Id `json:"id" bson:"_id"`
 	Name        string   `json:"name" bson:"name"`
 	Description string   `json:"description" bson:"description"`
-	Price       int32    `json:"price" bson:"price"`
+	Price       Money    `json:"price" bson:"price"`
+	// Translations переводы Name и Description по локалям. Сами Name и Description — на локали по умолчанию
+	Translations map[string]Translation `json:"translations" bson:"translations,omitempty"`
+	// Sku артикул, уникален среди продуктов
+	Sku         string            `json:"sku" bson:"sku,omitempty"`
+	CategoryIds []types.Id        `json:"categoryIds" bson:"categoryIds,omitempty"`
+	Tags        []string          `json:"tags" bson:"tags,omitempty"`
+	Images      []string          `json:"images" bson:"images,omitempty"`
+	Attributes  map[string]string `json:"attributes" bson:"attributes,omitempty"`
+	// Stock доступное для продажи количество, Reserved — зарезервированное под заказы
+	Stock    int64 `json:"stock" bson:"stock"`
+	Reserved int64 `json:"reserved" bson:"reserved"`
+	// Variants варианты продукта. Price продукта остается базовой ценой для клиентов без поддержки вариантов
+	Variants []Variant `json:"variants" bson:"variants,omitempty"`
+
+	// CreatedAt, UpdatedAt проставляются репозиторием
+	CreatedAt time.Time `json:"createdAt" bson:"createdAt"`
+	UpdatedAt time.Time `json:"updatedAt" bson:"updatedAt"`
+	// UpdatedBy инициатор последнего изменения из метаданных запроса
+	UpdatedBy string `json:"updatedBy" bson:"updatedBy,omitempty"`
+	// Version увеличивается репозиторием при каждом изменении продукта и используется для оптимистичной блокировки.
+	// У документов, созданных до появления поля, версия равна нулю
+	Version int64 `json:"version" bson:"version,omitempty"`
+	// DeletedAt момент мягкого удаления. Удаленные продукты не попадают в кеш и выдачу до восстановления
+	DeletedAt *time.Time `json:"deletedAt,omitempty" bson:"deletedAt,omitempty"`
+}
+
+func (p *Product) IsDeleted() bool {
+	return p.DeletedAt != nil
+}
+
+func (p *Product) InStock() bool {
+	return p.Stock > 0
 }
 
 func (p *Product) Hash() string {
 	return p.Id.Hex()
 }
 
+// CacheVersion версия продукта для кеша: более старая копия продукта не заменяет в кеше более новую.
+func (p *Product) CacheVersion() int64 {
+	return p.Version
+}
+
+// Checksum хеш содержимого продукта. Используется для сравнения копий продукта, например в кеше и в базе.
+func (p *Product) Checksum() string {
+	data, _ := json.Marshal(p) //nolint: errchkjson // Product всегда сериализуется
+
+	sum := sha256.Sum256(data)
+
+	return hex.EncodeToString(sum[:])
+}
+
 func (p *Product) Validate() error {
+	return p.ValidateWith(nil)
+}
+
+// ValidateWith проверяет инварианты продукта и правила витрины rules. Возвращает все нарушения сразу.
+func (p *Product) ValidateWith(rules *ValidationRules) error {
+	var v violations
+
 	if p.Name == "" {
-		return commonerr.NewIncorrectInputError("name is required")
+		v.add("name", "name is required")
 	}
 
 	if p.Description == "" {
-		return commonerr.NewIncorrectInputError("description is required")
+		v.add("description", "description is required")
+	}
+
+	if err := p.Price.Validate(); err != nil {
+		v.add("price", "invalid price: %s", err.Error())
+	}
+
+	if p.Sku != "" && !skuRegexp.MatchString(p.Sku) {
+		v.add("sku", "sku must be 1-%d latin letters, digits, dots, dashes or underscores", maxSkuLength)
+	}
+
+	p.validateCategories(&v)
+	p.validateTags(&v)
+	p.validateImages(&v)
+	p.validateAttributes(&v)
+	p.validateTranslations(&v)
+	p.validateVariants(&v)
+	rules.check(p, &v)
+
+	return v.err()
+}
+
+func (p *Product) HasCategory(categoryId types.Id) bool {
+	for _, id := range p.CategoryIds {
+		if id == categoryId {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Product) HasAnyCategory(categoryIds []types.Id) bool {
+	for _, id := range categoryIds {
+		if p.HasCategory(id) {
+			return true
+		}
+	}
+
+	return false
+}
+
+func (p *Product) HasTag(tag string) bool {
+	for _, t := range p.Tags {
+		if t == tag {
+			return true
+		}
 	}
 
-	if p.Price <= 0 {
-		return commonerr.NewIncorrectInputError("price must be greater than 0")
+	return false
+}
+
+func (p *Product) validateCategories(v *violations) {
+	if len(p.CategoryIds) > maxCategories {
+		v.add("category_ids", "product can't have more than %d categories", maxCategories)
 	}
 
-	return nil
+	seen := make(map[types.Id]struct{}, len(p.CategoryIds))
+
+	for i, id := range p.CategoryIds {
+		field := fmt.Sprintf("category_ids[%d]", i)
+
+		if id.IsZero() {
+			v.add(field, "category id is required")
+
+			continue
+		}
+
+		if _, ok := seen[id]; ok {
+			v.add(field, "duplicate category %s", id.Hex())
+		}
+
+		seen[id] = struct{}{}
+	}
+}
+
+func (p *Product) validateTags(v *violations) {
+	if len(p.Tags) > maxTags {
+		v.add("tags", "product can't have more than %d tags", maxTags)
+	}
+
+	seen := make(map[string]struct{}, len(p.Tags))
+
+	for i, tag := range p.Tags {
+		field := fmt.Sprintf("tags[%d]", i)
+
+		if strings.TrimSpace(tag) == "" {
+			v.add(field, "tag must not be empty")
+
+			continue
+		}
+
+		if utf8.RuneCountInString(tag) > maxTagLength {
+			v.add(field, "tag must not be longer than %d characters", maxTagLength)
+		}
+
+		if _, ok := seen[tag]; ok {
+			v.add(field, "duplicate tag %s", tag)
+		}
+
+		seen[tag] = struct{}{}
+	}
+}
+
+func (p *Product) validateImages(v *violations) {
+	if len(p.Images) > maxImages {
+		v.add("images", "product can't have more than %d images", maxImages)
+	}
+
+	for i, image := range p.Images {
+		u, err := url.Parse(image)
+		if err != nil || (u.Scheme != "http" && u.Scheme != "https") || u.Host == "" {
+			v.add(fmt.Sprintf("images[%d]", i), "image %q must be an absolute http(s) url", image)
+		}
+	}
+}
+
+func (p *Product) validateAttributes(v *violations) {
+	if len(p.Attributes) > maxAttributes {
+		v.add("attributes", "product can't have more than %d attributes", maxAttributes)
+	}
+
+	for _, key := range sortedKeys(p.Attributes) {
+		if key == "" || utf8.RuneCountInString(key) > maxAttributeKeyLength {
+			v.add("attributes", "attribute name must be 1-%d characters", maxAttributeKeyLength)
+
+			continue
+		}
+
+		if utf8.RuneCountInString(p.Attributes[key]) > maxAttributeValueLength {
+			v.add("attributes."+key, "attribute value must not be longer than %d characters", maxAttributeValueLength)
+		}
+	}
 }
@@ -3,6 +3,7 @@ package entity
 import (
 	"errors"
 	"fmt"
+	"strings"
 	"testing"
 
 	"github.com/brianvoe/gofakeit/v6"
@@ -45,7 +46,7 @@ func TestProduct_Validate(t *testing.T) {
 		product := &Product{ //nolint: exhaustruct
 			Id:          types.NewId(),
 			Description: gofakeit.Name(),
-			Price:       1,
+			Price:       Money{Amount: 1, Currency: "RUB"},
 		}
 		err := product.Validate()
 		require.Error(t, err)
@@ -55,7 +56,18 @@ func TestProduct_Validate(t *testing.T) {
 		product := &Product{ //nolint: exhaustruct
 			Id:    types.NewId(),
 			Name:  gofakeit.Name(),
-			Price: 1,
+			Price: Money{Amount: 1, Currency: "RUB"},
+		}
+		err := product.Validate()
+		require.Error(t, err)
+		isAppError(t, err)
+	})
+	t.Run("price without currency", func(t *testing.T) {
+		product := &Product{ //nolint: exhaustruct
+			Id:          types.NewId(),
+			Name:        gofakeit.Name(),
+			Description: gofakeit.Name(),
+			Price:       Money{Amount: 100, Currency: ""},
 		}
 		err := product.Validate()
 		require.Error(t, err)
@@ -65,10 +77,72 @@ func TestProduct_Validate(t *testing.T) {
 		product := &Product{ //nolint: exhaustruct
 			Id:    types.NewId(),
 			Name:  gofakeit.Name(),
-			Price: -1,
+			Price: Money{Amount: -1, Currency: "RUB"},
 		}
 		err := product.Validate()
 		require.Error(t, err)
 		isAppError(t, err)
 	})
 }
+
+func TestProduct_ValidateCatalogFields(t *testing.T) {
+	newProduct := func() *Product {
+		return &Product{ //nolint: exhaustruct
+			Id:          types.NewId(),
+			Name:        gofakeit.Name(),
+			Description: gofakeit.Name(),
+			Price:       Money{Amount: 100, Currency: "RUB"},
+			Sku:         "SKU-001",
+			CategoryIds: []types.Id{types.NewId()},
+			Tags:        []string{"sale", "новинка"},
+			Images:      []string{"https://cdn.example.com/1.png"},
+			Attributes:  map[string]string{"color": "red"},
+		}
+	}
+
+	testCases := []struct {
+		name    string
+		modify  func(p *Product)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(p *Product) {}, wantErr: false},
+		{name: "without optional fields", modify: func(p *Product) {
+			p.Sku, p.CategoryIds, p.Tags, p.Images, p.Attributes = "", nil, nil, nil, nil
+		}, wantErr: false},
+		{name: "sku with spaces", modify: func(p *Product) { p.Sku = "SKU 001" }, wantErr: true},
+		{name: "sku too long", modify: func(p *Product) { p.Sku = strings.Repeat("A", maxSkuLength+1) }, wantErr: true},
+		{name: "zero category id", modify: func(p *Product) { p.CategoryIds = []types.Id{{}} }, wantErr: true},
+		{name: "duplicate category", modify: func(p *Product) {
+			p.CategoryIds = append(p.CategoryIds, p.CategoryIds[0])
+		}, wantErr: true},
+		{name: "empty tag", modify: func(p *Product) { p.Tags = []string{" "} }, wantErr: true},
+		{name: "duplicate tag", modify: func(p *Product) { p.Tags = []string{"sale", "sale"} }, wantErr: true},
+		{name: "tag too long", modify: func(p *Product) {
+			p.Tags = []string{strings.Repeat("я", maxTagLength+1)}
+		}, wantErr: true},
+		{name: "relative image url", modify: func(p *Product) { p.Images = []string{"/1.png"} }, wantErr: true},
+		{name: "image url with wrong scheme", modify: func(p *Product) {
+			p.Images = []string{"ftp://cdn.example.com/1.png"}
+		}, wantErr: true},
+		{name: "empty attribute name", modify: func(p *Product) { p.Attributes = map[string]string{"": "x"} }, wantErr: true},
+		{name: "attribute value too long", modify: func(p *Product) {
+			p.Attributes = map[string]string{"color": strings.Repeat("x", maxAttributeValueLength+1)}
+		}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			product := newProduct()
+			tc.modify(product)
+
+			err := product.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+				isAppError(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
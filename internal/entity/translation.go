@@ -0,0 +1,61 @@
+package entity
+
+import "golang.org/x/text/language"
+
+const maxTranslations = 20
+
+// Translation перевод текстовых полей продукта на одну локаль.
+type Translation struct {
+	Name        string `json:"name" bson:"name"`
+	Description string `json:"description" bson:"description"`
+}
+
+// Localize возвращает копию продукта с названием и описанием на локали locale.
+// Если перевода нет, остаются Name и Description локали по умолчанию.
+func (p *Product) Localize(locale string) *Product {
+	translation, ok := p.Translations[locale]
+	if !ok {
+		return p
+	}
+
+	localized := *p
+	localized.Name = translation.Name
+	localized.Description = translation.Description
+
+	return &localized
+}
+
+func LocalizeProducts(products []*Product, locale string) []*Product {
+	localized := make([]*Product, len(products))
+
+	for i, product := range products {
+		localized[i] = product.Localize(locale)
+	}
+
+	return localized
+}
+
+func (p *Product) validateTranslations(v *violations) {
+	if len(p.Translations) > maxTranslations {
+		v.add("translations", "product can't have more than %d translations", maxTranslations)
+	}
+
+	for _, locale := range sortedKeys(p.Translations) {
+		field := "translations." + locale
+
+		tag, err := language.Parse(locale)
+		if err != nil || tag.String() != locale {
+			v.add(field, "invalid locale %q, expected BCP 47 tag like en or en-US", locale)
+
+			continue
+		}
+
+		if p.Translations[locale].Name == "" {
+			v.add(field+".name", "name in %s translation is required", locale)
+		}
+
+		if p.Translations[locale].Description == "" {
+			v.add(field+".description", "description in %s translation is required", locale)
+		}
+	}
+}
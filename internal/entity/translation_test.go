@@ -0,0 +1,88 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func newTranslatedProduct() *Product {
+	return &Product{ //nolint: exhaustruct
+		Id:          types.NewId(),
+		Name:        "Кружка",
+		Description: "Керамическая кружка",
+		Price:       Money{Amount: 100, Currency: "RUB"},
+		Translations: map[string]Translation{
+			"en":    {Name: "Mug", Description: "Ceramic mug"},
+			"en-GB": {Name: "Mug", Description: "Ceramic mug, UK edition"},
+		},
+	}
+}
+
+func TestProduct_ValidateTranslations(t *testing.T) {
+	testCases := []struct {
+		name    string
+		modify  func(p *Product)
+		wantErr bool
+	}{
+		{name: "valid", modify: func(p *Product) {}, wantErr: false},
+		{name: "without translations", modify: func(p *Product) { p.Translations = nil }, wantErr: false},
+		{name: "invalid locale", modify: func(p *Product) {
+			p.Translations["not a locale"] = Translation{Name: gofakeit.Name(), Description: gofakeit.Name()}
+		}, wantErr: true},
+		{name: "non canonical locale", modify: func(p *Product) {
+			p.Translations["EN_us"] = Translation{Name: gofakeit.Name(), Description: gofakeit.Name()}
+		}, wantErr: true},
+		{name: "translation without name", modify: func(p *Product) {
+			p.Translations["de"] = Translation{Name: "", Description: gofakeit.Name()}
+		}, wantErr: true},
+		{name: "translation without description", modify: func(p *Product) {
+			p.Translations["de"] = Translation{Name: gofakeit.Name(), Description: ""}
+		}, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			product := newTranslatedProduct()
+			tc.modify(product)
+
+			err := product.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+				isAppError(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProduct_ValidateRequiredLocales(t *testing.T) {
+	product := newTranslatedProduct()
+
+	require.NoError(t, product.ValidateWith(&ValidationRules{RequiredLocales: []string{"en"}})) //nolint: exhaustruct
+
+	err := product.ValidateWith(&ValidationRules{RequiredLocales: []string{"en", "kk"}}) //nolint: exhaustruct
+	require.Error(t, err)
+	isAppError(t, err)
+}
+
+func TestProduct_Localize(t *testing.T) {
+	product := newTranslatedProduct()
+
+	localized := product.Localize("en")
+	assert.Equal(t, "Mug", localized.Name)
+	assert.Equal(t, "Ceramic mug", localized.Description)
+	assert.Equal(t, product.Id, localized.Id)
+	// исходный продукт, например из кеша, не меняется
+	assert.Equal(t, "Кружка", product.Name)
+
+	fallback := product.Localize("kk")
+	assert.Equal(t, "Кружка", fallback.Name)
+	assert.Equal(t, "Керамическая кружка", fallback.Description)
+}
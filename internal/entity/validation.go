@@ -0,0 +1,207 @@
+package entity
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+)
+
+// Поля продукта, на которые можно навесить правила. Названия совпадают с полями в API.
+const (
+	fieldName        = "name"
+	fieldDescription = "description"
+	fieldSku         = "sku"
+	fieldCategoryIds = "category_ids"
+	fieldTags        = "tags"
+	fieldImages      = "images"
+	fieldAttributes  = "attributes"
+)
+
+var (
+	// requirableFields необязательные по умолчанию поля, которые витрина может сделать обязательными
+	requirableFields = map[string]func(p *Product) bool{
+		fieldSku:         func(p *Product) bool { return p.Sku != "" },
+		fieldCategoryIds: func(p *Product) bool { return len(p.CategoryIds) > 0 },
+		fieldTags:        func(p *Product) bool { return len(p.Tags) > 0 },
+		fieldImages:      func(p *Product) bool { return len(p.Images) > 0 },
+		fieldAttributes:  func(p *Product) bool { return len(p.Attributes) > 0 },
+	}
+	// textFields строковые поля, для которых задаются длина и запрещенные символы
+	textFields = map[string]struct{}{fieldName: {}, fieldDescription: {}, fieldSku: {}, fieldTags: {}}
+)
+
+// ValidationRules правила витрины, которые проверяются поверх встроенных инвариантов продукта.
+// Нулевое значение и nil не добавляют проверок.
+type ValidationRules struct {
+	// RequiredFields поля, которые должны быть заполнены: sku, category_ids, tags, images, attributes
+	RequiredFields []string `json:"requiredFields"`
+	// MaxLength максимальная длина в символах для name, description, sku и каждого из tags.
+	// Для name и description действует и на переводы
+	MaxLength map[string]int `json:"maxLength"`
+	// ForbiddenChars символы, которые не должны встречаться в поле. Поля те же, что у MaxLength
+	ForbiddenChars map[string]string `json:"forbiddenChars"`
+	// MaxPrice потолок цены продукта и вариантов в младших единицах по коду валюты
+	MaxPrice map[string]int64 `json:"maxPrice"`
+	// RequiredLocales локали, на которые продукт обязан быть переведен
+	RequiredLocales []string `json:"requiredLocales"`
+}
+
+// ParseValidationRules разбирает правила из JSON и проверяет, что они ссылаются на известные поля.
+func ParseValidationRules(data []byte) (*ValidationRules, error) {
+	var rules ValidationRules
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&rules); err != nil {
+		return nil, fmt.Errorf("can't decode validation rules: %w", err)
+	}
+
+	for _, field := range rules.RequiredFields {
+		if _, ok := requirableFields[field]; !ok {
+			return nil, fmt.Errorf("field %q can't be required", field)
+		}
+	}
+
+	for field, maxLength := range rules.MaxLength {
+		if _, ok := textFields[field]; !ok {
+			return nil, fmt.Errorf("max length can't be set for field %q", field)
+		}
+
+		if maxLength <= 0 {
+			return nil, fmt.Errorf("max length of %q must be greater than 0", field)
+		}
+	}
+
+	for field := range rules.ForbiddenChars {
+		if _, ok := textFields[field]; !ok {
+			return nil, fmt.Errorf("forbidden chars can't be set for field %q", field)
+		}
+	}
+
+	for currency, maxPrice := range rules.MaxPrice {
+		if _, ok := LookupCurrency(currency); !ok {
+			return nil, fmt.Errorf("currency %s is not supported", currency)
+		}
+
+		if maxPrice <= 0 {
+			return nil, fmt.Errorf("max price for %s must be greater than 0", currency)
+		}
+	}
+
+	return &rules, nil
+}
+
+// RequireLocales добавляет обязательные локали. Локаль по умолчанию пропускается, в том числе среди уже
+// заданных: на ней написаны name и description продукта, отдельный перевод для нее не нужен.
+func (r *ValidationRules) RequireLocales(defaultLocale string, locales ...string) {
+	required := make([]string, 0, len(r.RequiredLocales)+len(locales))
+	seen := make(map[string]struct{}, cap(required))
+
+	for _, locale := range append(append([]string{}, r.RequiredLocales...), locales...) {
+		if _, ok := seen[locale]; ok || locale == defaultLocale {
+			continue
+		}
+
+		seen[locale] = struct{}{}
+		required = append(required, locale)
+	}
+
+	r.RequiredLocales = required
+}
+
+func (r *ValidationRules) check(p *Product, v *violations) {
+	if r == nil {
+		return
+	}
+
+	for _, field := range r.RequiredFields {
+		if !requirableFields[field](p) {
+			v.add(field, "%s is required", field)
+		}
+	}
+
+	r.checkText(fieldName, p.Name, v)
+	r.checkText(fieldDescription, p.Description, v)
+
+	if p.Sku != "" {
+		r.checkText(fieldSku, p.Sku, v)
+	}
+
+	for i, tag := range p.Tags {
+		r.checkTextAt(fieldTags, fmt.Sprintf("tags[%d]", i), tag, v)
+	}
+
+	for _, locale := range sortedKeys(p.Translations) {
+		r.checkTextAt(fieldName, "translations."+locale+".name", p.Translations[locale].Name, v)
+		r.checkTextAt(fieldDescription, "translations."+locale+".description", p.Translations[locale].Description, v)
+	}
+
+	for _, locale := range r.RequiredLocales {
+		if _, ok := p.Translations[locale]; !ok {
+			v.add("translations", "translation to %s is required", locale)
+		}
+	}
+
+	r.checkPrice("price", p.Price, v)
+
+	for i := range p.Variants {
+		r.checkPrice(fmt.Sprintf("variants[%d].price", i), p.Variants[i].Price, v)
+	}
+}
+
+func (r *ValidationRules) checkText(field string, value string, v *violations) {
+	r.checkTextAt(field, field, value, v)
+}
+
+// checkTextAt проверяет value по правилам поля field и пишет нарушения по пути path.
+func (r *ValidationRules) checkTextAt(field string, path string, value string, v *violations) {
+	if maxLength, ok := r.MaxLength[field]; ok && utf8.RuneCountInString(value) > maxLength {
+		v.add(path, "must not be longer than %d characters", maxLength)
+	}
+
+	if chars := r.ForbiddenChars[field]; chars != "" {
+		if i := strings.IndexAny(value, chars); i >= 0 {
+			char, _ := utf8.DecodeRuneInString(value[i:])
+			v.add(path, "must not contain %q", char)
+		}
+	}
+}
+
+func (r *ValidationRules) checkPrice(path string, price Money, v *violations) {
+	if maxPrice, ok := r.MaxPrice[price.Currency]; ok && price.Amount > maxPrice {
+		v.add(path, "must not exceed %d %s", maxPrice, price.Currency)
+	}
+}
+
+// violations накапливает нарушения валидации, чтобы вернуть их все одной ошибкой.
+type violations []commonerr.FieldViolation
+
+func (v *violations) add(field string, descriptionf string, args ...interface{}) {
+	*v = append(*v, commonerr.FieldViolation{Field: field, Description: fmt.Sprintf(descriptionf, args...)})
+}
+
+func (v violations) err() error {
+	if len(v) == 0 {
+		return nil
+	}
+
+	return commonerr.NewValidationError(v)
+}
+
+// sortedKeys ключи map в стабильном порядке, чтобы нарушения не перемешивались между вызовами.
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	return keys
+}
@@ -0,0 +1,173 @@
+package entity
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func TestParseValidationRules(t *testing.T) {
+	testCases := []struct {
+		name    string
+		data    string
+		wantErr bool
+	}{
+		{name: "empty", data: `{}`, wantErr: false},
+		{
+			name: "valid",
+			data: `{"requiredFields": ["sku"], "maxLength": {"name": 10}, "forbiddenChars": {"tags": ","},
+				"maxPrice": {"RUB": 1000}, "requiredLocales": ["en"]}`,
+			wantErr: false,
+		},
+		{name: "invalid json", data: `{`, wantErr: true},
+		{name: "unknown rule", data: `{"minLength": {"name": 1}}`, wantErr: true},
+		{name: "unknown required field", data: `{"requiredFields": ["color"]}`, wantErr: true},
+		{name: "max length of non text field", data: `{"maxLength": {"images": 1}}`, wantErr: true},
+		{name: "zero max length", data: `{"maxLength": {"name": 0}}`, wantErr: true},
+		{name: "forbidden chars of non text field", data: `{"forbiddenChars": {"price": "1"}}`, wantErr: true},
+		{name: "unsupported currency", data: `{"maxPrice": {"XXX": 1}}`, wantErr: true},
+		{name: "negative max price", data: `{"maxPrice": {"RUB": -1}}`, wantErr: true},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			rules, err := ParseValidationRules([]byte(tc.data))
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+				require.NotNil(t, rules)
+			}
+		})
+	}
+}
+
+func TestProduct_ValidateWith(t *testing.T) {
+	rules := &ValidationRules{
+		RequiredFields:  []string{"sku", "images"},
+		MaxLength:       map[string]int{"name": 10, "tags": 5},
+		ForbiddenChars:  map[string]string{"name": "<>", "description": "$"},
+		MaxPrice:        map[string]int64{"RUB": 1000},
+		RequiredLocales: nil,
+	}
+
+	newProduct := func() *Product {
+		return &Product{ //nolint: exhaustruct
+			Id:          types.NewId(),
+			Name:        "Кружка",
+			Description: "Керамическая кружка",
+			Price:       Money{Amount: 1000, Currency: "RUB"},
+			Sku:         "MUG",
+			Tags:        []string{"sale"},
+			Images:      []string{"https://cdn.example.com/mug.png"},
+		}
+	}
+
+	testCases := []struct {
+		name   string
+		modify func(p *Product)
+		fields []string
+	}{
+		{name: "valid", modify: func(p *Product) {}, fields: nil},
+		{name: "required field", modify: func(p *Product) { p.Images = nil }, fields: []string{"images"}},
+		{name: "name too long", modify: func(p *Product) { p.Name = strings.Repeat("я", 11) }, fields: []string{"name"}},
+		{name: "forbidden char", modify: func(p *Product) { p.Name = "<b>Mug" }, fields: []string{"name"}},
+		{name: "tag too long", modify: func(p *Product) { p.Tags = []string{"sale", "clearance"} }, fields: []string{"tags[1]"}},
+		{name: "price ceiling", modify: func(p *Product) { p.Price.Amount = 1001 }, fields: []string{"price"}},
+		{name: "ceiling of other currency", modify: func(p *Product) { p.Price.Currency = "USD" }, fields: nil},
+		{
+			name: "translation",
+			modify: func(p *Product) {
+				p.Translations = map[string]Translation{"en": {Name: "Mug", Description: "Costs $5"}}
+			},
+			fields: []string{"translations.en.description"},
+		},
+		{
+			name: "all violations at once",
+			modify: func(p *Product) {
+				p.Name = ""
+				p.Description = "$$$"
+				p.Sku = ""
+				p.Images = []string{"/mug.png"}
+			},
+			fields: []string{"name", "images[0]", "sku", "description"},
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			product := newProduct()
+			tc.modify(product)
+
+			err := product.ValidateWith(rules)
+			if tc.fields == nil {
+				require.NoError(t, err)
+
+				return
+			}
+
+			require.Error(t, err)
+			isAppError(t, err)
+
+			violations := commonerr.FieldViolations(err)
+			fields := make([]string, len(violations))
+
+			for i, violation := range violations {
+				fields[i] = violation.Field
+				assert.NotEmpty(t, violation.Description)
+			}
+
+			assert.Equal(t, tc.fields, fields)
+		})
+	}
+}
+
+func TestValidationRules_RequireLocales(t *testing.T) {
+	rules := &ValidationRules{RequiredLocales: []string{"ru", "en"}} //nolint: exhaustruct
+	rules.RequireLocales("ru", "ru", "en", "kk")
+
+	assert.Equal(t, []string{"en", "kk"}, rules.RequiredLocales)
+
+	// name и description уже на локали по умолчанию, перевод на нее не требуется
+	product := &Product{ //nolint: exhaustruct
+		Id:           types.NewId(),
+		Name:         "Кружка",
+		Description:  "Керамическая кружка",
+		Price:        Money{Amount: 1000, Currency: "RUB"},
+		Translations: map[string]Translation{"en": {Name: "Mug", Description: "Ceramic mug"}},
+	}
+
+	err := product.ValidateWith(rules)
+	require.Error(t, err)
+
+	violations := commonerr.FieldViolations(err)
+	require.Len(t, violations, 1)
+	assert.Equal(t, "translations", violations[0].Field)
+	assert.Contains(t, violations[0].Description, "kk")
+
+	rules.RequireLocales("ru")
+	product.Translations["kk"] = Translation{Name: "Саптыаяқ", Description: "Керамикалық саптыаяқ"}
+	require.NoError(t, product.ValidateWith(rules))
+}
+
+func TestProduct_ValidateWithNilRules(t *testing.T) {
+	product := &Product{ //nolint: exhaustruct
+		Id:    types.NewId(),
+		Price: Money{Amount: 1, Currency: "RUB"},
+	}
+
+	err := product.ValidateWith(nil)
+	require.Error(t, err)
+
+	violations := commonerr.FieldViolations(err)
+	require.Len(t, violations, 2)
+	assert.Equal(t, "name", violations[0].Field)
+	assert.Equal(t, "description", violations[1].Field)
+}
@@ -0,0 +1,129 @@
+package entity
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+const (
+	maxVariants          = 100
+	maxVariantOptions    = 10
+	maxOptionNameLength  = 64
+	maxOptionValueLength = 100
+)
+
+// Variant вариант продукта, например размер или цвет, со своими артикулом и ценой.
+type Variant struct {
+	Id    types.Id `json:"id" bson:"id"`
+	Sku   string   `json:"sku" bson:"sku"`
+	Price Money    `json:"price" bson:"price"`
+	// Options характеристики, отличающие вариант, например {"size": "XL", "color": "red"}
+	Options map[string]string `json:"options" bson:"options"`
+}
+
+func (v *Variant) Validate() error {
+	var vs violations
+
+	v.validate("", &vs)
+
+	return vs.err()
+}
+
+// validate добавляет нарушения варианта в vs. prefix — путь к варианту внутри продукта.
+func (v *Variant) validate(prefix string, vs *violations) {
+	if !skuRegexp.MatchString(v.Sku) {
+		vs.add(prefix+"sku", "variant sku must be 1-%d latin letters, digits, dots, dashes or underscores", maxSkuLength)
+	}
+
+	if err := v.Price.Validate(); err != nil {
+		vs.add(prefix+"price", "invalid variant price: %s", err.Error())
+	}
+
+	if len(v.Options) == 0 {
+		vs.add(prefix+"options", "variant must have at least one option")
+	}
+
+	if len(v.Options) > maxVariantOptions {
+		vs.add(prefix+"options", "variant can't have more than %d options", maxVariantOptions)
+	}
+
+	for _, name := range sortedKeys(v.Options) {
+		if name == "" || utf8.RuneCountInString(name) > maxOptionNameLength {
+			vs.add(prefix+"options", "option name must be 1-%d characters", maxOptionNameLength)
+
+			continue
+		}
+
+		if value := v.Options[name]; value == "" || utf8.RuneCountInString(value) > maxOptionValueLength {
+			vs.add(prefix+"options."+name, "option value must be 1-%d characters", maxOptionValueLength)
+		}
+	}
+}
+
+// optionsKey каноничное представление набора характеристик для поиска одинаковых вариантов.
+func (v *Variant) optionsKey() string {
+	names := make([]string, 0, len(v.Options))
+	for name := range v.Options {
+		names = append(names, name)
+	}
+
+	sort.Strings(names)
+
+	var key strings.Builder
+	for _, name := range names {
+		key.WriteString(name)
+		key.WriteByte(0)
+		key.WriteString(v.Options[name])
+		key.WriteByte(0)
+	}
+
+	return key.String()
+}
+
+// Variant возвращает вариант продукта по id.
+func (p *Product) Variant(variantId types.Id) (*Variant, bool) {
+	for i := range p.Variants {
+		if p.Variants[i].Id == variantId {
+			return &p.Variants[i], true
+		}
+	}
+
+	return nil, false
+}
+
+func (p *Product) validateVariants(vs *violations) {
+	if len(p.Variants) > maxVariants {
+		vs.add("variants", "product can't have more than %d variants", maxVariants)
+	}
+
+	skus := make(map[string]struct{}, len(p.Variants)+1)
+	if p.Sku != "" {
+		skus[p.Sku] = struct{}{}
+	}
+
+	options := make(map[string]struct{}, len(p.Variants))
+
+	for i := range p.Variants {
+		variant := &p.Variants[i]
+		prefix := fmt.Sprintf("variants[%d].", i)
+
+		variant.validate(prefix, vs)
+
+		if _, ok := skus[variant.Sku]; ok {
+			vs.add(prefix+"sku", "duplicate sku %s", variant.Sku)
+		}
+
+		skus[variant.Sku] = struct{}{}
+
+		key := variant.optionsKey()
+		if _, ok := options[key]; ok {
+			vs.add(prefix+"options", "variant %s duplicates options of another variant", variant.Sku)
+		}
+
+		options[key] = struct{}{}
+	}
+}
@@ -0,0 +1,85 @@
+package entity
+
+import (
+	"testing"
+
+	"github.com/brianvoe/gofakeit/v6"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func newVariant(sku string, options map[string]string) Variant {
+	return Variant{
+		Id:      types.NewId(),
+		Sku:     sku,
+		Price:   Money{Amount: 1000, Currency: "RUB"},
+		Options: options,
+	}
+}
+
+func TestProduct_ValidateVariants(t *testing.T) {
+	newProduct := func(variants ...Variant) *Product {
+		return &Product{ //nolint: exhaustruct
+			Id:          types.NewId(),
+			Name:        gofakeit.Name(),
+			Description: gofakeit.Name(),
+			Price:       Money{Amount: 100, Currency: "RUB"},
+			Sku:         "SHIRT",
+			Variants:    variants,
+		}
+	}
+
+	invalidPrice := newVariant("SHIRT-S", map[string]string{"size": "S"})
+	invalidPrice.Price = Money{Amount: 0, Currency: "RUB"}
+
+	testCases := []struct {
+		name    string
+		product *Product
+		wantErr bool
+	}{
+		{name: "without variants", product: newProduct(), wantErr: false},
+		{
+			name: "valid variants",
+			product: newProduct(
+				newVariant("SHIRT-S-RED", map[string]string{"size": "S", "color": "red"}),
+				newVariant("SHIRT-S-BLUE", map[string]string{"size": "S", "color": "blue"}),
+			),
+			wantErr: false,
+		},
+		{name: "invalid price", product: newProduct(invalidPrice), wantErr: true},
+		{name: "without options", product: newProduct(newVariant("SHIRT-S", nil)), wantErr: true},
+		{name: "empty option value", product: newProduct(newVariant("SHIRT-S", map[string]string{"size": ""})), wantErr: true},
+		{name: "invalid sku", product: newProduct(newVariant("", map[string]string{"size": "S"})), wantErr: true},
+		{name: "sku of product", product: newProduct(newVariant("SHIRT", map[string]string{"size": "S"})), wantErr: true},
+		{
+			name: "duplicate sku",
+			product: newProduct(
+				newVariant("SHIRT-S", map[string]string{"size": "S"}),
+				newVariant("SHIRT-S", map[string]string{"size": "M"}),
+			),
+			wantErr: true,
+		},
+		{
+			name: "duplicate options",
+			product: newProduct(
+				newVariant("SHIRT-S-RED", map[string]string{"size": "S", "color": "red"}),
+				newVariant("SHIRT-RED-S", map[string]string{"color": "red", "size": "S"}),
+			),
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			err := tc.product.Validate()
+			if tc.wantErr {
+				require.Error(t, err)
+				isAppError(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
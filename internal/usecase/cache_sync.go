@@ -0,0 +1,218 @@
+package usecase
+
+import (
+	"context"
+	"math"
+	"math/rand"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+)
+
+// maxSyncJitter верхняя граница Jitter: при отклонении на 100% и больше задержка может стать нулевой
+// или отрицательной, и синхронизация пойдет без пауз.
+const maxSyncJitter = 0.9
+
+type CacheSyncConfig struct {
+	// Interval период синхронизации кеша с базой при успешных синхронизациях
+	Interval time.Duration
+	// MinBackoff задержка перед первой повторной попыткой после ошибки. Удваивается с каждой ошибкой подряд
+	MinBackoff time.Duration
+	// MaxBackoff верхняя граница задержки между повторными попытками
+	MaxBackoff time.Duration
+	// Jitter доля случайного отклонения задержки, например 0.1 — ±10%. Разносит синхронизации реплик во времени.
+	// Ограничивается диапазоном [0, maxSyncJitter]
+	Jitter float64
+	// MaxStaleness время без успешной синхронизации, после которого поднимается алерт. 0 — не проверять
+	MaxStaleness time.Duration
+	// Timeout ограничение времени одной синхронизации. Зависшая синхронизация считается ошибкой. 0 — без ограничения
+	Timeout time.Duration
+}
+
+type cacheSyncState struct {
+	// requests запросы на внеочередную синхронизацию. В ответный канал пишется результат синхронизации
+	requests chan chan error
+
+	mutex            sync.Mutex
+	lastSyncedAt     time.Time
+	staleAlarmRaised bool
+
+	random func() float64
+}
+
+func newCacheSyncState() *cacheSyncState {
+	return &cacheSyncState{
+		requests:         make(chan chan error),
+		mutex:            sync.Mutex{},
+		lastSyncedAt:     time.Now(),
+		staleAlarmRaised: false,
+		random:           rand.Float64, //nolint: gosec // криптостойкость для джиттера не нужна
+	}
+}
+
+// ListenCacheInvalidation удаляет из негативного кеша продукты, измененные или восстановленные на других
+// репликах, до отмены ctx. Без канала инвалидации сразу возвращает nil.
+func (p *ProductUseCase) ListenCacheInvalidation(ctx context.Context) error {
+	return p.loader.Run(ctx)
+}
+
+// SyncCache периодически синхронизирует кеш с базой до отмены ctx. Первая синхронизация выполняется сразу.
+// После ошибки повторная попытка делается с экспоненциально растущей задержкой.
+func (p *ProductUseCase) SyncCache(ctx context.Context) {
+	timer := time.NewTimer(0)
+	defer timer.Stop()
+
+	failures := 0
+
+	for {
+		var reply chan error
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-timer.C:
+		case reply = <-p.syncState.requests:
+			if !timer.Stop() {
+				select {
+				case <-timer.C:
+				default:
+				}
+			}
+		}
+
+		err := p.syncCache(ctx)
+		if reply != nil {
+			reply <- err
+		}
+
+		if err != nil {
+			failures++
+		} else {
+			failures = 0
+		}
+
+		p.checkStaleness(ctx)
+		timer.Reset(p.nextSyncDelay(failures))
+	}
+}
+
+// ResyncProductsCache запускает внеочередную синхронизацию кеша и дожидается ее результата.
+func (p *ProductUseCase) ResyncProductsCache(ctx context.Context) error {
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.ResyncProductsCache")
+	defer span.End()
+
+	reply := make(chan error, 1)
+
+	select {
+	case p.syncState.requests <- reply:
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+
+	select {
+	case err := <-reply:
+		return err
+	case <-ctx.Done():
+		return errors.WithStack(ctx.Err())
+	}
+}
+
+func (p *ProductUseCase) syncCache(ctx context.Context) error {
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.syncCache")
+	defer span.End()
+
+	if p.syncConfig.Timeout > 0 {
+		var cancel context.CancelFunc
+
+		ctx, cancel = context.WithTimeout(ctx, p.syncConfig.Timeout)
+		defer cancel()
+	}
+
+	p.logger.Infow("Start syncing cache")
+
+	l, err := p.repo.GetProducts(ctx)
+	if err != nil {
+		err = errors.WithStack(err)
+		commonerr.SendToSentry(ctx, err, nil)
+		p.logger.Errorw("can't sync cache", "err", err)
+		cacheSyncFailures.Inc()
+
+		return err
+	}
+
+	err = p.cache.Replace(l)
+	if err != nil {
+		err = errors.WithStack(err)
+		commonerr.SendToSentry(ctx, err, nil)
+		p.logger.Errorw("can't set batch in cache", "err", err)
+		cacheSyncFailures.Inc()
+
+		return err
+	}
+
+	now := time.Now()
+
+	p.syncState.mutex.Lock()
+	p.syncState.lastSyncedAt = now
+	p.syncState.staleAlarmRaised = false
+	p.syncState.mutex.Unlock()
+
+	cacheLastSyncTimestamp.Set(float64(now.Unix()))
+	p.logger.Infow("Cache synced with database")
+
+	return nil
+}
+
+// nextSyncDelay возвращает задержку до следующей синхронизации с учетом числа ошибок подряд.
+func (p *ProductUseCase) nextSyncDelay(failures int) time.Duration {
+	delay := p.syncConfig.Interval
+
+	if failures > 0 {
+		delay = p.syncConfig.MinBackoff
+
+		for i := 1; i < failures && delay < p.syncConfig.MaxBackoff; i++ {
+			delay *= 2
+		}
+
+		if delay > p.syncConfig.MaxBackoff {
+			delay = p.syncConfig.MaxBackoff
+		}
+	}
+
+	if jitter := math.Min(p.syncConfig.Jitter, maxSyncJitter); jitter > 0 {
+		// равномерно в диапазоне [delay*(1-jitter), delay*(1+jitter))
+		delay += time.Duration(float64(delay) * jitter * (2*p.syncState.random() - 1))
+	}
+
+	return delay
+}
+
+// checkStaleness поднимает алерт один раз за период, пока кеш не синхронизируется дольше MaxStaleness.
+func (p *ProductUseCase) checkStaleness(ctx context.Context) {
+	p.syncState.mutex.Lock()
+	staleness := time.Since(p.syncState.lastSyncedAt)
+	raise := p.syncConfig.MaxStaleness > 0 &&
+		staleness > p.syncConfig.MaxStaleness &&
+		!p.syncState.staleAlarmRaised
+
+	if raise {
+		p.syncState.staleAlarmRaised = true
+	}
+	p.syncState.mutex.Unlock()
+
+	cacheStaleness.Set(staleness.Seconds())
+
+	if !raise {
+		return
+	}
+
+	err := errors.Errorf("products cache is stale for %s", staleness.Round(time.Second))
+	commonerr.SendToSentry(ctx, err, &commonerr.SentryInfo{
+		Tags: map[string]string{"alarm": "cache-staleness"},
+	})
+	p.logger.Errorw("products cache exceeded max staleness", "staleness", staleness, "maxStaleness", p.syncConfig.MaxStaleness)
+}
@@ -0,0 +1,295 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/usecase/repo"
+	"github.com/qulaz/artforintrovert-test/pkg/cache"
+)
+
+func TestProductUseCase_syncCache(t *testing.T) {
+	products := []*entity.Product{newValidProduct(), newValidProduct(), newValidProduct()}
+
+	testCases := []struct {
+		name    string
+		mock    func(mockRepo *repo.MockRepository, mockCache *cache.MockEntityCache[*entity.Product])
+		wantErr bool
+	}{
+		{
+			name: "Success",
+			mock: func(mockRepo *repo.MockRepository, mockCache *cache.MockEntityCache[*entity.Product]) {
+				mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+				mockCache.EXPECT().Replace(products).Return(nil)
+			},
+			wantErr: false,
+		},
+		{
+			name: "Repo error",
+			mock: func(mockRepo *repo.MockRepository, mockCache *cache.MockEntityCache[*entity.Product]) {
+				mockRepo.EXPECT().GetProducts(gomock.Any()).Return(nil, errors.New(""))
+			},
+			wantErr: true,
+		},
+		{
+			name: "Cache error",
+			mock: func(mockRepo *repo.MockRepository, mockCache *cache.MockEntityCache[*entity.Product]) {
+				mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+				mockCache.EXPECT().Replace(products).Return(errors.New(""))
+			},
+			wantErr: true,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+			defer teardown()
+
+			// test fails if has been called unexpected func or expected not been called
+			tc.mock(mockRepo, mockCache)
+
+			err := uc.syncCache(context.Background())
+			if tc.wantErr {
+				require.Error(t, err)
+			} else {
+				require.NoError(t, err)
+			}
+		})
+	}
+}
+
+func TestProductUseCase_syncCacheTimeout(t *testing.T) {
+	uc, mockRepo, _, teardown := newProductUseCase(t)
+	defer teardown()
+
+	uc.syncConfig.Timeout = time.Millisecond * 10
+
+	// зависший запрос к базе прерывается по таймауту синхронизации
+	mockRepo.EXPECT().GetProducts(gomock.Any()).DoAndReturn(func(ctx context.Context) ([]*entity.Product, error) {
+		<-ctx.Done()
+
+		return nil, ctx.Err()
+	})
+
+	err := uc.syncCache(context.Background())
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, context.DeadlineExceeded))
+}
+
+// runSyncCache запускает цикл синхронизации и возвращает функцию, которая останавливает его
+// и проверяет, что цикл завершился после отмены контекста.
+func runSyncCache(t *testing.T, uc *ProductUseCase) func() {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+		uc.SyncCache(ctx)
+	}()
+
+	return func() {
+		cancel()
+
+		select {
+		case <-done:
+		case <-time.After(time.Second):
+			t.Error("SyncCache did not stop after context cancellation")
+		}
+	}
+}
+
+func TestProductUseCase_SyncCache_RetriesWithBackoff(t *testing.T) {
+	uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+	defer teardown()
+
+	products := []*entity.Product{newValidProduct()}
+	synced := make(chan struct{})
+
+	gomock.InOrder(
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(nil, errors.New("")),
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(nil, errors.New("")),
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil),
+	)
+	mockCache.EXPECT().Replace(products).DoAndReturn(func(_ []*entity.Product) error {
+		close(synced)
+
+		return nil
+	})
+
+	stop := runSyncCache(t, uc)
+	defer stop()
+
+	// интервал синхронизации — час, поэтому повторы возможны только благодаря backoff
+	select {
+	case <-synced:
+	case <-time.After(time.Second):
+		t.Fatal("cache was not synced after failures")
+	}
+}
+
+func TestProductUseCase_ResyncProductsCache(t *testing.T) {
+	// waitInitialSync ждет первую синхронизацию, которую цикл делает сразу после запуска
+	waitInitialSync := func(t *testing.T, initialSynced chan struct{}) {
+		t.Helper()
+
+		select {
+		case <-initialSynced:
+		case <-time.After(time.Second):
+			t.Fatal("initial sync was not performed")
+		}
+	}
+
+	t.Run("forces immediate sync", func(t *testing.T) {
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		products := []*entity.Product{newValidProduct()}
+		initialSynced := make(chan struct{})
+
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil).Times(2)
+		gomock.InOrder(
+			mockCache.EXPECT().Replace(products).DoAndReturn(func(_ []*entity.Product) error {
+				close(initialSynced)
+
+				return nil
+			}),
+			mockCache.EXPECT().Replace(products).Return(nil),
+		)
+
+		stop := runSyncCache(t, uc)
+		defer stop()
+
+		waitInitialSync(t, initialSynced)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		require.NoError(t, uc.ResyncProductsCache(ctx))
+	})
+	t.Run("returns sync error", func(t *testing.T) {
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		products := []*entity.Product{newValidProduct()}
+		initialSynced := make(chan struct{})
+
+		gomock.InOrder(
+			mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil),
+			mockRepo.EXPECT().GetProducts(gomock.Any()).Return(nil, errors.New("")),
+			// после ошибки цикл продолжит повторы с backoff
+			mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil).AnyTimes(),
+		)
+		mockCache.EXPECT().Replace(products).DoAndReturn(func(_ []*entity.Product) error {
+			close(initialSynced)
+
+			return nil
+		})
+		mockCache.EXPECT().Replace(products).Return(nil).AnyTimes()
+
+		stop := runSyncCache(t, uc)
+		defer stop()
+
+		waitInitialSync(t, initialSynced)
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Second)
+		defer cancel()
+
+		require.Error(t, uc.ResyncProductsCache(ctx))
+	})
+	t.Run("sync loop is not running", func(t *testing.T) {
+		uc, _, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		ctx, cancel := context.WithTimeout(context.Background(), time.Millisecond*10)
+		defer cancel()
+
+		err := uc.ResyncProductsCache(ctx)
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, context.DeadlineExceeded))
+	})
+}
+
+func TestProductUseCase_nextSyncDelay(t *testing.T) {
+	uc, _, _, teardown := newProductUseCase(t)
+	defer teardown()
+
+	uc.syncConfig = CacheSyncConfig{
+		Interval:     time.Minute,
+		MinBackoff:   time.Second,
+		MaxBackoff:   time.Second * 10,
+		Jitter:       0,
+		MaxStaleness: 0,
+		Timeout:      0,
+	}
+
+	testCases := []struct {
+		failures int
+		expected time.Duration
+	}{
+		{failures: 0, expected: time.Minute},
+		{failures: 1, expected: time.Second},
+		{failures: 2, expected: time.Second * 2},
+		{failures: 3, expected: time.Second * 4},
+		{failures: 4, expected: time.Second * 8},
+		{failures: 5, expected: time.Second * 10},
+		{failures: 100, expected: time.Second * 10},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, uc.nextSyncDelay(tc.failures), "failures: %d", tc.failures)
+	}
+
+	t.Run("jitter", func(t *testing.T) {
+		uc.syncConfig.Jitter = 0.1
+
+		uc.syncState.random = func() float64 { return 0 }
+		assert.Equal(t, time.Second*54, uc.nextSyncDelay(0))
+
+		uc.syncState.random = func() float64 { return 0.5 }
+		assert.Equal(t, time.Minute, uc.nextSyncDelay(0))
+
+		uc.syncState.random = func() float64 { return 1 }
+		assert.Equal(t, time.Second*66, uc.nextSyncDelay(0))
+	})
+	t.Run("jitter is clamped", func(t *testing.T) {
+		uc.syncConfig.Jitter = 1.5
+
+		uc.syncState.random = func() float64 { return 0 }
+		assert.Equal(t, time.Second*6, uc.nextSyncDelay(0))
+
+		uc.syncConfig.Jitter = -1
+		assert.Equal(t, time.Minute, uc.nextSyncDelay(0))
+	})
+}
+
+func TestProductUseCase_checkStaleness(t *testing.T) {
+	uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+	defer teardown()
+
+	uc.syncConfig.MaxStaleness = time.Minute
+	uc.syncState.lastSyncedAt = time.Now().Add(-time.Hour)
+
+	uc.checkStaleness(context.Background())
+	assert.True(t, uc.syncState.staleAlarmRaised)
+
+	products := []*entity.Product{newValidProduct()}
+	mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
+	mockCache.EXPECT().Replace(products).Return(nil)
+
+	require.NoError(t, uc.syncCache(context.Background()))
+	uc.checkStaleness(context.Background())
+	assert.False(t, uc.syncState.staleAlarmRaised)
+}
@@ -0,0 +1,403 @@
+package usecase
+
+import (
+	"context"
+	"math/rand"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+	"github.com/qulaz/artforintrovert-test/pkg/cache"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+const (
+	verifierPageSize = 1000
+	// maxReportedIds ограничивает число id, отправляемых в Sentry, чтобы не раздувать событие
+	maxReportedIds = 100
+)
+
+type CacheVerifierConfig struct {
+	// Interval период между проверками
+	Interval time.Duration
+	// SampleSize число случайных записей из базы и из кеша для сверки. 0 — полная сверка
+	SampleSize int
+	// SelfHeal исправлять расхождения в кеше данными из базы
+	SelfHeal bool
+}
+
+// DriftReport результат сверки кеша с базой. Содержит id расхождений по типам.
+type DriftReport struct {
+	Checked int
+	// Missing есть в базе, но нет в кеше
+	Missing []string
+	// Stale в кеше отличается от базы
+	Stale []string
+	// Extra есть в кеше, но нет в базе
+	Extra  []string
+	Healed bool
+}
+
+func (r *DriftReport) HasDrift() bool {
+	return len(r.Missing)+len(r.Stale)+len(r.Extra) > 0
+}
+
+// CacheVerifier периодически сверяет содержимое кеша продуктов с базой по контрольным суммам.
+type CacheVerifier struct {
+	repo   Repository
+	cache  cache.EntityCache[*entity.Product]
+	logger logging.ContextLogger
+	config CacheVerifierConfig
+	random *rand.Rand
+}
+
+func NewCacheVerifier(
+	repo Repository,
+	productCache cache.EntityCache[*entity.Product],
+	logger logging.ContextLogger,
+	config CacheVerifierConfig,
+) *CacheVerifier {
+	return &CacheVerifier{
+		repo:   repo,
+		cache:  productCache,
+		logger: logger,
+		config: config,
+		random: rand.New(rand.NewSource(time.Now().UnixNano())), //nolint: gosec // выборка не требует криптостойкости
+	}
+}
+
+func (v *CacheVerifier) Run(ctx context.Context) {
+	ticker := time.NewTicker(v.config.Interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if _, err := v.Verify(ctx); err != nil {
+				commonerr.SendToSentry(ctx, err, nil)
+				v.logger.Errorw("can't verify products cache", "err", err)
+			}
+		}
+	}
+}
+
+func (v *CacheVerifier) Verify(ctx context.Context) (*DriftReport, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "cacheVerifier.Verify")
+	defer span.End()
+
+	var (
+		dbProducts     []*entity.Product
+		cachedProducts []*entity.Product
+		err            error
+	)
+
+	if v.config.SampleSize > 0 {
+		dbProducts, cachedProducts, err = v.sample(ctx)
+	} else {
+		dbProducts, cachedProducts, err = v.all(ctx)
+	}
+
+	if err != nil {
+		cacheVerifications.WithLabelValues("error").Inc()
+
+		return nil, err
+	}
+
+	report := compareProducts(dbProducts, cachedProducts)
+
+	if report.HasDrift() {
+		// между чтением базы и кеша продукт мог измениться — перепроверяем расхождения
+		report, err = v.confirm(ctx, report)
+		if err != nil {
+			cacheVerifications.WithLabelValues("error").Inc()
+
+			return nil, err
+		}
+	}
+
+	v.reportDrift(ctx, report)
+
+	return report, nil
+}
+
+// all возвращает полное содержимое базы и кеша.
+func (v *CacheVerifier) all(ctx context.Context) ([]*entity.Product, []*entity.Product, error) {
+	dbProducts, err := v.repo.GetProducts(ctx)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	cachedProducts := make([]*entity.Product, 0, len(dbProducts))
+
+	for offset := uint(0); ; offset += verifierPageSize {
+		page, err := v.cache.GetList(verifierPageSize, offset)
+		if err != nil {
+			return nil, nil, errors.WithStack(err)
+		}
+
+		cachedProducts = append(cachedProducts, page...)
+
+		if len(page) < verifierPageSize {
+			break
+		}
+	}
+
+	return dbProducts, cachedProducts, nil
+}
+
+// sample возвращает случайные записи из базы и из кеша вместе с их парами из другого источника.
+func (v *CacheVerifier) sample(ctx context.Context) ([]*entity.Product, []*entity.Product, error) {
+	dbSample, err := v.repo.SampleProducts(ctx, v.config.SampleSize)
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	cacheSample, err := v.sampleCache()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	dbCounterparts, err := v.repo.GetProductsByIds(ctx, productIds(cacheSample))
+	if err != nil {
+		return nil, nil, errors.WithStack(err)
+	}
+
+	dbProducts := uniqueProducts(append(dbSample, dbCounterparts...))
+	cachedProducts := uniqueProducts(append(cacheSample, v.getCached(productKeys(dbSample))...))
+
+	return dbProducts, cachedProducts, nil
+}
+
+// sampleCache выбирает до SampleSize случайных записей из кеша.
+func (v *CacheVerifier) sampleCache() ([]*entity.Product, error) {
+	var all []*entity.Product
+
+	for offset := uint(0); ; offset += verifierPageSize {
+		page, err := v.cache.GetList(verifierPageSize, offset)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		all = append(all, page...)
+
+		if len(page) < verifierPageSize {
+			break
+		}
+	}
+
+	if len(all) <= v.config.SampleSize {
+		return all, nil
+	}
+
+	sample := make([]*entity.Product, v.config.SampleSize)
+	for i, idx := range v.random.Perm(len(all))[:v.config.SampleSize] {
+		sample[i] = all[idx]
+	}
+
+	return sample, nil
+}
+
+// confirm повторно читает расходящиеся записи из базы и кеша и оставляет в отчете только подтвердившиеся.
+func (v *CacheVerifier) confirm(ctx context.Context, report *DriftReport) (*DriftReport, error) {
+	keys := make([]string, 0, len(report.Missing)+len(report.Stale)+len(report.Extra))
+	keys = append(keys, report.Missing...)
+	keys = append(keys, report.Stale...)
+	keys = append(keys, report.Extra...)
+
+	ids := make([]types.Id, 0, len(keys))
+
+	for _, key := range keys {
+		id, err := types.NewIdFromString(key)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		ids = append(ids, id)
+	}
+
+	dbProducts, err := v.repo.GetProductsByIds(ctx, ids)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	confirmed := compareProducts(dbProducts, v.getCached(keys))
+	confirmed.Checked = report.Checked
+
+	return confirmed, nil
+}
+
+func (v *CacheVerifier) reportDrift(ctx context.Context, report *DriftReport) {
+	cacheDriftEntries.WithLabelValues("missing").Set(float64(len(report.Missing)))
+	cacheDriftEntries.WithLabelValues("stale").Set(float64(len(report.Stale)))
+	cacheDriftEntries.WithLabelValues("extra").Set(float64(len(report.Extra)))
+
+	if !report.HasDrift() {
+		cacheVerifications.WithLabelValues("consistent").Inc()
+		v.logger.Debugw("Products cache is consistent with database", "checked", report.Checked)
+
+		return
+	}
+
+	cacheVerifications.WithLabelValues("drift").Inc()
+
+	commonerr.SendToSentry(ctx, errors.New("products cache drift detected"), &commonerr.SentryInfo{
+		Tags: map[string]string{"alarm": "cache-drift"},
+		Extras: map[string]interface{}{
+			"checked": report.Checked,
+			"missing": truncateIds(report.Missing),
+			"stale":   truncateIds(report.Stale),
+			"extra":   truncateIds(report.Extra),
+		},
+	})
+	v.logger.Warnw(
+		"products cache drift detected",
+		"checked", report.Checked,
+		"missing", truncateIds(report.Missing),
+		"stale", truncateIds(report.Stale),
+		"extra", truncateIds(report.Extra),
+	)
+
+	if v.config.SelfHeal {
+		report.Healed = v.heal(ctx, report)
+	}
+}
+
+// heal приводит расходящиеся записи кеша к состоянию базы.
+func (v *CacheVerifier) heal(ctx context.Context, report *DriftReport) bool {
+	keys := append(append([]string{}, report.Missing...), report.Stale...)
+	ids := make([]types.Id, 0, len(keys))
+
+	for _, key := range keys {
+		if id, err := types.NewIdFromString(key); err == nil {
+			ids = append(ids, id)
+		}
+	}
+
+	healed := true
+
+	if len(ids) > 0 {
+		products, err := v.repo.GetProductsByIds(ctx, ids)
+		if err != nil {
+			v.logger.Errorw("can't heal products cache", "err", err)
+
+			return false
+		}
+
+		for _, product := range products {
+			if err := v.cache.Set(product); err != nil {
+				v.logger.Errorw("can't heal product in cache", "productId", product.Hash(), "err", err)
+				healed = false
+			}
+		}
+	}
+
+	for _, key := range report.Extra {
+		if err := v.cache.Delete(key); err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+			v.logger.Errorw("can't remove extra product from cache", "productId", key, "err", err)
+			healed = false
+		}
+	}
+
+	if healed {
+		v.logger.Infow("Products cache healed", "count", len(keys)+len(report.Extra))
+	}
+
+	return healed
+}
+
+func (v *CacheVerifier) getCached(keys []string) []*entity.Product {
+	products := make([]*entity.Product, 0, len(keys))
+
+	for _, key := range keys {
+		if product, err := v.cache.Get(key); err == nil {
+			products = append(products, product)
+		}
+	}
+
+	return products
+}
+
+func compareProducts(dbProducts []*entity.Product, cachedProducts []*entity.Product) *DriftReport {
+	report := &DriftReport{} //nolint: exhaustruct
+
+	dbChecksums := make(map[string]string, len(dbProducts))
+	for _, product := range dbProducts {
+		dbChecksums[product.Hash()] = product.Checksum()
+	}
+
+	cachedChecksums := make(map[string]string, len(cachedProducts))
+	for _, product := range cachedProducts {
+		cachedChecksums[product.Hash()] = product.Checksum()
+	}
+
+	for _, product := range dbProducts {
+		key := product.Hash()
+
+		cachedChecksum, ok := cachedChecksums[key]
+
+		switch {
+		case !ok:
+			report.Missing = append(report.Missing, key)
+		case cachedChecksum != dbChecksums[key]:
+			report.Stale = append(report.Stale, key)
+		}
+	}
+
+	for _, product := range cachedProducts {
+		if _, ok := dbChecksums[product.Hash()]; !ok {
+			report.Extra = append(report.Extra, product.Hash())
+		}
+	}
+
+	report.Checked = len(dbChecksums) + len(report.Extra)
+
+	return report
+}
+
+func uniqueProducts(products []*entity.Product) []*entity.Product {
+	seen := make(map[string]struct{}, len(products))
+	unique := make([]*entity.Product, 0, len(products))
+
+	for _, product := range products {
+		if _, ok := seen[product.Hash()]; ok {
+			continue
+		}
+
+		seen[product.Hash()] = struct{}{}
+		unique = append(unique, product)
+	}
+
+	return unique
+}
+
+func productIds(products []*entity.Product) []types.Id {
+	ids := make([]types.Id, len(products))
+	for i, product := range products {
+		ids[i] = product.Id
+	}
+
+	return ids
+}
+
+func productKeys(products []*entity.Product) []string {
+	keys := make([]string, len(products))
+	for i, product := range products {
+		keys[i] = product.Hash()
+	}
+
+	return keys
+}
+
+func truncateIds(ids []string) []string {
+	if len(ids) > maxReportedIds {
+		return ids[:maxReportedIds]
+	}
+
+	return ids
+}
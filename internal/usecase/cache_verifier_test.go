@@ -0,0 +1,143 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/usecase/repo"
+	"github.com/qulaz/artforintrovert-test/pkg/cache"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+func newCacheVerifier(t *testing.T, config CacheVerifierConfig) (
+	*CacheVerifier,
+	*repo.MockRepository,
+	*cache.MockEntityCache[*entity.Product],
+	func(),
+) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockRepo := repo.NewMockRepository(ctrl)
+	mockCache := cache.NewMockEntityCache[*entity.Product](ctrl)
+
+	return NewCacheVerifier(mockRepo, mockCache, logging.NewDummyLogger(), config), mockRepo, mockCache, ctrl.Finish
+}
+
+func staleCopy(product *entity.Product) *entity.Product {
+	stale := *product
+	stale.Name += " (stale)"
+
+	return &stale
+}
+
+func TestCacheVerifier_Verify(t *testing.T) {
+	a, b, c, extra := newValidProduct(), newValidProduct(), newValidProduct(), newValidProduct()
+	staleB := staleCopy(b)
+
+	t.Run("consistent", func(t *testing.T) {
+		t.Parallel()
+		v, mockRepo, mockCache, teardown := newCacheVerifier(t, CacheVerifierConfig{}) //nolint: exhaustruct
+		defer teardown()
+
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return([]*entity.Product{a, b}, nil)
+		mockCache.EXPECT().GetList(uint(verifierPageSize), uint(0)).Return([]*entity.Product{a, b}, nil)
+
+		report, err := v.Verify(context.Background())
+		require.NoError(t, err)
+		assert.False(t, report.HasDrift())
+		assert.Equal(t, 2, report.Checked)
+	})
+	t.Run("drift", func(t *testing.T) {
+		t.Parallel()
+		v, mockRepo, mockCache, teardown := newCacheVerifier(t, CacheVerifierConfig{}) //nolint: exhaustruct
+		defer teardown()
+
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return([]*entity.Product{a, b, c}, nil)
+		mockCache.EXPECT().GetList(uint(verifierPageSize), uint(0)).Return([]*entity.Product{a, staleB, extra}, nil)
+
+		// перепроверка расхождений
+		mockRepo.EXPECT().GetProductsByIds(gomock.Any(), gomock.Any()).Return([]*entity.Product{b, c}, nil)
+		mockCache.EXPECT().Get(c.Hash()).Return(nil, cache.ErrKeyNotFound)
+		mockCache.EXPECT().Get(b.Hash()).Return(staleB, nil)
+		mockCache.EXPECT().Get(extra.Hash()).Return(extra, nil)
+
+		report, err := v.Verify(context.Background())
+		require.NoError(t, err)
+		assert.True(t, report.HasDrift())
+		assert.Equal(t, []string{c.Hash()}, report.Missing)
+		assert.Equal(t, []string{b.Hash()}, report.Stale)
+		assert.Equal(t, []string{extra.Hash()}, report.Extra)
+		assert.False(t, report.Healed)
+	})
+	t.Run("drift resolved during confirmation", func(t *testing.T) {
+		t.Parallel()
+		v, mockRepo, mockCache, teardown := newCacheVerifier(t, CacheVerifierConfig{}) //nolint: exhaustruct
+		defer teardown()
+
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return([]*entity.Product{a, b}, nil)
+		mockCache.EXPECT().GetList(uint(verifierPageSize), uint(0)).Return([]*entity.Product{a, staleB}, nil)
+
+		// продукт обновили в кеше между чтениями
+		mockRepo.EXPECT().GetProductsByIds(gomock.Any(), gomock.Any()).Return([]*entity.Product{b}, nil)
+		mockCache.EXPECT().Get(b.Hash()).Return(b, nil)
+
+		report, err := v.Verify(context.Background())
+		require.NoError(t, err)
+		assert.False(t, report.HasDrift())
+	})
+	t.Run("self heal", func(t *testing.T) {
+		t.Parallel()
+		v, mockRepo, mockCache, teardown := newCacheVerifier(t, CacheVerifierConfig{SelfHeal: true}) //nolint: exhaustruct
+		defer teardown()
+
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return([]*entity.Product{a, b, c}, nil)
+		mockCache.EXPECT().GetList(uint(verifierPageSize), uint(0)).Return([]*entity.Product{a, staleB, extra}, nil)
+
+		mockRepo.EXPECT().GetProductsByIds(gomock.Any(), gomock.Any()).Return([]*entity.Product{b, c}, nil).Times(2)
+		mockCache.EXPECT().Get(c.Hash()).Return(nil, cache.ErrKeyNotFound)
+		mockCache.EXPECT().Get(b.Hash()).Return(staleB, nil)
+		mockCache.EXPECT().Get(extra.Hash()).Return(extra, nil)
+
+		mockCache.EXPECT().Set(b).Return(nil)
+		mockCache.EXPECT().Set(c).Return(nil)
+		mockCache.EXPECT().Delete(extra.Hash()).Return(nil)
+
+		report, err := v.Verify(context.Background())
+		require.NoError(t, err)
+		assert.True(t, report.HasDrift())
+		assert.True(t, report.Healed)
+	})
+	t.Run("sample", func(t *testing.T) {
+		t.Parallel()
+		v, mockRepo, mockCache, teardown := newCacheVerifier(t, CacheVerifierConfig{SampleSize: 2}) //nolint: exhaustruct
+		defer teardown()
+
+		mockRepo.EXPECT().SampleProducts(gomock.Any(), 2).Return([]*entity.Product{c}, nil)
+		mockCache.EXPECT().GetList(uint(verifierPageSize), uint(0)).Return([]*entity.Product{a, b}, nil)
+		mockRepo.EXPECT().GetProductsByIds(gomock.Any(), gomock.Any()).Return([]*entity.Product{a, b}, nil)
+		mockCache.EXPECT().Get(c.Hash()).Return(c, nil)
+
+		report, err := v.Verify(context.Background())
+		require.NoError(t, err)
+		assert.False(t, report.HasDrift())
+		assert.Equal(t, 3, report.Checked)
+	})
+	t.Run("repo error", func(t *testing.T) {
+		t.Parallel()
+		v, mockRepo, _, teardown := newCacheVerifier(t, CacheVerifierConfig{}) //nolint: exhaustruct
+		defer teardown()
+
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(nil, errors.New(""))
+
+		report, err := v.Verify(context.Background())
+		require.Error(t, err)
+		assert.Nil(t, report)
+	})
+}
@@ -0,0 +1,283 @@
+package usecase
+
+import (
+	"context"
+	"time"
+
+	"github.com/pkg/errors"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+	"github.com/qulaz/artforintrovert-test/pkg/cache"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+const categoriesPageSize = 1000
+
+var _ Category = (*CategoryUseCase)(nil)
+
+type CategoryUseCase struct {
+	repo     CategoryRepository
+	products Product
+	logger   logging.ContextLogger
+	cache    cache.EntityCache[*entity.Category]
+	loader   *cache.Loader[*entity.Category]
+}
+
+func NewCategoryUseCase(
+	repo CategoryRepository,
+	products Product,
+	categoryCache cache.EntityCache[*entity.Category],
+	logger logging.ContextLogger,
+) *CategoryUseCase {
+	return &CategoryUseCase{
+		repo:     repo,
+		products: products,
+		logger:   logger,
+		cache:    categoryCache,
+		loader: cache.NewLoader[*entity.Category](
+			categoryCache,
+			func(ctx context.Context, key string) (*entity.Category, error) {
+				id, err := types.NewIdFromString(key)
+				if err != nil {
+					return nil, err
+				}
+
+				return repo.GetCategory(ctx, id)
+			},
+			repo.GetCategories,
+			cache.LoaderOptions{NegativeTtl: 0, IsNotFound: nil},
+		),
+	}
+}
+
+// SyncCache периодически перечитывает категории из базы, подхватывая изменения других реплик.
+func (c *CategoryUseCase) SyncCache(ctx context.Context, interval time.Duration) {
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		if err := c.syncCache(ctx); err != nil {
+			commonerr.SendToSentry(ctx, err, nil)
+			c.logger.Errorw("can't sync categories cache", "err", err)
+		}
+
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+		}
+	}
+}
+
+func (c *CategoryUseCase) syncCache(ctx context.Context) error {
+	ctx, span := tracing.Tracer.Start(ctx, "categoryUseCase.syncCache")
+	defer span.End()
+
+	categories, err := c.repo.GetCategories(ctx)
+	if err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := c.cache.Replace(categories); err != nil {
+		return errors.WithStack(err)
+	}
+
+	return nil
+}
+
+func (c *CategoryUseCase) GetCategory(ctx context.Context, id types.Id) (*entity.Category, error) {
+	ctx, _ = c.logger.FromContext(ctx, "categoryId", id)
+	ctx, span := tracing.Tracer.Start(ctx, "categoryUseCase.GetCategory")
+	defer span.End()
+
+	category, err := c.loader.Get(ctx, id.Hex())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return category, nil
+}
+
+func (c *CategoryUseCase) GetCategoryTree(ctx context.Context, rootId types.Id) ([]*entity.CategoryNode, error) {
+	ctx, _ = c.logger.FromContext(ctx, "rootId", rootId)
+	ctx, span := tracing.Tracer.Start(ctx, "categoryUseCase.GetCategoryTree")
+	defer span.End()
+
+	if !rootId.IsZero() {
+		if _, err := c.GetCategory(ctx, rootId); err != nil {
+			return nil, err
+		}
+	}
+
+	categories, err := c.allCategories(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	return entity.BuildCategoryTree(categories, rootId), nil
+}
+
+func (c *CategoryUseCase) CreateCategory(ctx context.Context, category *entity.Category) (*entity.Category, error) {
+	ctx, logger := c.logger.FromContext(ctx, "category", category)
+	ctx, span := tracing.Tracer.Start(ctx, "categoryUseCase.CreateCategory")
+	defer span.End()
+
+	if err := category.Validate(); err != nil {
+		return nil, err
+	}
+
+	category.Id = types.NewId()
+	category.AncestorIds = []types.Id{}
+
+	if !category.ParentId.IsZero() {
+		parent, err := c.repo.GetCategory(ctx, category.ParentId)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		category.AncestorIds = parent.PathIds()
+	}
+
+	if err := c.repo.CreateCategory(ctx, category); err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := c.cache.Set(category); err != nil {
+		logger.Warnw("error while adding category to cache", "err", err)
+	}
+
+	return category, nil
+}
+
+func (c *CategoryUseCase) UpdateCategory(
+	ctx context.Context,
+	id types.Id,
+	updatedCategory *entity.Category,
+) (*entity.Category, error) {
+	ctx, logger := c.logger.FromContext(ctx, "categoryId", id, "updatedCategory", updatedCategory)
+	ctx, span := tracing.Tracer.Start(ctx, "categoryUseCase.UpdateCategory")
+	defer span.End()
+
+	if err := updatedCategory.Validate(); err != nil {
+		return nil, err
+	}
+
+	category, err := c.repo.UpdateCategory(ctx, id, updatedCategory)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := c.cache.Set(category); err != nil {
+		logger.Warnw("error while updating category in cache", "err", err)
+	}
+
+	return category, nil
+}
+
+func (c *CategoryUseCase) DeleteCategory(ctx context.Context, id types.Id) error {
+	ctx, logger := c.logger.FromContext(ctx, "categoryId", id)
+	ctx, span := tracing.Tracer.Start(ctx, "categoryUseCase.DeleteCategory")
+	defer span.End()
+
+	if err := c.repo.DeleteCategory(ctx, id); err != nil {
+		return errors.WithStack(err)
+	}
+
+	if err := c.cache.Delete(id.Hex()); err != nil && !errors.Is(err, cache.ErrKeyNotFound) {
+		logger.Warnw("error while deleting category from cache", "err", err)
+	}
+
+	return nil
+}
+
+func (c *CategoryUseCase) MoveCategory(ctx context.Context, id types.Id, parentId types.Id) (*entity.Category, error) {
+	ctx, logger := c.logger.FromContext(ctx, "categoryId", id, "parentId", parentId)
+	ctx, span := tracing.Tracer.Start(ctx, "categoryUseCase.MoveCategory")
+	defer span.End()
+
+	if id == parentId {
+		return nil, commonerr.NewIncorrectInputError("category can't be moved into itself")
+	}
+
+	ancestorIds := []types.Id{}
+
+	if !parentId.IsZero() {
+		parent, err := c.repo.GetCategory(ctx, parentId)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		if parent.IsDescendantOf(id) {
+			return nil, commonerr.NewIncorrectInputError("category can't be moved into its own subtree")
+		}
+
+		ancestorIds = parent.PathIds()
+	}
+
+	category, err := c.repo.MoveCategory(ctx, id, parentId, ancestorIds)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	// у потомков изменились пути, поэтому кеш перечитывается целиком
+	if err := c.syncCache(ctx); err != nil {
+		commonerr.SendToSentry(ctx, err, nil)
+		logger.Warnw("error while syncing categories cache after move", "err", err)
+	}
+
+	return category, nil
+}
+
+// GetCategoryProducts возвращает продукты категории, а при includeDescendants — и всех ее подкатегорий.
+func (c *CategoryUseCase) GetCategoryProducts(
+	ctx context.Context,
+	id types.Id,
+	includeDescendants bool,
+	limit uint,
+	offset uint,
+) ([]*entity.Product, error) {
+	ctx, _ = c.logger.FromContext(ctx, "categoryId", id)
+	ctx, span := tracing.Tracer.Start(ctx, "categoryUseCase.GetCategoryProducts")
+	defer span.End()
+
+	if _, err := c.GetCategory(ctx, id); err != nil {
+		return nil, err
+	}
+
+	categoryIds := []types.Id{id}
+
+	if includeDescendants {
+		categories, err := c.allCategories(ctx)
+		if err != nil {
+			return nil, err
+		}
+
+		for _, category := range categories {
+			if category.IsDescendantOf(id) {
+				categoryIds = append(categoryIds, category.Id)
+			}
+		}
+	}
+
+	return c.products.GetProducts(ctx, &entity.ProductFilter{CategoryIds: categoryIds}, limit, offset) //nolint: exhaustruct
+}
+
+func (c *CategoryUseCase) allCategories(ctx context.Context) ([]*entity.Category, error) {
+	var categories []*entity.Category
+
+	for offset := uint(0); ; offset += categoriesPageSize {
+		page, err := c.loader.GetList(ctx, categoriesPageSize, offset)
+		if err != nil {
+			return nil, errors.WithStack(err)
+		}
+
+		categories = append(categories, page...)
+
+		if len(page) < categoriesPageSize {
+			return categories, nil
+		}
+	}
+}
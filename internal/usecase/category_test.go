@@ -0,0 +1,270 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+	"github.com/qulaz/artforintrovert-test/internal/usecase/repo"
+	"github.com/qulaz/artforintrovert-test/pkg/cache"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+func newCategoryUseCase(t *testing.T) (
+	*CategoryUseCase,
+	*repo.MockCategoryRepository,
+	*MockProduct,
+	*cache.MockEntityCache[*entity.Category],
+	func(),
+) {
+	t.Helper()
+
+	ctrl := gomock.NewController(t)
+	mockRepo := repo.NewMockCategoryRepository(ctrl)
+	mockProducts := NewMockProduct(ctrl)
+	mockCache := cache.NewMockEntityCache[*entity.Category](ctrl)
+
+	uc := NewCategoryUseCase(mockRepo, mockProducts, mockCache, logging.NewDummyLogger())
+
+	return uc, mockRepo, mockProducts, mockCache, ctrl.Finish
+}
+
+func newTestCategory(parent *entity.Category) *entity.Category {
+	category := &entity.Category{ //nolint: exhaustruct
+		Id:          types.NewId(),
+		Name:        "category",
+		AncestorIds: []types.Id{},
+	}
+
+	if parent != nil {
+		category.ParentId = parent.Id
+		category.AncestorIds = parent.PathIds()
+	}
+
+	return category
+}
+
+func TestCategoryUseCase_CreateCategory(t *testing.T) {
+	t.Run("root", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, mockCache, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		mockRepo.EXPECT().CreateCategory(gomock.Any(), gomock.Any()).Return(nil)
+		mockCache.EXPECT().Set(gomock.Any()).Return(nil)
+
+		category, err := uc.CreateCategory(context.Background(), &entity.Category{Name: "root"}) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.False(t, category.Id.IsZero())
+		assert.True(t, category.IsRoot())
+		assert.Empty(t, category.AncestorIds)
+	})
+	t.Run("with parent", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, mockCache, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		root := newTestCategory(nil)
+		parent := newTestCategory(root)
+
+		mockRepo.EXPECT().GetCategory(gomock.Any(), parent.Id).Return(parent, nil)
+		mockRepo.EXPECT().CreateCategory(gomock.Any(), gomock.Any()).Return(nil)
+		mockCache.EXPECT().Set(gomock.Any()).Return(nil)
+
+		category, err := uc.CreateCategory(
+			context.Background(),
+			&entity.Category{Name: "child", ParentId: parent.Id}, //nolint: exhaustruct
+		)
+		require.NoError(t, err)
+		assert.Equal(t, []types.Id{root.Id, parent.Id}, category.AncestorIds)
+	})
+	t.Run("parent not found", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, _, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		parentId := types.NewId()
+		mockRepo.EXPECT().GetCategory(gomock.Any(), parentId).Return(nil, commonerr.NewNotFoundError("not found"))
+
+		_, err := uc.CreateCategory(
+			context.Background(),
+			&entity.Category{Name: "child", ParentId: parentId}, //nolint: exhaustruct
+		)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeNotFound))
+	})
+	t.Run("invalid", func(t *testing.T) {
+		t.Parallel()
+		uc, _, _, _, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		_, err := uc.CreateCategory(context.Background(), &entity.Category{}) //nolint: exhaustruct
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeIncorrectInput))
+	})
+}
+
+func TestCategoryUseCase_MoveCategory(t *testing.T) {
+	root := newTestCategory(nil)
+	child := newTestCategory(root)
+	grandchild := newTestCategory(child)
+	other := newTestCategory(nil)
+
+	t.Run("into other subtree", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, mockCache, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		moved := *child
+		moved.ParentId = other.Id
+		moved.AncestorIds = other.PathIds()
+		categories := []*entity.Category{root, &moved, grandchild, other}
+
+		mockRepo.EXPECT().GetCategory(gomock.Any(), other.Id).Return(other, nil)
+		mockRepo.EXPECT().MoveCategory(gomock.Any(), child.Id, other.Id, other.PathIds()).Return(&moved, nil)
+		// после переноса кеш перечитывается целиком
+		mockRepo.EXPECT().GetCategories(gomock.Any()).Return(categories, nil)
+		mockCache.EXPECT().Replace(categories).Return(nil)
+
+		category, err := uc.MoveCategory(context.Background(), child.Id, other.Id)
+		require.NoError(t, err)
+		assert.Equal(t, &moved, category)
+	})
+	t.Run("sync error after move is not returned", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, _, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		mockRepo.EXPECT().MoveCategory(gomock.Any(), child.Id, types.Id{}, []types.Id{}).Return(child, nil)
+		mockRepo.EXPECT().GetCategories(gomock.Any()).Return(nil, errors.New(""))
+
+		_, err := uc.MoveCategory(context.Background(), child.Id, types.Id{})
+		require.NoError(t, err)
+	})
+	t.Run("into itself", func(t *testing.T) {
+		t.Parallel()
+		uc, _, _, _, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		_, err := uc.MoveCategory(context.Background(), child.Id, child.Id)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeIncorrectInput))
+	})
+	t.Run("into own subtree", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, _, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		mockRepo.EXPECT().GetCategory(gomock.Any(), grandchild.Id).Return(grandchild, nil)
+
+		_, err := uc.MoveCategory(context.Background(), root.Id, grandchild.Id)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeIncorrectInput))
+	})
+}
+
+func TestCategoryUseCase_GetCategoryTree(t *testing.T) {
+	root := newTestCategory(nil)
+	child := newTestCategory(root)
+	categories := []*entity.Category{root, child}
+
+	t.Run("whole tree", func(t *testing.T) {
+		t.Parallel()
+		uc, _, _, mockCache, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		mockCache.EXPECT().GetList(uint(categoriesPageSize), uint(0)).Return(categories, nil)
+
+		tree, err := uc.GetCategoryTree(context.Background(), types.Id{})
+		require.NoError(t, err)
+		require.Len(t, tree, 1)
+		assert.Equal(t, root, tree[0].Category)
+		require.Len(t, tree[0].Children, 1)
+		assert.Equal(t, child, tree[0].Children[0].Category)
+	})
+	t.Run("unknown root", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, mockCache, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		rootId := types.NewId()
+		mockCache.EXPECT().Get(rootId.Hex()).Return(nil, cache.ErrKeyNotFound)
+		mockRepo.EXPECT().GetCategory(gomock.Any(), rootId).Return(nil, commonerr.NewNotFoundError("not found"))
+
+		_, err := uc.GetCategoryTree(context.Background(), rootId)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeNotFound))
+	})
+}
+
+func TestCategoryUseCase_GetCategoryProducts(t *testing.T) {
+	root := newTestCategory(nil)
+	child := newTestCategory(root)
+	grandchild := newTestCategory(child)
+	other := newTestCategory(nil)
+	categories := []*entity.Category{root, child, grandchild, other}
+	products := []*entity.Product{newValidProduct()}
+
+	testCases := []struct {
+		name               string
+		includeDescendants bool
+		expectedIds        []types.Id
+	}{
+		{name: "only category", includeDescendants: false, expectedIds: []types.Id{child.Id}},
+		{name: "with descendants", includeDescendants: true, expectedIds: []types.Id{child.Id, grandchild.Id}},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			uc, _, mockProducts, mockCache, teardown := newCategoryUseCase(t)
+			defer teardown()
+
+			mockCache.EXPECT().Get(child.Hash()).Return(child, nil)
+			mockCache.EXPECT().GetList(uint(categoriesPageSize), uint(0)).Return(categories, nil).AnyTimes()
+			mockProducts.EXPECT().
+				GetProducts(gomock.Any(), &entity.ProductFilter{CategoryIds: tc.expectedIds}, uint(10), uint(0)). //nolint: exhaustruct
+				Return(products, nil)
+
+			res, err := uc.GetCategoryProducts(context.Background(), child.Id, tc.includeDescendants, 10, 0)
+			require.NoError(t, err)
+			assert.Equal(t, products, res)
+		})
+	}
+}
+
+func TestCategoryUseCase_DeleteCategory(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, mockCache, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		id := types.NewId()
+		mockRepo.EXPECT().DeleteCategory(gomock.Any(), id).Return(nil)
+		mockCache.EXPECT().Delete(id.Hex()).Return(nil)
+
+		require.NoError(t, uc.DeleteCategory(context.Background(), id))
+	})
+	t.Run("has subcategories", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, _, teardown := newCategoryUseCase(t)
+		defer teardown()
+
+		id := types.NewId()
+		mockRepo.EXPECT().
+			DeleteCategory(gomock.Any(), id).
+			Return(commonerr.NewFailedPreconditionError("category %s has subcategories", id.Hex()))
+
+		err := uc.DeleteCategory(context.Background(), id)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeFailedPrecondition))
+	})
+}
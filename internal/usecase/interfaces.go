@@ -2,6 +2,7 @@ package usecase
 
 import (
 	"context"
+	"time"
 
 	"github.com/qulaz/artforintrovert-test/internal/entity"
 	"github.com/qulaz/artforintrovert-test/internal/types"
@@ -9,15 +10,72 @@ import (
 
 //go:generate go run github.com/golang/mock/mockgen -source=interfaces.go -destination=product_mock.go -package=usecase
 type Product interface {
-	GetProducts(ctx context.Context, limit uint, offset uint) ([]*entity.Product, error)
+	GetProducts(ctx context.Context, filter *entity.ProductFilter, limit uint, offset uint) ([]*entity.Product, error)
+	GetProduct(ctx context.Context, id types.Id) (*entity.Product, error)
 	UpdateProduct(ctx context.Context, productId types.Id, updatedProduct *entity.Product) (*entity.Product, error)
 	DeleteProduct(ctx context.Context, id types.Id) error
+	RestoreProduct(ctx context.Context, id types.Id) (*entity.Product, error)
+	GetProductHistory(ctx context.Context, productId types.Id, limit uint, offset uint) ([]*entity.ProductChange, error)
+	AddVariant(ctx context.Context, productId types.Id, variant *entity.Variant) (*entity.Product, error)
+	UpdateVariant(ctx context.Context, productId types.Id, variant *entity.Variant) (*entity.Product, error)
+	DeleteVariant(ctx context.Context, productId types.Id, variantId types.Id) (*entity.Product, error)
+	// ReserveStock переводит quantity из доступного остатка в резерв
+	ReserveStock(ctx context.Context, productId types.Id, quantity int64) (*entity.Product, error)
+	// ReleaseStock возвращает quantity из резерва в доступный остаток
+	ReleaseStock(ctx context.Context, productId types.Id, quantity int64) (*entity.Product, error)
+	// AdjustStock изменяет доступный остаток на delta, например при поставке или инвентаризации
+	AdjustStock(ctx context.Context, productId types.Id, delta int64) (*entity.Product, error)
+}
+
+type Category interface {
+	GetCategory(ctx context.Context, id types.Id) (*entity.Category, error)
+	// GetCategoryTree возвращает все дерево, если rootId нулевой, иначе поддерево rootId
+	GetCategoryTree(ctx context.Context, rootId types.Id) ([]*entity.CategoryNode, error)
+	CreateCategory(ctx context.Context, category *entity.Category) (*entity.Category, error)
+	UpdateCategory(ctx context.Context, id types.Id, updatedCategory *entity.Category) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, id types.Id) error
+	// MoveCategory переносит категорию с поддеревом под parentId. Нулевой parentId переносит в корень
+	MoveCategory(ctx context.Context, id types.Id, parentId types.Id) (*entity.Category, error)
+	GetCategoryProducts(
+		ctx context.Context,
+		id types.Id,
+		includeDescendants bool,
+		limit uint,
+		offset uint,
+	) ([]*entity.Product, error)
+}
+
+type Admin interface {
+	ResyncProductsCache(ctx context.Context) error
+	PurgeDeletedProducts(ctx context.Context, olderThan time.Duration) (int64, error)
 }
 
 //go:generate go run github.com/golang/mock/mockgen -source=interfaces.go -destination=repo/products_mock.go -package=repo
 type Repository interface {
 	GetProducts(ctx context.Context) ([]*entity.Product, error)
+	GetProduct(ctx context.Context, id types.Id) (*entity.Product, error)
+	GetProductsByIds(ctx context.Context, ids []types.Id) ([]*entity.Product, error)
+	SampleProducts(ctx context.Context, size int) ([]*entity.Product, error)
 	UpdateProduct(ctx context.Context, productId types.Id, updatedProduct *entity.Product) (*entity.Product, error)
+	UpdateVariants(
+		ctx context.Context,
+		productId types.Id,
+		variants []entity.Variant,
+		expectedVersion int64,
+	) (*entity.Product, error)
+	ChangeStock(ctx context.Context, productId types.Id, stockDelta int64, reservedDelta int64) (*entity.Product, error)
 	DeleteProduct(ctx context.Context, id types.Id) error
+	RestoreProduct(ctx context.Context, id types.Id) (*entity.Product, error)
+	PurgeDeletedProducts(ctx context.Context, before time.Time) (int64, error)
+	GetProductHistory(ctx context.Context, productId types.Id, limit uint, offset uint) ([]*entity.ProductChange, error)
 	CreateProducts(ctx context.Context, product []*entity.Product) error
 }
+
+type CategoryRepository interface {
+	GetCategories(ctx context.Context) ([]*entity.Category, error)
+	GetCategory(ctx context.Context, id types.Id) (*entity.Category, error)
+	CreateCategory(ctx context.Context, category *entity.Category) error
+	UpdateCategory(ctx context.Context, id types.Id, updatedCategory *entity.Category) (*entity.Category, error)
+	DeleteCategory(ctx context.Context, id types.Id) error
+	MoveCategory(ctx context.Context, id types.Id, parentId types.Id, ancestorIds []types.Id) (*entity.Category, error)
+}
@@ -0,0 +1,29 @@
+package usecase
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var (
+	cacheLastSyncTimestamp = promauto.NewGauge(prometheus.GaugeOpts{ //nolint: exhaustruct
+		Name: "products_cache_last_sync_timestamp_seconds",
+		Help: "Unix time of the last successful products cache sync.",
+	})
+	cacheStaleness = promauto.NewGauge(prometheus.GaugeOpts{ //nolint: exhaustruct
+		Name: "products_cache_staleness_seconds",
+		Help: "Seconds since the last successful products cache sync.",
+	})
+	cacheSyncFailures = promauto.NewCounter(prometheus.CounterOpts{ //nolint: exhaustruct
+		Name: "products_cache_sync_failures_total",
+		Help: "Total number of failed products cache syncs.",
+	})
+	cacheDriftEntries = promauto.NewGaugeVec(prometheus.GaugeOpts{ //nolint: exhaustruct
+		Name: "products_cache_drift_entries",
+		Help: "Number of products that differ between cache and database in the last verification.",
+	}, []string{"kind"})
+	cacheVerifications = promauto.NewCounterVec(prometheus.CounterOpts{ //nolint: exhaustruct
+		Name: "products_cache_verifications_total",
+		Help: "Total number of products cache verifications by result.",
+	}, []string{"result"})
+)
@@ -11,6 +11,7 @@ import (
 	"github.com/qulaz/artforintrovert-test/internal/tracing"
 	"github.com/qulaz/artforintrovert-test/internal/types"
 	"github.com/qulaz/artforintrovert-test/pkg/cache"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/actor"
 	"github.com/qulaz/artforintrovert-test/pkg/logging"
 )
 
@@ -18,39 +19,101 @@ const (
 	defaultLimit = 100
 )
 
-var _ Product = (*ProductUseCase)(nil)
+var (
+	_ Product = (*ProductUseCase)(nil)
+	_ Admin   = (*ProductUseCase)(nil)
+)
 
 type ProductUseCase struct {
-	repo     Repository
-	logger   logging.ContextLogger
-	cache    cache.EntityCache[*entity.Product]
-	cacheTtl time.Duration
+	repo       Repository
+	logger     logging.ContextLogger
+	cache      cache.EntityCache[*entity.Product]
+	loader     *cache.Loader[*entity.Product]
+	syncConfig CacheSyncConfig
+	syncState  *cacheSyncState
+	// validationRules правила витрины, проверяемые при сохранении продукта
+	validationRules *entity.ValidationRules
 }
 
+// NewProductUseCase создает сценарии работы с продуктами. invalidationBus канал инвалидации между репликами,
+// nil, если кеш не общий.
 func NewProductUseCase(
 	repo Repository,
-	cache cache.EntityCache[*entity.Product],
+	productCache cache.EntityCache[*entity.Product],
+	invalidationBus cache.InvalidationBus,
 	logger logging.ContextLogger,
-	cacheTtl time.Duration,
+	syncConfig CacheSyncConfig,
+	negativeCacheTtl time.Duration,
+	validationRules *entity.ValidationRules,
 ) *ProductUseCase {
 	return &ProductUseCase{
-		repo:     repo,
-		logger:   logger,
-		cache:    cache,
-		cacheTtl: cacheTtl,
+		repo:            repo,
+		logger:          logger,
+		cache:           productCache,
+		loader:          newProductLoader(repo, productCache, invalidationBus, negativeCacheTtl, syncConfig.Timeout),
+		syncConfig:      syncConfig,
+		syncState:       newCacheSyncState(),
+		validationRules: validationRules,
 	}
 }
 
-func (p *ProductUseCase) GetProducts(ctx context.Context, limit uint, offset uint) ([]*entity.Product, error) {
+// newProductLoader при промахе кеша подгружает продукты из репозитория. Загрузка ограничена loadTimeout,
+// как и синхронизация кеша.
+func newProductLoader(
+	repo Repository,
+	productCache cache.EntityCache[*entity.Product],
+	invalidationBus cache.InvalidationBus,
+	negativeCacheTtl time.Duration,
+	loadTimeout time.Duration,
+) *cache.Loader[*entity.Product] {
+	return cache.NewLoader[*entity.Product](
+		productCache,
+		func(ctx context.Context, key string) (*entity.Product, error) {
+			id, err := types.NewIdFromString(key)
+			if err != nil {
+				return nil, err
+			}
+
+			return repo.GetProduct(ctx, id)
+		},
+		repo.GetProducts,
+		cache.LoaderOptions{
+			NegativeTtl:        negativeCacheTtl,
+			MaxNegativeEntries: 0,
+			LoadTimeout:        loadTimeout,
+			IsNotFound: func(err error) bool {
+				return commonerr.IsErrorType(err, commonerr.ErrorTypeNotFound)
+			},
+			Bus: invalidationBus,
+		},
+	)
+}
+
+func (p *ProductUseCase) GetProducts(
+	ctx context.Context,
+	filter *entity.ProductFilter,
+	limit uint,
+	offset uint,
+) ([]*entity.Product, error) {
 	ctx, _ = p.logger.FromContext(ctx)
-	_, span := tracing.Tracer.Start(ctx, "productUseCase.GetProducts")
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.GetProducts")
 	defer span.End()
 
 	if limit == 0 {
 		limit = defaultLimit
 	}
 
-	cachedProducts, err := p.cache.GetList(limit, offset)
+	var (
+		cachedProducts []*entity.Product
+		err            error
+	)
+
+	if filter.IsEmpty() {
+		cachedProducts, err = p.loader.GetList(ctx, limit, offset)
+	} else {
+		cachedProducts, err = p.loader.Find(ctx, filter.Match, limit, offset)
+	}
+
 	if err != nil {
 		return nil, errors.WithStack(err)
 	}
@@ -58,6 +121,19 @@ func (p *ProductUseCase) GetProducts(ctx context.Context, limit uint, offset uin
 	return cachedProducts, nil
 }
 
+func (p *ProductUseCase) GetProduct(ctx context.Context, id types.Id) (*entity.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx, "productId", id)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.GetProduct")
+	defer span.End()
+
+	product, err := p.loader.Get(ctx, id.Hex())
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	return product, nil
+}
+
 func (p *ProductUseCase) UpdateProduct(
 	ctx context.Context,
 	productId types.Id,
@@ -67,10 +143,12 @@ func (p *ProductUseCase) UpdateProduct(
 	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.UpdateProduct")
 	defer span.End()
 
-	if err := updatedProduct.Validate(); err != nil {
+	if err := updatedProduct.ValidateWith(p.validationRules); err != nil {
 		return nil, err
 	}
 
+	updatedProduct.UpdatedBy = actor.FromContext(ctx)
+
 	product, err := p.repo.UpdateProduct(ctx, productId, updatedProduct)
 	if err != nil {
 		return nil, errors.WithStack(err)
@@ -98,7 +176,7 @@ func (p *ProductUseCase) DeleteProduct(ctx context.Context, id types.Id) error {
 		return errors.WithStack(err)
 	}
 
-	if err := p.cache.Delete(id.Hex()); err != nil {
+	if err := p.loader.Delete(id.Hex()); err != nil {
 		commonerr.SendToSentry(ctx, errors.WithStack(err), &commonerr.SentryInfo{
 			Contexts: map[string]interface{}{"productId": id},
 		})
@@ -108,37 +186,69 @@ func (p *ProductUseCase) DeleteProduct(ctx context.Context, id types.Id) error {
 	return nil
 }
 
-func (p *ProductUseCase) SyncCache(ctx context.Context) {
-	for {
-		select {
-		case <-ctx.Done():
-			return
-		default:
-			p.syncCache(ctx)
-			time.Sleep(p.cacheTtl)
-		}
+// RestoreProduct отменяет мягкое удаление продукта и возвращает его в кеш.
+func (p *ProductUseCase) RestoreProduct(ctx context.Context, id types.Id) (*entity.Product, error) {
+	ctx, logger := p.logger.FromContext(ctx, "productId", id)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.RestoreProduct")
+	defer span.End()
+
+	product, err := p.repo.RestoreProduct(ctx, id)
+	if err != nil {
+		return nil, errors.WithStack(err)
 	}
+
+	if err := p.loader.Forget(id.Hex()); err != nil {
+		logger.Warnw("error while notifying replicas about restored product", "err", err)
+	}
+
+	if err := p.cache.Set(product); err != nil {
+		commonerr.SendToSentry(ctx, errors.WithStack(err), &commonerr.SentryInfo{
+			Contexts: map[string]interface{}{"productId": id},
+		})
+		logger.Warnw("error while restoring product in cache", "err", err)
+	}
+
+	return product, nil
 }
 
-func (p *ProductUseCase) syncCache(ctx context.Context) {
-	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.syncCache")
+func (p *ProductUseCase) GetProductHistory(
+	ctx context.Context,
+	productId types.Id,
+	limit uint,
+	offset uint,
+) ([]*entity.ProductChange, error) {
+	ctx, _ = p.logger.FromContext(ctx, "productId", productId)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.GetProductHistory")
 	defer span.End()
 
-	p.logger.Infow("Start syncing cache")
+	if limit == 0 {
+		limit = defaultLimit
+	}
 
-	l, err := p.repo.GetProducts(ctx)
+	changes, err := p.repo.GetProductHistory(ctx, productId, limit, offset)
 	if err != nil {
-		commonerr.SendToSentry(ctx, errors.WithStack(err), nil)
-		p.logger.Errorw("can't sync cache", "err", err)
-		return
+		return nil, errors.WithStack(err)
 	}
 
-	err = p.cache.Replace(l)
+	return changes, nil
+}
+
+// PurgeDeletedProducts окончательно удаляет продукты, мягко удаленные больше olderThan назад, вместе с их историей.
+func (p *ProductUseCase) PurgeDeletedProducts(ctx context.Context, olderThan time.Duration) (int64, error) {
+	ctx, logger := p.logger.FromContext(ctx, "olderThan", olderThan)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.PurgeDeletedProducts")
+	defer span.End()
+
+	if olderThan <= 0 {
+		return 0, commonerr.NewIncorrectInputError("retention period must be greater than 0")
+	}
+
+	purged, err := p.repo.PurgeDeletedProducts(ctx, time.Now().Add(-olderThan))
 	if err != nil {
-		commonerr.SendToSentry(ctx, errors.WithStack(err), nil)
-		p.logger.Errorw("can't set batch in cache", "err", err)
-		return
+		return 0, errors.WithStack(err)
 	}
 
-	p.logger.Infow("Cache synced with database")
+	logger.Infow("Deleted products purged", "count", purged)
+
+	return purged, nil
 }
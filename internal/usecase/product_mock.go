@@ -7,6 +7,7 @@ package usecase
 import (
 	context "context"
 	reflect "reflect"
+	time "time"
 
 	gomock "github.com/golang/mock/gomock"
 	entity "github.com/qulaz/artforintrovert-test/internal/entity"
@@ -36,6 +37,36 @@ func (m *MockProduct) EXPECT() *MockProductMockRecorder {
 	return m.recorder
 }
 
+// AddVariant mocks base method.
+func (m *MockProduct) AddVariant(ctx context.Context, productId types.Id, variant *entity.Variant) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AddVariant", ctx, productId, variant)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AddVariant indicates an expected call of AddVariant.
+func (mr *MockProductMockRecorder) AddVariant(ctx, productId, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AddVariant", reflect.TypeOf((*MockProduct)(nil).AddVariant), ctx, productId, variant)
+}
+
+// AdjustStock mocks base method.
+func (m *MockProduct) AdjustStock(ctx context.Context, productId types.Id, delta int64) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "AdjustStock", ctx, productId, delta)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// AdjustStock indicates an expected call of AdjustStock.
+func (mr *MockProductMockRecorder) AdjustStock(ctx, productId, delta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "AdjustStock", reflect.TypeOf((*MockProduct)(nil).AdjustStock), ctx, productId, delta)
+}
+
 // DeleteProduct mocks base method.
 func (m *MockProduct) DeleteProduct(ctx context.Context, id types.Id) error {
 	m.ctrl.T.Helper()
@@ -50,19 +81,109 @@ func (mr *MockProductMockRecorder) DeleteProduct(ctx, id interface{}) *gomock.Ca
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockProduct)(nil).DeleteProduct), ctx, id)
 }
 
+// DeleteVariant mocks base method.
+func (m *MockProduct) DeleteVariant(ctx context.Context, productId, variantId types.Id) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteVariant", ctx, productId, variantId)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// DeleteVariant indicates an expected call of DeleteVariant.
+func (mr *MockProductMockRecorder) DeleteVariant(ctx, productId, variantId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteVariant", reflect.TypeOf((*MockProduct)(nil).DeleteVariant), ctx, productId, variantId)
+}
+
+// GetProduct mocks base method.
+func (m *MockProduct) GetProduct(ctx context.Context, id types.Id) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProduct", ctx, id)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProduct indicates an expected call of GetProduct.
+func (mr *MockProductMockRecorder) GetProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockProduct)(nil).GetProduct), ctx, id)
+}
+
+// GetProductHistory mocks base method.
+func (m *MockProduct) GetProductHistory(ctx context.Context, productId types.Id, limit, offset uint) ([]*entity.ProductChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductHistory", ctx, productId, limit, offset)
+	ret0, _ := ret[0].([]*entity.ProductChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductHistory indicates an expected call of GetProductHistory.
+func (mr *MockProductMockRecorder) GetProductHistory(ctx, productId, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductHistory", reflect.TypeOf((*MockProduct)(nil).GetProductHistory), ctx, productId, limit, offset)
+}
+
 // GetProducts mocks base method.
-func (m *MockProduct) GetProducts(ctx context.Context, limit, offset uint) ([]*entity.Product, error) {
+func (m *MockProduct) GetProducts(ctx context.Context, filter *entity.ProductFilter, limit, offset uint) ([]*entity.Product, error) {
 	m.ctrl.T.Helper()
-	ret := m.ctrl.Call(m, "GetProducts", ctx, limit, offset)
+	ret := m.ctrl.Call(m, "GetProducts", ctx, filter, limit, offset)
 	ret0, _ := ret[0].([]*entity.Product)
 	ret1, _ := ret[1].(error)
 	return ret0, ret1
 }
 
 // GetProducts indicates an expected call of GetProducts.
-func (mr *MockProductMockRecorder) GetProducts(ctx, limit, offset interface{}) *gomock.Call {
+func (mr *MockProductMockRecorder) GetProducts(ctx, filter, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockProduct)(nil).GetProducts), ctx, filter, limit, offset)
+}
+
+// ReleaseStock mocks base method.
+func (m *MockProduct) ReleaseStock(ctx context.Context, productId types.Id, quantity int64) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReleaseStock", ctx, productId, quantity)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReleaseStock indicates an expected call of ReleaseStock.
+func (mr *MockProductMockRecorder) ReleaseStock(ctx, productId, quantity interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReleaseStock", reflect.TypeOf((*MockProduct)(nil).ReleaseStock), ctx, productId, quantity)
+}
+
+// ReserveStock mocks base method.
+func (m *MockProduct) ReserveStock(ctx context.Context, productId types.Id, quantity int64) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ReserveStock", ctx, productId, quantity)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ReserveStock indicates an expected call of ReserveStock.
+func (mr *MockProductMockRecorder) ReserveStock(ctx, productId, quantity interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
-	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockProduct)(nil).GetProducts), ctx, limit, offset)
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ReserveStock", reflect.TypeOf((*MockProduct)(nil).ReserveStock), ctx, productId, quantity)
+}
+
+// RestoreProduct mocks base method.
+func (m *MockProduct) RestoreProduct(ctx context.Context, id types.Id) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreProduct", ctx, id)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreProduct indicates an expected call of RestoreProduct.
+func (mr *MockProductMockRecorder) RestoreProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreProduct", reflect.TypeOf((*MockProduct)(nil).RestoreProduct), ctx, id)
 }
 
 // UpdateProduct mocks base method.
@@ -80,6 +201,200 @@ func (mr *MockProductMockRecorder) UpdateProduct(ctx, productId, updatedProduct
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockProduct)(nil).UpdateProduct), ctx, productId, updatedProduct)
 }
 
+// UpdateVariant mocks base method.
+func (m *MockProduct) UpdateVariant(ctx context.Context, productId types.Id, variant *entity.Variant) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariant", ctx, productId, variant)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVariant indicates an expected call of UpdateVariant.
+func (mr *MockProductMockRecorder) UpdateVariant(ctx, productId, variant interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariant", reflect.TypeOf((*MockProduct)(nil).UpdateVariant), ctx, productId, variant)
+}
+
+// MockCategory is a mock of Category interface.
+type MockCategory struct {
+	ctrl     *gomock.Controller
+	recorder *MockCategoryMockRecorder
+}
+
+// MockCategoryMockRecorder is the mock recorder for MockCategory.
+type MockCategoryMockRecorder struct {
+	mock *MockCategory
+}
+
+// NewMockCategory creates a new mock instance.
+func NewMockCategory(ctrl *gomock.Controller) *MockCategory {
+	mock := &MockCategory{ctrl: ctrl}
+	mock.recorder = &MockCategoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCategory) EXPECT() *MockCategoryMockRecorder {
+	return m.recorder
+}
+
+// CreateCategory mocks base method.
+func (m *MockCategory) CreateCategory(ctx context.Context, category *entity.Category) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", ctx, category)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockCategoryMockRecorder) CreateCategory(ctx, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockCategory)(nil).CreateCategory), ctx, category)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockCategory) DeleteCategory(ctx context.Context, id types.Id) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockCategoryMockRecorder) DeleteCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockCategory)(nil).DeleteCategory), ctx, id)
+}
+
+// GetCategory mocks base method.
+func (m *MockCategory) GetCategory(ctx context.Context, id types.Id) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", ctx, id)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockCategoryMockRecorder) GetCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockCategory)(nil).GetCategory), ctx, id)
+}
+
+// GetCategoryProducts mocks base method.
+func (m *MockCategory) GetCategoryProducts(ctx context.Context, id types.Id, includeDescendants bool, limit, offset uint) ([]*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryProducts", ctx, id, includeDescendants, limit, offset)
+	ret0, _ := ret[0].([]*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryProducts indicates an expected call of GetCategoryProducts.
+func (mr *MockCategoryMockRecorder) GetCategoryProducts(ctx, id, includeDescendants, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryProducts", reflect.TypeOf((*MockCategory)(nil).GetCategoryProducts), ctx, id, includeDescendants, limit, offset)
+}
+
+// GetCategoryTree mocks base method.
+func (m *MockCategory) GetCategoryTree(ctx context.Context, rootId types.Id) ([]*entity.CategoryNode, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategoryTree", ctx, rootId)
+	ret0, _ := ret[0].([]*entity.CategoryNode)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategoryTree indicates an expected call of GetCategoryTree.
+func (mr *MockCategoryMockRecorder) GetCategoryTree(ctx, rootId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategoryTree", reflect.TypeOf((*MockCategory)(nil).GetCategoryTree), ctx, rootId)
+}
+
+// MoveCategory mocks base method.
+func (m *MockCategory) MoveCategory(ctx context.Context, id, parentId types.Id) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveCategory", ctx, id, parentId)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveCategory indicates an expected call of MoveCategory.
+func (mr *MockCategoryMockRecorder) MoveCategory(ctx, id, parentId interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveCategory", reflect.TypeOf((*MockCategory)(nil).MoveCategory), ctx, id, parentId)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockCategory) UpdateCategory(ctx context.Context, id types.Id, updatedCategory *entity.Category) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", ctx, id, updatedCategory)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockCategoryMockRecorder) UpdateCategory(ctx, id, updatedCategory interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockCategory)(nil).UpdateCategory), ctx, id, updatedCategory)
+}
+
+// MockAdmin is a mock of Admin interface.
+type MockAdmin struct {
+	ctrl     *gomock.Controller
+	recorder *MockAdminMockRecorder
+}
+
+// MockAdminMockRecorder is the mock recorder for MockAdmin.
+type MockAdminMockRecorder struct {
+	mock *MockAdmin
+}
+
+// NewMockAdmin creates a new mock instance.
+func NewMockAdmin(ctrl *gomock.Controller) *MockAdmin {
+	mock := &MockAdmin{ctrl: ctrl}
+	mock.recorder = &MockAdminMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockAdmin) EXPECT() *MockAdminMockRecorder {
+	return m.recorder
+}
+
+// PurgeDeletedProducts mocks base method.
+func (m *MockAdmin) PurgeDeletedProducts(ctx context.Context, olderThan time.Duration) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedProducts", ctx, olderThan)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedProducts indicates an expected call of PurgeDeletedProducts.
+func (mr *MockAdminMockRecorder) PurgeDeletedProducts(ctx, olderThan interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedProducts", reflect.TypeOf((*MockAdmin)(nil).PurgeDeletedProducts), ctx, olderThan)
+}
+
+// ResyncProductsCache mocks base method.
+func (m *MockAdmin) ResyncProductsCache(ctx context.Context) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ResyncProductsCache", ctx)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// ResyncProductsCache indicates an expected call of ResyncProductsCache.
+func (mr *MockAdminMockRecorder) ResyncProductsCache(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ResyncProductsCache", reflect.TypeOf((*MockAdmin)(nil).ResyncProductsCache), ctx)
+}
+
 // MockRepository is a mock of Repository interface.
 type MockRepository struct {
 	ctrl     *gomock.Controller
@@ -103,6 +418,21 @@ func (m *MockRepository) EXPECT() *MockRepositoryMockRecorder {
 	return m.recorder
 }
 
+// ChangeStock mocks base method.
+func (m *MockRepository) ChangeStock(ctx context.Context, productId types.Id, stockDelta, reservedDelta int64) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "ChangeStock", ctx, productId, stockDelta, reservedDelta)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// ChangeStock indicates an expected call of ChangeStock.
+func (mr *MockRepositoryMockRecorder) ChangeStock(ctx, productId, stockDelta, reservedDelta interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "ChangeStock", reflect.TypeOf((*MockRepository)(nil).ChangeStock), ctx, productId, stockDelta, reservedDelta)
+}
+
 // CreateProducts mocks base method.
 func (m *MockRepository) CreateProducts(ctx context.Context, product []*entity.Product) error {
 	m.ctrl.T.Helper()
@@ -131,6 +461,36 @@ func (mr *MockRepositoryMockRecorder) DeleteProduct(ctx, id interface{}) *gomock
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteProduct", reflect.TypeOf((*MockRepository)(nil).DeleteProduct), ctx, id)
 }
 
+// GetProduct mocks base method.
+func (m *MockRepository) GetProduct(ctx context.Context, id types.Id) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProduct", ctx, id)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProduct indicates an expected call of GetProduct.
+func (mr *MockRepositoryMockRecorder) GetProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProduct", reflect.TypeOf((*MockRepository)(nil).GetProduct), ctx, id)
+}
+
+// GetProductHistory mocks base method.
+func (m *MockRepository) GetProductHistory(ctx context.Context, productId types.Id, limit, offset uint) ([]*entity.ProductChange, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductHistory", ctx, productId, limit, offset)
+	ret0, _ := ret[0].([]*entity.ProductChange)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductHistory indicates an expected call of GetProductHistory.
+func (mr *MockRepositoryMockRecorder) GetProductHistory(ctx, productId, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductHistory", reflect.TypeOf((*MockRepository)(nil).GetProductHistory), ctx, productId, limit, offset)
+}
+
 // GetProducts mocks base method.
 func (m *MockRepository) GetProducts(ctx context.Context) ([]*entity.Product, error) {
 	m.ctrl.T.Helper()
@@ -146,6 +506,66 @@ func (mr *MockRepositoryMockRecorder) GetProducts(ctx interface{}) *gomock.Call
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProducts", reflect.TypeOf((*MockRepository)(nil).GetProducts), ctx)
 }
 
+// GetProductsByIds mocks base method.
+func (m *MockRepository) GetProductsByIds(ctx context.Context, ids []types.Id) ([]*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetProductsByIds", ctx, ids)
+	ret0, _ := ret[0].([]*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetProductsByIds indicates an expected call of GetProductsByIds.
+func (mr *MockRepositoryMockRecorder) GetProductsByIds(ctx, ids interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetProductsByIds", reflect.TypeOf((*MockRepository)(nil).GetProductsByIds), ctx, ids)
+}
+
+// PurgeDeletedProducts mocks base method.
+func (m *MockRepository) PurgeDeletedProducts(ctx context.Context, before time.Time) (int64, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "PurgeDeletedProducts", ctx, before)
+	ret0, _ := ret[0].(int64)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// PurgeDeletedProducts indicates an expected call of PurgeDeletedProducts.
+func (mr *MockRepositoryMockRecorder) PurgeDeletedProducts(ctx, before interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "PurgeDeletedProducts", reflect.TypeOf((*MockRepository)(nil).PurgeDeletedProducts), ctx, before)
+}
+
+// RestoreProduct mocks base method.
+func (m *MockRepository) RestoreProduct(ctx context.Context, id types.Id) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "RestoreProduct", ctx, id)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// RestoreProduct indicates an expected call of RestoreProduct.
+func (mr *MockRepositoryMockRecorder) RestoreProduct(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "RestoreProduct", reflect.TypeOf((*MockRepository)(nil).RestoreProduct), ctx, id)
+}
+
+// SampleProducts mocks base method.
+func (m *MockRepository) SampleProducts(ctx context.Context, size int) ([]*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "SampleProducts", ctx, size)
+	ret0, _ := ret[0].([]*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// SampleProducts indicates an expected call of SampleProducts.
+func (mr *MockRepositoryMockRecorder) SampleProducts(ctx, size interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "SampleProducts", reflect.TypeOf((*MockRepository)(nil).SampleProducts), ctx, size)
+}
+
 // UpdateProduct mocks base method.
 func (m *MockRepository) UpdateProduct(ctx context.Context, productId types.Id, updatedProduct *entity.Product) (*entity.Product, error) {
 	m.ctrl.T.Helper()
@@ -160,3 +580,129 @@ func (mr *MockRepositoryMockRecorder) UpdateProduct(ctx, productId, updatedProdu
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateProduct", reflect.TypeOf((*MockRepository)(nil).UpdateProduct), ctx, productId, updatedProduct)
 }
+
+// UpdateVariants mocks base method.
+func (m *MockRepository) UpdateVariants(ctx context.Context, productId types.Id, variants []entity.Variant, expectedVersion int64) (*entity.Product, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateVariants", ctx, productId, variants, expectedVersion)
+	ret0, _ := ret[0].(*entity.Product)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateVariants indicates an expected call of UpdateVariants.
+func (mr *MockRepositoryMockRecorder) UpdateVariants(ctx, productId, variants, expectedVersion interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateVariants", reflect.TypeOf((*MockRepository)(nil).UpdateVariants), ctx, productId, variants, expectedVersion)
+}
+
+// MockCategoryRepository is a mock of CategoryRepository interface.
+type MockCategoryRepository struct {
+	ctrl     *gomock.Controller
+	recorder *MockCategoryRepositoryMockRecorder
+}
+
+// MockCategoryRepositoryMockRecorder is the mock recorder for MockCategoryRepository.
+type MockCategoryRepositoryMockRecorder struct {
+	mock *MockCategoryRepository
+}
+
+// NewMockCategoryRepository creates a new mock instance.
+func NewMockCategoryRepository(ctrl *gomock.Controller) *MockCategoryRepository {
+	mock := &MockCategoryRepository{ctrl: ctrl}
+	mock.recorder = &MockCategoryRepositoryMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockCategoryRepository) EXPECT() *MockCategoryRepositoryMockRecorder {
+	return m.recorder
+}
+
+// CreateCategory mocks base method.
+func (m *MockCategoryRepository) CreateCategory(ctx context.Context, category *entity.Category) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "CreateCategory", ctx, category)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// CreateCategory indicates an expected call of CreateCategory.
+func (mr *MockCategoryRepositoryMockRecorder) CreateCategory(ctx, category interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "CreateCategory", reflect.TypeOf((*MockCategoryRepository)(nil).CreateCategory), ctx, category)
+}
+
+// DeleteCategory mocks base method.
+func (m *MockCategoryRepository) DeleteCategory(ctx context.Context, id types.Id) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "DeleteCategory", ctx, id)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// DeleteCategory indicates an expected call of DeleteCategory.
+func (mr *MockCategoryRepositoryMockRecorder) DeleteCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "DeleteCategory", reflect.TypeOf((*MockCategoryRepository)(nil).DeleteCategory), ctx, id)
+}
+
+// GetCategories mocks base method.
+func (m *MockCategoryRepository) GetCategories(ctx context.Context) ([]*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategories", ctx)
+	ret0, _ := ret[0].([]*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategories indicates an expected call of GetCategories.
+func (mr *MockCategoryRepositoryMockRecorder) GetCategories(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategories", reflect.TypeOf((*MockCategoryRepository)(nil).GetCategories), ctx)
+}
+
+// GetCategory mocks base method.
+func (m *MockCategoryRepository) GetCategory(ctx context.Context, id types.Id) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "GetCategory", ctx, id)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// GetCategory indicates an expected call of GetCategory.
+func (mr *MockCategoryRepositoryMockRecorder) GetCategory(ctx, id interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "GetCategory", reflect.TypeOf((*MockCategoryRepository)(nil).GetCategory), ctx, id)
+}
+
+// MoveCategory mocks base method.
+func (m *MockCategoryRepository) MoveCategory(ctx context.Context, id, parentId types.Id, ancestorIds []types.Id) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "MoveCategory", ctx, id, parentId, ancestorIds)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// MoveCategory indicates an expected call of MoveCategory.
+func (mr *MockCategoryRepositoryMockRecorder) MoveCategory(ctx, id, parentId, ancestorIds interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "MoveCategory", reflect.TypeOf((*MockCategoryRepository)(nil).MoveCategory), ctx, id, parentId, ancestorIds)
+}
+
+// UpdateCategory mocks base method.
+func (m *MockCategoryRepository) UpdateCategory(ctx context.Context, id types.Id, updatedCategory *entity.Category) (*entity.Category, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "UpdateCategory", ctx, id, updatedCategory)
+	ret0, _ := ret[0].(*entity.Category)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// UpdateCategory indicates an expected call of UpdateCategory.
+func (mr *MockCategoryRepositoryMockRecorder) UpdateCategory(ctx, id, updatedCategory interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "UpdateCategory", reflect.TypeOf((*MockCategoryRepository)(nil).UpdateCategory), ctx, id, updatedCategory)
+}
@@ -5,6 +5,7 @@ import (
 	"errors"
 	"math"
 	"testing"
+	"time"
 
 	"github.com/brianvoe/gofakeit/v6"
 	"github.com/golang/mock/gomock"
@@ -36,35 +37,99 @@ func newProductUseCase(t *testing.T) (
 	}
 
 	return &ProductUseCase{
-		repo:     mockRepo,
-		logger:   logging.NewDummyLogger(),
-		cache:    mockCache,
-		cacheTtl: 10,
+		repo:   mockRepo,
+		logger: logging.NewDummyLogger(),
+		cache:  mockCache,
+		loader: newProductLoader(mockRepo, mockCache, nil, time.Minute, time.Minute),
+		syncConfig: CacheSyncConfig{
+			Interval:     time.Hour,
+			MinBackoff:   time.Millisecond,
+			MaxBackoff:   time.Millisecond * 10,
+			Jitter:       0,
+			MaxStaleness: 0,
+			Timeout:      time.Second,
+		},
+		syncState: newCacheSyncState(),
 	}, mockRepo, mockCache, teardown
 }
 
 func TestProductUseCase_GetProducts(t *testing.T) {
+	products := []*entity.Product{newValidProduct(), newValidProduct()}
+
 	t.Run("success", func(t *testing.T) {
 		t.Parallel()
 		uc, _, mockCache, teardown := newProductUseCase(t)
 		defer teardown()
 
-		mockCache.EXPECT().GetList(uint(100), uint(0)).Return([]*entity.Product{}, nil)
+		mockCache.EXPECT().GetList(uint(100), uint(0)).Return(products, nil)
 
-		products, err := uc.GetProducts(context.Background(), 100, 0)
+		res, err := uc.GetProducts(context.Background(), nil, 100, 0)
 		require.NoError(t, err)
-		assert.Equal(t, []*entity.Product{}, products)
+		assert.Equal(t, products, res)
 	})
 	t.Run("zero limit", func(t *testing.T) {
 		t.Parallel()
 		uc, _, mockCache, teardown := newProductUseCase(t)
 		defer teardown()
 
-		mockCache.EXPECT().GetList(uint(defaultLimit), uint(0)).Return([]*entity.Product{}, nil)
+		mockCache.EXPECT().GetList(uint(defaultLimit), uint(0)).Return(products, nil)
+
+		res, err := uc.GetProducts(context.Background(), nil, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, products, res)
+	})
+	t.Run("offset out of range", func(t *testing.T) {
+		t.Parallel()
+		uc, _, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		mockCache.EXPECT().GetList(uint(100), uint(100)).Return([]*entity.Product{}, nil)
 
-		products, err := uc.GetProducts(context.Background(), 0, 0)
+		res, err := uc.GetProducts(context.Background(), nil, 100, 100)
 		require.NoError(t, err)
-		assert.Equal(t, []*entity.Product{}, products)
+		assert.Equal(t, []*entity.Product{}, res)
+	})
+	t.Run("filtered", func(t *testing.T) {
+		t.Parallel()
+		uc, _, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		filter := &entity.ProductFilter{UpdatedSince: time.Now()}
+
+		mockCache.EXPECT().GetList(uint(1), uint(0)).Return(products[:1], nil)
+		mockCache.EXPECT().Find(gomock.Any(), uint(100), uint(0)).Return(products[:1], nil)
+
+		res, err := uc.GetProducts(context.Background(), filter, 100, 0)
+		require.NoError(t, err)
+		assert.Equal(t, products[:1], res)
+	})
+	t.Run("empty cache is loaded from repo", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		gomock.InOrder(
+			mockCache.EXPECT().GetList(uint(100), uint(0)).Return([]*entity.Product{}, nil),
+			mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil),
+			mockCache.EXPECT().Replace(products).Return(nil),
+			mockCache.EXPECT().GetList(uint(100), uint(0)).Return(products, nil),
+		)
+
+		res, err := uc.GetProducts(context.Background(), nil, 100, 0)
+		require.NoError(t, err)
+		assert.Equal(t, products, res)
+	})
+	t.Run("empty cache and repo error", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		mockCache.EXPECT().GetList(uint(100), uint(0)).Return([]*entity.Product{}, nil)
+		mockRepo.EXPECT().GetProducts(gomock.Any()).Return(nil, errors.New(""))
+
+		res, err := uc.GetProducts(context.Background(), nil, 100, 0)
+		require.Error(t, err)
+		assert.Nil(t, res)
 	})
 	t.Run("cache error", func(t *testing.T) {
 		t.Parallel()
@@ -73,12 +138,58 @@ func TestProductUseCase_GetProducts(t *testing.T) {
 
 		mockCache.EXPECT().GetList(uint(100), uint(0)).Return(nil, errors.New(""))
 
-		products, err := uc.GetProducts(context.Background(), 100, 0)
+		products, err := uc.GetProducts(context.Background(), nil, 100, 0)
 		require.Error(t, err)
 		assert.Nil(t, products)
 	})
 }
 
+func TestProductUseCase_GetProduct(t *testing.T) {
+	t.Run("cache hit", func(t *testing.T) {
+		t.Parallel()
+		uc, _, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product := newValidProduct()
+		mockCache.EXPECT().Get(product.Id.Hex()).Return(product, nil)
+
+		res, err := uc.GetProduct(context.Background(), product.Id)
+		require.NoError(t, err)
+		assert.Equal(t, product, res)
+	})
+	t.Run("cache miss", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product := newValidProduct()
+		mockCache.EXPECT().Get(product.Id.Hex()).Return(nil, cache.ErrKeyNotFound)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+		mockCache.EXPECT().Set(product).Return(nil)
+
+		res, err := uc.GetProduct(context.Background(), product.Id)
+		require.NoError(t, err)
+		assert.Equal(t, product, res)
+	})
+	t.Run("not found is cached", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		id := types.NewId()
+		notFoundErr := commonerr.NewNotFoundError("product %s not found", id.Hex())
+		mockCache.EXPECT().Get(id.Hex()).Return(nil, cache.ErrKeyNotFound).Times(2)
+		mockRepo.EXPECT().GetProduct(gomock.Any(), id).Return(nil, notFoundErr).Times(1)
+
+		for i := 0; i < 2; i++ {
+			res, err := uc.GetProduct(context.Background(), id)
+			require.Error(t, err)
+			require.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeNotFound))
+			require.Nil(t, res)
+		}
+	})
+}
+
 func TestProductUseCase_UpdateProduct(t *testing.T) {
 	t.Run("Success", func(t *testing.T) {
 		t.Parallel()
@@ -111,6 +222,22 @@ func TestProductUseCase_UpdateProduct(t *testing.T) {
 		assert.Equal(t, commonerr.ErrorTypeIncorrectInput, appError.ErrorType())
 		require.Nil(t, res)
 	})
+	t.Run("missing required translation", func(t *testing.T) {
+		t.Parallel()
+		uc, _, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		uc.validationRules = &entity.ValidationRules{RequiredLocales: []string{"en"}} //nolint: exhaustruct
+		product := newValidProduct()
+		updatedProduct := newValidProduct()
+		var appError commonerr.AppError
+
+		res, err := uc.UpdateProduct(context.Background(), product.Id, updatedProduct)
+		require.Error(t, err)
+		require.True(t, errors.As(err, &appError), err)
+		assert.Equal(t, commonerr.ErrorTypeIncorrectInput, appError.ErrorType())
+		require.Nil(t, res)
+	})
 	t.Run("product not found", func(t *testing.T) {
 		t.Parallel()
 		uc, mockRepo, _, teardown := newProductUseCase(t)
@@ -191,48 +318,139 @@ func TestProductUseCase_DeleteProduct(t *testing.T) {
 	})
 }
 
-func TestProductUseCase_syncCache(t *testing.T) {
-	products := []*entity.Product{newValidProduct(), newValidProduct(), newValidProduct()}
+func TestProductUseCase_RestoreProduct(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		product := newValidProduct()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		mockRepo.EXPECT().RestoreProduct(gomock.Any(), product.Id).Return(product, nil)
+		mockCache.EXPECT().Set(product).Return(nil)
 
-	testCases := []struct {
-		name string
-		mock func(mockRepo *repo.MockRepository, mockCache *cache.MockEntityCache[*entity.Product])
-	}{
-		{
-			name: "Success",
-			mock: func(mockRepo *repo.MockRepository, mockCache *cache.MockEntityCache[*entity.Product]) {
-				mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
-				mockCache.EXPECT().Replace(products).Return(nil)
-			},
-		},
-		{
-			name: "Repo error",
-			mock: func(mockRepo *repo.MockRepository, mockCache *cache.MockEntityCache[*entity.Product]) {
-				mockRepo.EXPECT().GetProducts(gomock.Any()).Return(nil, errors.New(""))
-			},
-		},
+		res, err := uc.RestoreProduct(context.Background(), product.Id)
+		require.NoError(t, err)
+		assert.Equal(t, product, res)
+	})
+	t.Run("negative cache is reset", func(t *testing.T) {
+		t.Parallel()
+		product := newValidProduct()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		notFoundErr := commonerr.NewNotFoundError("product %s not found", product.Id.Hex())
+
+		gomock.InOrder(
+			mockCache.EXPECT().Get(product.Id.Hex()).Return(nil, cache.ErrKeyNotFound),
+			mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(nil, notFoundErr),
+			mockRepo.EXPECT().RestoreProduct(gomock.Any(), product.Id).Return(product, nil),
+			mockCache.EXPECT().Set(product).Return(nil),
+			mockCache.EXPECT().Get(product.Id.Hex()).Return(product, nil),
+		)
+
+		_, err := uc.GetProduct(context.Background(), product.Id)
+		require.Error(t, err)
+
+		_, err = uc.RestoreProduct(context.Background(), product.Id)
+		require.NoError(t, err)
+
+		res, err := uc.GetProduct(context.Background(), product.Id)
+		require.NoError(t, err)
+		assert.Equal(t, product, res)
+	})
+	t.Run("product not found", func(t *testing.T) {
+		t.Parallel()
+		product := newValidProduct()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		notFoundErr := commonerr.NewNotFoundError("deleted product %s not found", product.Id.Hex())
+		mockRepo.EXPECT().RestoreProduct(gomock.Any(), product.Id).Return(nil, notFoundErr)
+
+		res, err := uc.RestoreProduct(context.Background(), product.Id)
+		require.Error(t, err)
+		assert.Nil(t, res)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeNotFound))
+	})
+}
+
+func TestProductUseCase_GetProductHistory(t *testing.T) {
+	product := newValidProduct()
+	changes := []*entity.ProductChange{
 		{
-			name: "Cache error",
-			mock: func(mockRepo *repo.MockRepository, mockCache *cache.MockEntityCache[*entity.Product]) {
-				mockRepo.EXPECT().GetProducts(gomock.Any()).Return(products, nil)
-				mockCache.EXPECT().Replace(products).Return(errors.New(""))
-			},
+			Id:        types.NewId(),
+			ProductId: product.Id,
+			Operation: entity.ProductOperationUpdate,
+			Before:    product,
+			After:     product,
+			Actor:     "support",
+			RequestId: "request-id",
+			ChangedAt: time.Now(),
 		},
 	}
 
-	for _, tc := range testCases {
-		tc := tc
-		t.Run(tc.name, func(t *testing.T) {
-			t.Parallel()
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
 
-			uc, mockRepo, mockCache, teardown := newProductUseCase(t)
-			defer teardown()
+		mockRepo.EXPECT().GetProductHistory(gomock.Any(), product.Id, uint(10), uint(5)).Return(changes, nil)
 
-			// test fails if has been called unexpected func or expected not been called
-			tc.mock(mockRepo, mockCache)
-			uc.syncCache(context.Background())
-		})
-	}
+		res, err := uc.GetProductHistory(context.Background(), product.Id, 10, 5)
+		require.NoError(t, err)
+		assert.Equal(t, changes, res)
+	})
+	t.Run("zero limit", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		mockRepo.EXPECT().GetProductHistory(gomock.Any(), product.Id, uint(defaultLimit), uint(0)).Return(changes, nil)
+
+		res, err := uc.GetProductHistory(context.Background(), product.Id, 0, 0)
+		require.NoError(t, err)
+		assert.Equal(t, changes, res)
+	})
+	t.Run("repo error", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		mockRepo.EXPECT().GetProductHistory(gomock.Any(), product.Id, uint(defaultLimit), uint(0)).Return(nil, errors.New(""))
+
+		res, err := uc.GetProductHistory(context.Background(), product.Id, 0, 0)
+		require.Error(t, err)
+		assert.Nil(t, res)
+	})
+}
+
+func TestProductUseCase_PurgeDeletedProducts(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		mockRepo.EXPECT().
+			PurgeDeletedProducts(gomock.Any(), gomock.Any()).
+			DoAndReturn(func(_ context.Context, before time.Time) (int64, error) {
+				assert.WithinDuration(t, time.Now().Add(-time.Hour*24*7), before, time.Minute)
+
+				return 3, nil
+			})
+
+		purged, err := uc.PurgeDeletedProducts(context.Background(), time.Hour*24*7)
+		require.NoError(t, err)
+		assert.Equal(t, int64(3), purged)
+	})
+	t.Run("zero retention", func(t *testing.T) {
+		t.Parallel()
+		uc, _, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		_, err := uc.PurgeDeletedProducts(context.Background(), 0)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeIncorrectInput))
+	})
 }
 
 func newValidProduct() *entity.Product {
@@ -240,7 +458,7 @@ func newValidProduct() *entity.Product {
 		Id:          types.NewId(),
 		Name:        gofakeit.Name(),
 		Description: gofakeit.JobDescriptor(),
-		Price:       int32(gofakeit.IntRange(1, math.MaxInt32)),
+		Price:       entity.Money{Amount: int64(gofakeit.IntRange(1, math.MaxInt32)), Currency: "RUB"},
 	}
 }
 
@@ -249,6 +467,6 @@ func newInvalidProduct() *entity.Product {
 		Id:          types.NewId(),
 		Name:        gofakeit.Name(),
 		Description: gofakeit.JobDescriptor(),
-		Price:       -1,
+		Price:       entity.Money{Amount: -1, Currency: "RUB"},
 	}
 }
@@ -0,0 +1,271 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+const (
+	categoriesCollectionName    = "categories"
+	categoryNotFoundMsgTemplate = "category with id %s not found"
+	categoryResourceType        = "category"
+	reasonCategoryNotEmpty      = "CATEGORY_NOT_EMPTY"
+)
+
+type MongoCategoryRepository struct {
+	collection *mongo.Collection
+	products   *mongo.Collection
+	timeouts   Timeouts
+	logger     logging.ContextLogger
+}
+
+func NewMongoCategoryRepository(
+	mongo *mongo.Database,
+	timeouts Timeouts,
+	logger logging.ContextLogger,
+) *MongoCategoryRepository {
+	return &MongoCategoryRepository{
+		collection: mongo.Collection(categoriesCollectionName),
+		products:   mongo.Collection(collectionName),
+		timeouts:   timeouts,
+		logger:     logger,
+	}
+}
+
+// EnsureIndexes создает индексы, необходимые репозиторию. Повторный вызов безопасен.
+func (r *MongoCategoryRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateMany(ctx, []mongo.IndexModel{
+		{Keys: bson.D{{Key: "parentId", Value: 1}}},    //nolint: exhaustruct
+		{Keys: bson.D{{Key: "ancestorIds", Value: 1}}}, //nolint: exhaustruct
+	})
+	if err != nil {
+		return fmt.Errorf("can't create %s indexes: %w", categoriesCollectionName, err)
+	}
+
+	_, err = r.products.Indexes().CreateOne(ctx, mongo.IndexModel{ //nolint: exhaustruct
+		Keys: bson.D{{Key: "categoryIds", Value: 1}},
+	})
+	if err != nil {
+		return fmt.Errorf("can't create %s indexes: %w", collectionName, err)
+	}
+
+	return nil
+}
+
+func (r *MongoCategoryRepository) GetCategories(ctx context.Context) ([]*entity.Category, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "categoryRepository.GetCategories")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.bulk(ctx)
+	defer cancel()
+
+	var categories []*entity.Category
+
+	cursor, err := r.collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.D{{Key: "name", Value: 1}}))
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	if err := cursor.All(ctx, &categories); err != nil {
+		return nil, mongoError(err)
+	}
+
+	return categories, nil
+}
+
+func (r *MongoCategoryRepository) GetCategory(ctx context.Context, id types.Id) (*entity.Category, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "categoryRepository.GetCategory")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var category entity.Category
+
+	if err := r.collection.FindOne(ctx, bson.M{"_id": id}).Decode(&category); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, commonerr.NewNotFoundError(categoryNotFoundMsgTemplate, id.Hex()).
+				WithResource(categoryResourceType, id.Hex())
+		}
+
+		return nil, mongoError(err)
+	}
+
+	return &category, nil
+}
+
+func (r *MongoCategoryRepository) CreateCategory(ctx context.Context, category *entity.Category) error {
+	ctx, span := tracing.Tracer.Start(ctx, "categoryRepository.CreateCategory")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	createdAt := now()
+	category.CreatedAt = createdAt
+	category.UpdatedAt = createdAt
+
+	if _, err := r.collection.InsertOne(ctx, category); err != nil {
+		return mongoError(err)
+	}
+
+	return nil
+}
+
+func (r *MongoCategoryRepository) UpdateCategory(
+	ctx context.Context,
+	id types.Id,
+	updatedCategory *entity.Category,
+) (*entity.Category, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "categoryRepository.UpdateCategory")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	var category entity.Category
+
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		bson.M{"$set": bson.M{"name": updatedCategory.Name, "updatedAt": now()}},
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, commonerr.NewNotFoundError(categoryNotFoundMsgTemplate, id.Hex()).
+				WithResource(categoryResourceType, id.Hex())
+		}
+
+		return nil, mongoError(err)
+	}
+
+	return &category, nil
+}
+
+// DeleteCategory удаляет категорию без подкатегорий и продуктов.
+func (r *MongoCategoryRepository) DeleteCategory(ctx context.Context, id types.Id) error {
+	ctx, span := tracing.Tracer.Start(ctx, "categoryRepository.DeleteCategory")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	children, err := r.collection.CountDocuments(ctx, bson.M{"parentId": id}, options.Count().SetLimit(1))
+	if err != nil {
+		return mongoError(err)
+	}
+
+	if children > 0 {
+		return commonerr.NewFailedPreconditionError("category %s has subcategories", id.Hex()).
+			WithReason(reasonCategoryNotEmpty, nil)
+	}
+
+	products, err := r.products.CountDocuments(
+		ctx,
+		notDeleted(bson.M{"categoryIds": id}),
+		options.Count().SetLimit(1),
+	)
+	if err != nil {
+		return mongoError(err)
+	}
+
+	if products > 0 {
+		return commonerr.NewFailedPreconditionError("category %s has products", id.Hex()).
+			WithReason(reasonCategoryNotEmpty, nil)
+	}
+
+	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	if err != nil {
+		return mongoError(err)
+	}
+
+	if res.DeletedCount == 0 {
+		return commonerr.NewNotFoundError(categoryNotFoundMsgTemplate, id.Hex()).
+			WithResource(categoryResourceType, id.Hex())
+	}
+
+	return nil
+}
+
+// MoveCategory переносит категорию с поддеревом под нового родителя.
+// ancestorIds — путь от корня до нового родителя включительно, пустой при переносе в корень.
+// Сначала обновляются потомки, затем сама категория: пока она не перенесена, повторный вызов исправит частичный перенос.
+func (r *MongoCategoryRepository) MoveCategory(
+	ctx context.Context,
+	id types.Id,
+	parentId types.Id,
+	ancestorIds []types.Id,
+) (*entity.Category, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "categoryRepository.MoveCategory")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	if ancestorIds == nil {
+		ancestorIds = []types.Id{}
+	}
+
+	updatedAt := now()
+	newPrefix := append(append([]types.Id{}, ancestorIds...), id)
+
+	// у потомков заменяется часть пути до перемещаемой категории включительно
+	_, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"ancestorIds": id},
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"ancestorIds": bson.M{"$concatArrays": bson.A{
+				newPrefix,
+				bson.M{"$slice": bson.A{
+					"$ancestorIds",
+					bson.M{"$add": bson.A{bson.M{"$indexOfArray": bson.A{"$ancestorIds", id}}, 1}},
+					bson.M{"$max": bson.A{bson.M{"$size": "$ancestorIds"}, 1}},
+				}},
+			}},
+			"updatedAt": updatedAt,
+		}}}},
+	)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	set := bson.M{"ancestorIds": ancestorIds, "updatedAt": updatedAt}
+	changes := bson.M{"$set": set}
+
+	if parentId.IsZero() {
+		changes["$unset"] = bson.M{"parentId": ""}
+	} else {
+		set["parentId"] = parentId
+	}
+
+	var category entity.Category
+
+	err = r.collection.FindOneAndUpdate(
+		ctx,
+		bson.M{"_id": id},
+		changes,
+		options.FindOneAndUpdate().SetReturnDocument(options.After),
+	).Decode(&category)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, commonerr.NewNotFoundError(categoryNotFoundMsgTemplate, id.Hex()).
+				WithResource(categoryResourceType, id.Hex())
+		}
+
+		return nil, mongoError(err)
+	}
+
+	return &category, nil
+}
@@ -0,0 +1,35 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"go.mongodb.org/mongo-driver/mongo"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+)
+
+// databaseRetryDelay подсказка клиенту, через сколько повторить запрос при недоступной базе
+const databaseRetryDelay = time.Second
+
+// mongoError переводит ошибки драйвера в AppError, чтобы клиент получил подходящий код вместо Internal.
+// Остальные ошибки, в том числе AppError и mongo.ErrNoDocuments, возвращаются как есть.
+func mongoError(err error) error {
+	switch {
+	case err == nil:
+		return nil
+	case mongo.IsDuplicateKeyError(err):
+		return commonerr.NewAlreadyExistsError("resource already exists").WithCause(err)
+	case errors.Is(err, context.Canceled):
+		return commonerr.NewCanceledError("request canceled").WithCause(err)
+	case mongo.IsTimeout(err), errors.Is(err, context.DeadlineExceeded):
+		return commonerr.NewDeadlineExceededError("database request timed out").WithCause(err)
+	case mongo.IsNetworkError(err), errors.Is(err, mongo.ErrClientDisconnected):
+		return commonerr.NewUnavailableError("database is unavailable").
+			WithRetryDelay(databaseRetryDelay).
+			WithCause(err)
+	default:
+		return err
+	}
+}
@@ -0,0 +1,147 @@
+package repo
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"time"
+
+	"go.mongodb.org/mongo-driver/bson"
+	"go.mongodb.org/mongo-driver/mongo"
+	"go.mongodb.org/mongo-driver/mongo/options"
+
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/idempotency"
+)
+
+const idempotencyCollectionName = "idempotency_keys"
+
+type idempotencyRecord struct {
+	Key         string    `bson:"_id"`
+	Method      string    `bson:"method"`
+	RequestHash []byte    `bson:"requestHash"`
+	Response    []byte    `bson:"response"`
+	Token       string    `bson:"token"`
+	CreatedAt   time.Time `bson:"createdAt"`
+	// ExpiresAt время удаления записи TTL индексом
+	ExpiresAt time.Time `bson:"expiresAt"`
+}
+
+// MongoIdempotencyStore хранит результаты запросов с ключом идемпотентности. Записи удаляются через ttl.
+type MongoIdempotencyStore struct {
+	collection *mongo.Collection
+	ttl        time.Duration
+}
+
+func NewMongoIdempotencyStore(mongo *mongo.Database, ttl time.Duration) *MongoIdempotencyStore {
+	return &MongoIdempotencyStore{
+		collection: mongo.Collection(idempotencyCollectionName),
+		ttl:        ttl,
+	}
+}
+
+// EnsureIndexes создает TTL индекс. Срок хранения записан в самих документах, поэтому смена ttl
+// не требует пересоздания индекса.
+func (s *MongoIdempotencyStore) EnsureIndexes(ctx context.Context) error {
+	_, err := s.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys:    bson.D{{Key: "expiresAt", Value: 1}},
+		Options: options.Index().SetExpireAfterSeconds(0),
+	})
+	if err != nil {
+		return fmt.Errorf("can't create %s indexes: %w", idempotencyCollectionName, err)
+	}
+
+	return nil
+}
+
+func (s *MongoIdempotencyStore) Reserve(
+	ctx context.Context,
+	record *idempotency.Record,
+	lockTimeout time.Duration,
+) (*idempotency.Record, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "idempotencyStore.Reserve")
+	defer span.End()
+
+	doc := idempotencyRecord{
+		Key:         record.Key,
+		Method:      record.Method,
+		RequestHash: record.RequestHash,
+		Response:    nil,
+		Token:       record.Token,
+		CreatedAt:   record.CreatedAt,
+		ExpiresAt:   record.CreatedAt.Add(s.ttl),
+	}
+
+	_, err := s.collection.InsertOne(ctx, doc)
+	if err == nil {
+		return nil, nil
+	}
+
+	if !mongo.IsDuplicateKeyError(err) {
+		return nil, mongoError(err)
+	}
+
+	// брошенный незавершенный запрос, например после падения сервиса, перезаписывается новым.
+	// Новый токен не дает прежнему владельцу сохранить ответ или удалить запись
+	res, err := s.collection.ReplaceOne(ctx, bson.M{
+		"_id":       record.Key,
+		"response":  nil,
+		"createdAt": bson.M{"$lt": record.CreatedAt.Add(-lockTimeout)},
+	}, doc)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	if res.MatchedCount > 0 {
+		return nil, nil
+	}
+
+	var existing idempotencyRecord
+
+	err = s.collection.FindOne(ctx, bson.M{"_id": record.Key}).Decode(&existing)
+	if errors.Is(err, mongo.ErrNoDocuments) {
+		// запись удалили между вставкой и чтением: ключ снова свободен, клиент может повторить запрос
+		return nil, fmt.Errorf("idempotency key %s was released concurrently", record.Key)
+	}
+
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	return &idempotency.Record{
+		Key:         existing.Key,
+		Method:      existing.Method,
+		RequestHash: existing.RequestHash,
+		Response:    existing.Response,
+		Token:       existing.Token,
+		CreatedAt:   existing.CreatedAt,
+	}, nil
+}
+
+func (s *MongoIdempotencyStore) Complete(ctx context.Context, key string, token string, response []byte) error {
+	ctx, span := tracing.Tracer.Start(ctx, "idempotencyStore.Complete")
+	defer span.End()
+
+	res, err := s.collection.UpdateOne(ctx, bson.M{"_id": key, "token": token, "response": nil}, bson.M{"$set": bson.M{
+		"response":  response,
+		"expiresAt": time.Now().UTC().Add(s.ttl),
+	}})
+	if err != nil {
+		return mongoError(err)
+	}
+
+	if res.MatchedCount == 0 {
+		return idempotency.ErrReservationLost
+	}
+
+	return nil
+}
+
+func (s *MongoIdempotencyStore) Release(ctx context.Context, key string, token string) error {
+	ctx, span := tracing.Tracer.Start(ctx, "idempotencyStore.Release")
+	defer span.End()
+
+	_, err := s.collection.DeleteOne(ctx, bson.M{"_id": key, "token": token, "response": nil})
+
+	return mongoError(err)
+}
@@ -2,6 +2,10 @@ package repo
 
 import (
 	"context"
+	"errors"
+	"fmt"
+	"strconv"
+	"time"
 
 	"go.mongodb.org/mongo-driver/bson"
 	"go.mongodb.org/mongo-driver/mongo"
@@ -11,39 +15,159 @@ import (
 	"github.com/qulaz/artforintrovert-test/internal/entity"
 	"github.com/qulaz/artforintrovert-test/internal/tracing"
 	"github.com/qulaz/artforintrovert-test/internal/types"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/actor"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/requestid"
 	"github.com/qulaz/artforintrovert-test/pkg/logging"
 )
 
 const (
-	collectionName      = "products"
-	notFoundMsgTemplate = "product with id %s not found"
+	collectionName          = "products"
+	historyCollectionName   = "products_history"
+	notFoundMsgTemplate     = "product with id %s not found"
+	duplicateSkuMsgTemplate = "product with sku %s already exists"
+	productResourceType     = "product"
+
+	reasonDuplicateSku           = "DUPLICATE_SKU"
+	reasonConcurrentModification = "CONCURRENT_MODIFICATION"
+	reasonInsufficientStock      = "INSUFFICIENT_STOCK"
+	// concurrentModificationRetryDelay подсказка клиенту, через сколько повторить запрос после конфликта
+	concurrentModificationRetryDelay = 100 * time.Millisecond
 )
 
 type MongoRepository struct {
 	collection *mongo.Collection
+	history    *mongo.Collection
+	timeouts   Timeouts
 	logger     logging.ContextLogger
 }
 
-func NewMongoRepository(mongo *mongo.Database, logger logging.ContextLogger) *MongoRepository {
+func NewMongoRepository(mongo *mongo.Database, timeouts Timeouts, logger logging.ContextLogger) *MongoRepository {
 	return &MongoRepository{
 		collection: mongo.Collection(collectionName),
+		history:    mongo.Collection(historyCollectionName),
+		timeouts:   timeouts,
 		logger:     logger,
 	}
 }
 
+// EnsureIndexes создает индексы, необходимые репозиторию. Повторный вызов безопасен.
+func (r *MongoRepository) EnsureIndexes(ctx context.Context) error {
+	_, err := r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "sku", Value: 1}},
+		// продукты без артикула в индекс не попадают
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"sku": bson.M{"$type": "string"}}),
+	})
+	if err != nil {
+		return fmt.Errorf("can't create %s indexes: %w", collectionName, err)
+	}
+
+	_, err = r.collection.Indexes().CreateOne(ctx, mongo.IndexModel{
+		Keys: bson.D{{Key: "variants.sku", Value: 1}},
+		Options: options.Index().
+			SetUnique(true).
+			SetPartialFilterExpression(bson.M{"variants.sku": bson.M{"$type": "string"}}),
+	})
+	if err != nil {
+		return fmt.Errorf("can't create %s indexes: %w", collectionName, err)
+	}
+
+	_, err = r.history.Indexes().CreateOne(ctx, mongo.IndexModel{ //nolint: exhaustruct
+		Keys: bson.D{{Key: "productId", Value: 1}, {Key: "changedAt", Value: -1}},
+	})
+	if err != nil {
+		return fmt.Errorf("can't create %s indexes: %w", historyCollectionName, err)
+	}
+
+	return nil
+}
+
 func (r *MongoRepository) GetProducts(ctx context.Context) ([]*entity.Product, error) {
 	ctx, span := tracing.Tracer.Start(ctx, "repository.GetProducts")
 	defer span.End()
 
+	ctx, cancel := r.timeouts.bulk(ctx)
+	defer cancel()
+
 	var products []*entity.Product
 
-	cursor, err := r.collection.Find(ctx, bson.D{}, options.Find().SetSort(bson.M{"_id": 1}))
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{}), options.Find().SetSort(bson.M{"_id": 1}))
 	if err != nil {
-		return nil, err
+		return nil, mongoError(err)
 	}
 
 	if err := cursor.All(ctx, &products); err != nil {
-		return nil, err
+		return nil, mongoError(err)
+	}
+
+	return products, nil
+}
+
+func (r *MongoRepository) GetProduct(ctx context.Context, id types.Id) (*entity.Product, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repository.GetProduct")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var product entity.Product
+
+	if err := r.collection.FindOne(ctx, notDeleted(bson.M{"_id": id})).Decode(&product); err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, commonerr.NewNotFoundError(notFoundMsgTemplate, id.Hex()).
+				WithResource(productResourceType, id.Hex())
+		}
+
+		return nil, mongoError(err)
+	}
+
+	return &product, nil
+}
+
+func (r *MongoRepository) GetProductsByIds(ctx context.Context, ids []types.Id) ([]*entity.Product, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repository.GetProductsByIds")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	var products []*entity.Product
+
+	cursor, err := r.collection.Find(ctx, notDeleted(bson.M{"_id": bson.M{"$in": ids}}))
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, mongoError(err)
+	}
+
+	return products, nil
+}
+
+// SampleProducts возвращает до size случайных продуктов.
+func (r *MongoRepository) SampleProducts(ctx context.Context, size int) ([]*entity.Product, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repository.SampleProducts")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.bulk(ctx)
+	defer cancel()
+
+	var products []*entity.Product
+
+	pipeline := mongo.Pipeline{
+		{{Key: "$match", Value: notDeleted(bson.M{})}},
+		{{Key: "$sample", Value: bson.M{"size": size}}},
+	}
+
+	cursor, err := r.collection.Aggregate(ctx, pipeline)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	if err := cursor.All(ctx, &products); err != nil {
+		return nil, mongoError(err)
 	}
 
 	return products, nil
@@ -57,48 +181,636 @@ func (r *MongoRepository) UpdateProduct(
 	ctx, span := tracing.Tracer.Start(ctx, "repository.UpdateProduct")
 	defer span.End()
 
-	res, err := r.collection.ReplaceOne(ctx, bson.M{"_id": productId}, updatedProduct)
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	updatedAt := now()
+	update := bson.M{
+		"name":         updatedProduct.Name,
+		"description":  updatedProduct.Description,
+		"price":        updatedProduct.Price,
+		"sku":          updatedProduct.Sku,
+		"categoryIds":  updatedProduct.CategoryIds,
+		"tags":         updatedProduct.Tags,
+		"images":       updatedProduct.Images,
+		"attributes":   updatedProduct.Attributes,
+		"updatedAt":    updatedAt,
+		"updatedBy":    updatedProduct.UpdatedBy,
+		"translations": updatedProduct.Translations,
+	}
+
+	unset := bson.M{}
+	if updatedProduct.Sku == "" {
+		// пустой артикул не хранится, иначе он нарушит уникальный индекс
+		delete(update, "sku")
+		unset["sku"] = ""
+	}
+
+	changes := bson.M{"$set": update, "$inc": bson.M{"version": 1}}
+	if len(unset) > 0 {
+		changes["$unset"] = unset
+	}
+
+	before, err := r.findOneAndUpdate(ctx, notDeleted(bson.M{"_id": productId}), changes)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, commonerr.NewNotFoundError(notFoundMsgTemplate, productId.Hex()).
+				WithResource(productResourceType, productId.Hex())
+		}
+
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, commonerr.NewAlreadyExistsError(duplicateSkuMsgTemplate, updatedProduct.Sku).
+				WithReason(reasonDuplicateSku, map[string]string{"sku": updatedProduct.Sku})
+		}
+
+		return nil, mongoError(err)
+	}
+
+	after := *before
+	after.Name = updatedProduct.Name
+	after.Description = updatedProduct.Description
+	after.Price = updatedProduct.Price
+	after.Sku = updatedProduct.Sku
+	after.CategoryIds = updatedProduct.CategoryIds
+	after.Tags = updatedProduct.Tags
+	after.Images = updatedProduct.Images
+	after.Attributes = updatedProduct.Attributes
+	after.Translations = updatedProduct.Translations
+	after.UpdatedAt = updatedAt
+	after.UpdatedBy = updatedProduct.UpdatedBy
+	after.Version = before.Version + 1
+
+	r.recordChange(ctx, entity.ProductOperationUpdate, before, &after)
+
+	return &after, nil
+}
+
+// UpdateVariants заменяет варианты продукта. Обновление выполняется, только если версия продукта
+// равна expectedVersion, иначе возвращается ошибка и изменение нужно повторить на актуальной версии.
+func (r *MongoRepository) UpdateVariants(
+	ctx context.Context,
+	productId types.Id,
+	variants []entity.Variant,
+	expectedVersion int64,
+) (*entity.Product, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repository.UpdateVariants")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	updatedAt := now()
+	updatedBy := actor.FromContext(ctx)
+
+	before, err := r.findOneAndUpdate(
+		ctx,
+		notDeleted(bson.M{"_id": productId, "version": versionFilter(expectedVersion)}),
+		bson.M{
+			"$set": bson.M{"variants": variants, "updatedAt": updatedAt, "updatedBy": updatedBy},
+			"$inc": bson.M{"version": 1},
+		},
+	)
 	if err != nil {
-		return nil, err
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, getErr := r.GetProduct(ctx, productId); getErr != nil {
+				return nil, getErr
+			}
+
+			return nil, commonerr.NewConflictError("product %s was modified concurrently, retry", productId.Hex()).
+				WithReason(reasonConcurrentModification, nil).
+				WithRetryDelay(concurrentModificationRetryDelay)
+		}
+
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, commonerr.NewAlreadyExistsError("variant sku is already used by another product").
+				WithReason(reasonDuplicateSku, nil)
+		}
+
+		return nil, mongoError(err)
 	}
 
-	if res.MatchedCount == 0 {
-		return nil, commonerr.NewNotFoundError(notFoundMsgTemplate, productId.Hex())
+	after := *before
+	after.Variants = variants
+	after.UpdatedAt = updatedAt
+	after.UpdatedBy = updatedBy
+	after.Version = before.Version + 1
+
+	r.recordChange(ctx, entity.ProductOperationUpdate, before, &after)
+
+	return &after, nil
+}
+
+// ChangeStock атомарно изменяет доступный и зарезервированный остаток на stockDelta и reservedDelta.
+// Изменение применяется, только если ни одно из значений не станет отрицательным, поэтому конкурентные
+// резервирования не могут продать больше, чем есть. Как и любое изменение, увеличивает версию продукта
+// и записывается в историю.
+func (r *MongoRepository) ChangeStock(
+	ctx context.Context,
+	productId types.Id,
+	stockDelta int64,
+	reservedDelta int64,
+) (*entity.Product, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repository.ChangeStock")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	filter := notDeleted(bson.M{"_id": productId})
+	if stockDelta < 0 {
+		filter["stock"] = bson.M{"$gte": -stockDelta}
+	}
+
+	if reservedDelta < 0 {
+		filter["reserved"] = bson.M{"$gte": -reservedDelta}
 	}
 
-	return updatedProduct, nil
+	updatedAt := now()
+	updatedBy := actor.FromContext(ctx)
+
+	before, err := r.findOneAndUpdate(ctx, filter, bson.M{
+		"$set": bson.M{"updatedAt": updatedAt, "updatedBy": updatedBy},
+		"$inc": bson.M{"stock": stockDelta, "reserved": reservedDelta, "version": 1},
+	})
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			if _, getErr := r.GetProduct(ctx, productId); getErr != nil {
+				return nil, getErr
+			}
+
+			return nil, commonerr.NewFailedPreconditionError("insufficient stock of product %s", productId.Hex()).
+				WithReason(reasonInsufficientStock, map[string]string{
+					"stockDelta":    strconv.FormatInt(stockDelta, 10),
+					"reservedDelta": strconv.FormatInt(reservedDelta, 10),
+				})
+		}
+
+		return nil, mongoError(err)
+	}
+
+	after := *before
+	after.Stock = before.Stock + stockDelta
+	after.Reserved = before.Reserved + reservedDelta
+	after.UpdatedAt = updatedAt
+	after.UpdatedBy = updatedBy
+	after.Version = before.Version + 1
+
+	r.recordChange(ctx, entity.ProductOperationStock, before, &after)
+
+	return &after, nil
 }
 
+// DeleteProduct мягко удаляет продукт, проставляя deletedAt. Документ остается в базе до PurgeDeletedProducts.
+// Артикулы продукта и вариантов переносятся в deletedSku, чтобы освободить их в уникальных индексах.
 func (r *MongoRepository) DeleteProduct(ctx context.Context, id types.Id) error {
 	ctx, span := tracing.Tracer.Start(ctx, "repository.DeleteProduct")
 	defer span.End()
 
-	res, err := r.collection.DeleteOne(ctx, bson.M{"_id": id})
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	deletedAt := now()
+	updatedBy := actor.FromContext(ctx)
+
+	update := append(
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"deletedAt": deletedAt,
+			"updatedAt": deletedAt,
+			"updatedBy": bson.M{"$literal": updatedBy},
+			"version":   incrementVersion(),
+		}}}},
+		releaseSkus()...,
+	)
+
+	before, _, err := r.findOneAndUpdatePipeline(ctx, notDeleted(bson.M{"_id": id}), update)
 	if err != nil {
-		return err
-	}
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return commonerr.NewNotFoundError(notFoundMsgTemplate, id.Hex()).
+				WithResource(productResourceType, id.Hex())
+		}
 
-	if res.DeletedCount == 0 {
-		return commonerr.NewNotFoundError(notFoundMsgTemplate, id.Hex())
+		return mongoError(err)
 	}
 
+	after := *before
+	after.DeletedAt = &deletedAt
+	after.UpdatedAt = deletedAt
+	after.UpdatedBy = updatedBy
+	after.Version = before.Version + 1
+
+	r.recordChange(ctx, entity.ProductOperationDelete, before, &after)
+
 	return nil
 }
 
+// RestoreProduct восстанавливает мягко удаленный продукт вместе с его артикулами. Если артикул за время
+// удаления занял другой продукт, возвращается AlreadyExists.
+func (r *MongoRepository) RestoreProduct(ctx context.Context, id types.Id) (*entity.Product, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repository.RestoreProduct")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.write(ctx)
+	defer cancel()
+
+	updatedAt := now()
+	updatedBy := actor.FromContext(ctx)
+
+	update := append(
+		mongo.Pipeline{{{Key: "$set", Value: bson.M{
+			"updatedAt": updatedAt,
+			"updatedBy": bson.M{"$literal": updatedBy},
+			"version":   incrementVersion(),
+		}}}},
+		restoreSkus()...,
+	)
+	update = append(update, bson.D{{Key: "$unset", Value: bson.A{"deletedAt"}}})
+
+	before, released, err := r.findOneAndUpdatePipeline(
+		ctx,
+		bson.M{"_id": id, "deletedAt": bson.M{"$exists": true}},
+		update,
+	)
+	if err != nil {
+		if errors.Is(err, mongo.ErrNoDocuments) {
+			return nil, commonerr.NewNotFoundError("deleted product with id %s not found", id.Hex()).
+				WithResource(productResourceType, id.Hex())
+		}
+
+		if mongo.IsDuplicateKeyError(err) {
+			return nil, commonerr.NewAlreadyExistsError("sku of product %s is already used by another product", id.Hex()).
+				WithReason(reasonDuplicateSku, nil)
+		}
+
+		return nil, mongoError(err)
+	}
+
+	released.apply(before)
+
+	after := *before
+	after.DeletedAt = nil
+	after.UpdatedAt = updatedAt
+	after.UpdatedBy = updatedBy
+	after.Version = before.Version + 1
+
+	r.recordChange(ctx, entity.ProductOperationRestore, before, &after)
+
+	return &after, nil
+}
+
+// GetProductHistory возвращает изменения продукта от новых к старым.
+func (r *MongoRepository) GetProductHistory(
+	ctx context.Context,
+	productId types.Id,
+	limit uint,
+	offset uint,
+) ([]*entity.ProductChange, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repository.GetProductHistory")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.read(ctx)
+	defer cancel()
+
+	changes := make([]*entity.ProductChange, 0)
+
+	cursor, err := r.history.Find(
+		ctx,
+		bson.M{"productId": productId},
+		options.Find().
+			SetSort(bson.D{{Key: "changedAt", Value: -1}, {Key: "_id", Value: -1}}).
+			SetLimit(int64(limit)).
+			SetSkip(int64(offset)),
+	)
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	if err := cursor.All(ctx, &changes); err != nil {
+		return nil, mongoError(err)
+	}
+
+	return changes, nil
+}
+
+// PurgeDeletedProducts физически удаляет продукты, удаленные раньше before, вместе с их историей.
+// Возвращает число удаленных продуктов.
+func (r *MongoRepository) PurgeDeletedProducts(ctx context.Context, before time.Time) (int64, error) {
+	ctx, span := tracing.Tracer.Start(ctx, "repository.PurgeDeletedProducts")
+	defer span.End()
+
+	ctx, cancel := r.timeouts.bulk(ctx)
+	defer cancel()
+
+	filter := bson.M{"deletedAt": bson.M{"$lt": before}}
+
+	ids, err := r.productIds(ctx, filter)
+	if err != nil || len(ids) == 0 {
+		return 0, err
+	}
+
+	filter["_id"] = bson.M{"$in": ids}
+
+	res, err := r.collection.DeleteMany(ctx, filter)
+	if err != nil {
+		return 0, mongoError(err)
+	}
+
+	// продукт могли восстановить между выборкой и удалением: его история остается
+	restored, err := r.productIds(ctx, bson.M{"_id": bson.M{"$in": ids}})
+	if err != nil {
+		return res.DeletedCount, err
+	}
+
+	_, err = r.history.DeleteMany(ctx, bson.M{"productId": bson.M{"$in": ids, "$nin": restored}})
+	if err != nil {
+		return res.DeletedCount, fmt.Errorf("can't delete history of purged products: %w", mongoError(err))
+	}
+
+	return res.DeletedCount, nil
+}
+
+// productIds возвращает идентификаторы продуктов, подходящих под filter.
+func (r *MongoRepository) productIds(ctx context.Context, filter bson.M) ([]types.Id, error) {
+	cursor, err := r.collection.Find(ctx, filter, options.Find().SetProjection(bson.M{"_id": 1}))
+	if err != nil {
+		return nil, mongoError(err)
+	}
+
+	var docs []struct {
+		Id types.Id `bson:"_id"`
+	}
+
+	if err := cursor.All(ctx, &docs); err != nil {
+		return nil, mongoError(err)
+	}
+
+	ids := make([]types.Id, len(docs))
+	for i, doc := range docs {
+		ids[i] = doc.Id
+	}
+
+	return ids, nil
+}
+
 func (r *MongoRepository) CreateProducts(ctx context.Context, products []*entity.Product) error {
 	ctx, span := tracing.Tracer.Start(ctx, "repository.CreateProducts")
 	defer span.End()
 
+	ctx, cancel := r.timeouts.bulk(ctx)
+	defer cancel()
+
 	newProducts := make([]interface{}, len(products))
+	createdAt := now()
 
 	for i := range products {
+		if products[i].CreatedAt.IsZero() {
+			products[i].CreatedAt = createdAt
+		}
+
+		if products[i].UpdatedAt.IsZero() {
+			products[i].UpdatedAt = products[i].CreatedAt
+		}
+
 		newProducts[i] = products[i]
 	}
 
 	_, err := r.collection.InsertMany(ctx, newProducts)
 	if err != nil {
-		return err
+		if mongo.IsDuplicateKeyError(err) {
+			return commonerr.NewAlreadyExistsError("products contain duplicate sku").WithReason(reasonDuplicateSku, nil)
+		}
+
+		return mongoError(err)
 	}
 
 	return nil
 }
+
+// MigrateLegacyPrices переводит цены, сохраненные числом без валюты, в Money с валютой currency.
+// Старая цена считается суммой в основных единицах. Миграция идемпотентна: уже переведенные документы не меняются.
+// Возвращает число обновленных документов продуктов и записей истории.
+func (r *MongoRepository) MigrateLegacyPrices(ctx context.Context, currency entity.Currency) (int64, error) {
+	var migrated int64
+
+	for _, target := range []struct {
+		collection *mongo.Collection
+		field      string
+	}{
+		{collection: r.collection, field: "price"},
+		{collection: r.history, field: "before.price"},
+		{collection: r.history, field: "after.price"},
+	} {
+		res, err := target.collection.UpdateMany(
+			ctx,
+			bson.M{target.field: bson.M{"$type": "number"}},
+			mongo.Pipeline{{{Key: "$set", Value: bson.M{
+				target.field: bson.M{
+					"amount":   bson.M{"$multiply": bson.A{bson.M{"$toLong": "$" + target.field}, currency.Multiplier()}},
+					"currency": currency.Code,
+				},
+			}}}},
+		)
+		if err != nil {
+			return migrated, fmt.Errorf("can't migrate %s in %s: %w", target.field, target.collection.Name(), err)
+		}
+
+		migrated += res.ModifiedCount
+	}
+
+	return migrated, nil
+}
+
+// BackfillTimestamps проставляет createdAt и updatedAt продуктам, созданным до их появления. Время создания
+// берется из ObjectId, время изменения неизвестно и считается равным времени создания.
+func (r *MongoRepository) BackfillTimestamps(ctx context.Context) (int64, error) {
+	res, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{"$or": bson.A{
+			bson.M{"createdAt": bson.M{"$exists": false}},
+			bson.M{"updatedAt": bson.M{"$exists": false}},
+		}},
+		mongo.Pipeline{
+			{{Key: "$set", Value: bson.M{"createdAt": bson.M{"$ifNull": bson.A{"$createdAt", bson.M{"$toDate": "$_id"}}}}}},
+			{{Key: "$set", Value: bson.M{"updatedAt": bson.M{"$ifNull": bson.A{"$updatedAt", "$createdAt"}}}}},
+		},
+	)
+	if err != nil {
+		return 0, fmt.Errorf("can't backfill timestamps in %s: %w", collectionName, err)
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// ReleaseDeletedSkus освобождает артикулы продуктов, удаленных до переноса sku в deletedSku при удалении.
+func (r *MongoRepository) ReleaseDeletedSkus(ctx context.Context) (int64, error) {
+	res, err := r.collection.UpdateMany(
+		ctx,
+		bson.M{
+			"deletedAt": bson.M{"$exists": true},
+			"$or":       bson.A{bson.M{"sku": bson.M{"$exists": true}}, bson.M{"variants.sku": bson.M{"$exists": true}}},
+		},
+		releaseSkus(),
+	)
+	if err != nil {
+		return 0, fmt.Errorf("can't release sku of deleted products: %w", err)
+	}
+
+	return res.ModifiedCount, nil
+}
+
+// findOneAndUpdatePipeline применяет pipeline к документу и возвращает его состояние до изменения
+// вместе с артикулами, перенесенными releaseSkus.
+func (r *MongoRepository) findOneAndUpdatePipeline(
+	ctx context.Context,
+	filter bson.M,
+	pipeline mongo.Pipeline,
+) (*entity.Product, *releasedSkuFields, error) {
+	raw, err := r.collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		pipeline,
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).DecodeBytes()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	var (
+		before   entity.Product
+		released releasedSkuFields
+	)
+
+	if err := bson.Unmarshal(raw, &before); err != nil {
+		return nil, nil, err
+	}
+
+	if err := bson.Unmarshal(raw, &released); err != nil {
+		return nil, nil, err
+	}
+
+	return &before, &released, nil
+}
+
+// findOneAndUpdate применяет update к документу и возвращает его состояние до изменения.
+func (r *MongoRepository) findOneAndUpdate(ctx context.Context, filter bson.M, update bson.M) (*entity.Product, error) {
+	var before entity.Product
+
+	err := r.collection.FindOneAndUpdate(
+		ctx,
+		filter,
+		update,
+		options.FindOneAndUpdate().SetReturnDocument(options.Before),
+	).Decode(&before)
+	if err != nil {
+		return nil, err
+	}
+
+	return &before, nil
+}
+
+// recordChange сохраняет изменение в историю. Ошибка записи истории не отменяет уже выполненное изменение,
+// поэтому она только логируется и отправляется в Sentry.
+func (r *MongoRepository) recordChange(
+	ctx context.Context,
+	operation entity.ProductOperation,
+	before *entity.Product,
+	after *entity.Product,
+) {
+	change := &entity.ProductChange{
+		Id:        types.NewId(),
+		ProductId: before.Id,
+		Operation: operation,
+		Before:    before,
+		After:     after,
+		Actor:     actor.FromContext(ctx),
+		RequestId: requestid.FromContext(ctx),
+		ChangedAt: after.UpdatedAt,
+	}
+
+	if _, err := r.history.InsertOne(ctx, change); err != nil {
+		commonerr.SendToSentry(ctx, err, &commonerr.SentryInfo{
+			Contexts: map[string]interface{}{"change": change},
+		})
+		r.logger.Errorw("can't record product change", "productId", before.Id, "operation", operation, "err", err)
+	}
+}
+
+// notDeleted дополняет фильтр условием, исключающим мягко удаленные продукты.
+func notDeleted(filter bson.M) bson.M {
+	filter["deletedAt"] = bson.M{"$exists": false}
+
+	return filter
+}
+
+// releasedSkuFields артикулы удаленного продукта, перенесенные releaseSkus.
+type releasedSkuFields struct {
+	Sku      string `bson:"deletedSku,omitempty"`
+	Variants []struct {
+		Sku string `bson:"deletedSku,omitempty"`
+	} `bson:"variants,omitempty"`
+}
+
+// apply возвращает артикулы в продукт, прочитанный до восстановления.
+func (f *releasedSkuFields) apply(product *entity.Product) {
+	product.Sku = f.Sku
+
+	variants := make([]entity.Variant, len(product.Variants))
+	copy(variants, product.Variants)
+
+	for i := range variants {
+		if i < len(f.Variants) {
+			variants[i].Sku = f.Variants[i].Sku
+		}
+	}
+
+	product.Variants = variants
+}
+
+// releaseSkus стадии pipeline, переносящие sku продукта и вариантов в deletedSku. Уникальные индексы
+// по sku и variants.sku частичные, поэтому удаленный продукт перестает занимать свои артикулы.
+func releaseSkus() mongo.Pipeline {
+	return moveVariantField("sku", "deletedSku")
+}
+
+// restoreSkus обратные releaseSkus стадии.
+func restoreSkus() mongo.Pipeline {
+	return moveVariantField("deletedSku", "sku")
+}
+
+// moveVariantField переносит поле from в to у продукта и у каждого варианта. Если from нет, сохраняется
+// прежнее значение to: так повторное применение не теряет артикулы.
+func moveVariantField(from string, to string) mongo.Pipeline {
+	return mongo.Pipeline{
+		{{Key: "$set", Value: bson.M{
+			to: bson.M{"$ifNull": bson.A{"$" + from, "$" + to}},
+			"variants": bson.M{"$map": bson.M{
+				"input": bson.M{"$ifNull": bson.A{"$variants", bson.A{}}},
+				"as":    "variant",
+				"in": bson.M{"$mergeObjects": bson.A{
+					"$$variant",
+					bson.M{to: bson.M{"$ifNull": bson.A{"$$variant." + from, "$$variant." + to}}},
+				}},
+			}},
+		}}},
+		{{Key: "$unset", Value: bson.A{from, "variants." + from}}},
+	}
+}
+
+// incrementVersion выражение pipeline, увеличивающее версию продукта. Отсутствующая версия считается нулевой.
+func incrementVersion() bson.M {
+	return bson.M{"$add": bson.A{bson.M{"$ifNull": bson.A{"$version", 0}}, 1}}
+}
+
+// versionFilter условие на версию продукта. Документы без поля version считаются версией 0.
+func versionFilter(version int64) interface{} {
+	if version == 0 {
+		return bson.M{"$in": bson.A{nil, 0}}
+	}
+
+	return version
+}
+
+// now текущее время с точностью, с которой его хранит MongoDB.
+// Так значение в кеше совпадает с прочитанным из базы.
+func now() time.Time {
+	return time.Now().UTC().Truncate(time.Millisecond)
+}
@@ -0,0 +1,37 @@
+package repo
+
+import (
+	"context"
+	"time"
+)
+
+// Timeouts ограничения времени запросов к базе по видам операций. Более ранний дедлайн вызывающего
+// сохраняется. Нулевое значение не ограничивает запрос.
+type Timeouts struct {
+	// Read чтение одного документа или страницы
+	Read time.Duration
+	// Write изменение документа вместе с записью в историю
+	Write time.Duration
+	// Bulk чтение и изменение всей коллекции: синхронизация кеша, наполнение базы, очистка удаленных
+	Bulk time.Duration
+}
+
+func (t Timeouts) read(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, t.Read)
+}
+
+func (t Timeouts) write(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, t.Write)
+}
+
+func (t Timeouts) bulk(ctx context.Context) (context.Context, context.CancelFunc) {
+	return withTimeout(ctx, t.Bulk)
+}
+
+func withTimeout(ctx context.Context, timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return ctx, func() {}
+	}
+
+	return context.WithTimeout(ctx, timeout)
+}
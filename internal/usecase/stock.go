@@ -0,0 +1,71 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func (p *ProductUseCase) ReserveStock(ctx context.Context, productId types.Id, quantity int64) (*entity.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx, "productId", productId, "quantity", quantity)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.ReserveStock")
+	defer span.End()
+
+	if quantity <= 0 {
+		return nil, commonerr.NewIncorrectInputError("quantity must be greater than 0")
+	}
+
+	return p.changeStock(ctx, productId, -quantity, quantity)
+}
+
+func (p *ProductUseCase) ReleaseStock(ctx context.Context, productId types.Id, quantity int64) (*entity.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx, "productId", productId, "quantity", quantity)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.ReleaseStock")
+	defer span.End()
+
+	if quantity <= 0 {
+		return nil, commonerr.NewIncorrectInputError("quantity must be greater than 0")
+	}
+
+	return p.changeStock(ctx, productId, quantity, -quantity)
+}
+
+func (p *ProductUseCase) AdjustStock(ctx context.Context, productId types.Id, delta int64) (*entity.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx, "productId", productId, "delta", delta)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.AdjustStock")
+	defer span.End()
+
+	if delta == 0 {
+		return nil, commonerr.NewIncorrectInputError("delta must not be 0")
+	}
+
+	return p.changeStock(ctx, productId, delta, 0)
+}
+
+func (p *ProductUseCase) changeStock(
+	ctx context.Context,
+	productId types.Id,
+	stockDelta int64,
+	reservedDelta int64,
+) (*entity.Product, error) {
+	_, logger := p.logger.FromContext(ctx)
+
+	product, err := p.repo.ChangeStock(ctx, productId, stockDelta, reservedDelta)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := p.cache.Set(product); err != nil {
+		commonerr.SendToSentry(ctx, errors.WithStack(err), &commonerr.SentryInfo{
+			Contexts: map[string]interface{}{"productId": productId},
+		})
+		logger.Warnw("error while updating product stock in cache", "err", err)
+	}
+
+	return product, nil
+}
@@ -0,0 +1,117 @@
+package usecase
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func TestProductUseCase_ChangeStock(t *testing.T) {
+	productId := types.NewId()
+
+	testCases := []struct {
+		name          string
+		call          func(uc *ProductUseCase) error
+		stockDelta    int64
+		reservedDelta int64
+	}{
+		{
+			name: "reserve",
+			call: func(uc *ProductUseCase) error {
+				_, err := uc.ReserveStock(context.Background(), productId, 3)
+
+				return err
+			},
+			stockDelta:    -3,
+			reservedDelta: 3,
+		},
+		{
+			name: "release",
+			call: func(uc *ProductUseCase) error {
+				_, err := uc.ReleaseStock(context.Background(), productId, 2)
+
+				return err
+			},
+			stockDelta:    2,
+			reservedDelta: -2,
+		},
+		{
+			name: "adjust",
+			call: func(uc *ProductUseCase) error {
+				_, err := uc.AdjustStock(context.Background(), productId, -5)
+
+				return err
+			},
+			stockDelta:    -5,
+			reservedDelta: 0,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+			defer teardown()
+
+			product := newValidProduct()
+			mockRepo.EXPECT().ChangeStock(gomock.Any(), productId, tc.stockDelta, tc.reservedDelta).Return(product, nil)
+			mockCache.EXPECT().Set(product).Return(nil)
+
+			require.NoError(t, tc.call(uc))
+		})
+	}
+}
+
+func TestProductUseCase_ReserveStock(t *testing.T) {
+	t.Run("insufficient stock", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		productId := types.NewId()
+		mockRepo.EXPECT().
+			ChangeStock(gomock.Any(), productId, int64(-10), int64(10)).
+			Return(nil, commonerr.NewFailedPreconditionError("insufficient stock of product %s", productId.Hex()))
+
+		res, err := uc.ReserveStock(context.Background(), productId, 10)
+		require.Error(t, err)
+		assert.Nil(t, res)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeFailedPrecondition))
+	})
+	t.Run("non-positive quantity", func(t *testing.T) {
+		t.Parallel()
+		uc, _, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		_, err := uc.ReserveStock(context.Background(), types.NewId(), 0)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeIncorrectInput))
+
+		_, err = uc.ReleaseStock(context.Background(), types.NewId(), -1)
+		require.Error(t, err)
+
+		_, err = uc.AdjustStock(context.Background(), types.NewId(), 0)
+		require.Error(t, err)
+	})
+	t.Run("cache error is not returned", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product := newValidProduct()
+		mockRepo.EXPECT().ChangeStock(gomock.Any(), product.Id, int64(-1), int64(1)).Return(product, nil)
+		mockCache.EXPECT().Set(product).Return(errors.New(""))
+
+		res, err := uc.ReserveStock(context.Background(), product.Id, 1)
+		require.NoError(t, err)
+		assert.Equal(t, product, res)
+	})
+}
@@ -0,0 +1,116 @@
+package usecase
+
+import (
+	"context"
+
+	"github.com/pkg/errors"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/tracing"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+const (
+	variantNotFoundMsgTemplate = "variant %s of product %s not found"
+	variantResourceType        = "variant"
+)
+
+func (p *ProductUseCase) AddVariant(
+	ctx context.Context,
+	productId types.Id,
+	variant *entity.Variant,
+) (*entity.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx, "productId", productId, "variant", variant)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.AddVariant")
+	defer span.End()
+
+	variant.Id = types.NewId()
+
+	return p.modifyVariants(ctx, productId, func(product *entity.Product) error {
+		product.Variants = append(product.Variants, *variant)
+
+		return nil
+	})
+}
+
+func (p *ProductUseCase) UpdateVariant(
+	ctx context.Context,
+	productId types.Id,
+	variant *entity.Variant,
+) (*entity.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx, "productId", productId, "variant", variant)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.UpdateVariant")
+	defer span.End()
+
+	return p.modifyVariants(ctx, productId, func(product *entity.Product) error {
+		current, ok := product.Variant(variant.Id)
+		if !ok {
+			return commonerr.NewNotFoundError(variantNotFoundMsgTemplate, variant.Id.Hex(), productId.Hex()).
+				WithResource(variantResourceType, variant.Id.Hex())
+		}
+
+		*current = *variant
+
+		return nil
+	})
+}
+
+func (p *ProductUseCase) DeleteVariant(
+	ctx context.Context,
+	productId types.Id,
+	variantId types.Id,
+) (*entity.Product, error) {
+	ctx, _ = p.logger.FromContext(ctx, "productId", productId, "variantId", variantId)
+	ctx, span := tracing.Tracer.Start(ctx, "productUseCase.DeleteVariant")
+	defer span.End()
+
+	return p.modifyVariants(ctx, productId, func(product *entity.Product) error {
+		for i := range product.Variants {
+			if product.Variants[i].Id == variantId {
+				product.Variants = append(product.Variants[:i], product.Variants[i+1:]...)
+
+				return nil
+			}
+		}
+
+		return commonerr.NewNotFoundError(variantNotFoundMsgTemplate, variantId.Hex(), productId.Hex()).
+			WithResource(variantResourceType, variantId.Hex())
+	})
+}
+
+// modifyVariants применяет modify к актуальной версии продукта из базы, проверяет результат и сохраняет варианты.
+func (p *ProductUseCase) modifyVariants(
+	ctx context.Context,
+	productId types.Id,
+	modify func(product *entity.Product) error,
+) (*entity.Product, error) {
+	_, logger := p.logger.FromContext(ctx)
+
+	product, err := p.repo.GetProduct(ctx, productId)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := modify(product); err != nil {
+		return nil, err
+	}
+
+	if err := product.ValidateWith(p.validationRules); err != nil {
+		return nil, err
+	}
+
+	updatedProduct, err := p.repo.UpdateVariants(ctx, productId, product.Variants, product.Version)
+	if err != nil {
+		return nil, errors.WithStack(err)
+	}
+
+	if err := p.cache.Set(updatedProduct); err != nil {
+		commonerr.SendToSentry(ctx, errors.WithStack(err), &commonerr.SentryInfo{
+			Contexts: map[string]interface{}{"productId": productId},
+		})
+		logger.Warnw("error while updating product variants in cache", "err", err)
+	}
+
+	return updatedProduct, nil
+}
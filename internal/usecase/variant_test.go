@@ -0,0 +1,199 @@
+package usecase
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/golang/mock/gomock"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/internal/entity"
+	"github.com/qulaz/artforintrovert-test/internal/types"
+)
+
+func newProductWithVariant() (*entity.Product, entity.Variant) {
+	product := newValidProduct()
+	product.UpdatedAt = time.Now().UTC().Truncate(time.Millisecond)
+	product.Version = 3
+
+	variant := entity.Variant{
+		Id:      types.NewId(),
+		Sku:     "SKU-S",
+		Price:   entity.Money{Amount: 1000, Currency: "RUB"},
+		Options: map[string]string{"size": "S"},
+	}
+	product.Variants = []entity.Variant{variant}
+
+	return product, variant
+}
+
+// returnUpdatedVariants имитирует репозиторий, возвращающий продукт с сохраненными вариантами.
+func returnUpdatedVariants(product *entity.Product) func(
+	context.Context, types.Id, []entity.Variant, int64,
+) (*entity.Product, error) {
+	return func(_ context.Context, _ types.Id, variants []entity.Variant, _ int64) (*entity.Product, error) {
+		updated := *product
+		updated.Variants = variants
+
+		return &updated, nil
+	}
+}
+
+func TestProductUseCase_AddVariant(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product, _ := newProductWithVariant()
+		variant := &entity.Variant{ //nolint: exhaustruct
+			Sku:     "SKU-M",
+			Price:   entity.Money{Amount: 1200, Currency: "RUB"},
+			Options: map[string]string{"size": "M"},
+		}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+		mockRepo.EXPECT().
+			UpdateVariants(gomock.Any(), product.Id, gomock.Len(2), product.Version).
+			DoAndReturn(returnUpdatedVariants(product))
+		mockCache.EXPECT().Set(gomock.Any()).Return(nil)
+
+		res, err := uc.AddVariant(context.Background(), product.Id, variant)
+		require.NoError(t, err)
+		require.Len(t, res.Variants, 2)
+		assert.False(t, res.Variants[1].Id.IsZero())
+		assert.Equal(t, "SKU-M", res.Variants[1].Sku)
+	})
+	t.Run("product without timestamps", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		// продукт, созданный до появления createdAt, updatedAt и version
+		product, _ := newProductWithVariant()
+		product.CreatedAt = time.Time{}
+		product.UpdatedAt = time.Time{}
+		product.Version = 0
+		variant := &entity.Variant{ //nolint: exhaustruct
+			Sku:     "SKU-M",
+			Price:   entity.Money{Amount: 1200, Currency: "RUB"},
+			Options: map[string]string{"size": "M"},
+		}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+		mockRepo.EXPECT().
+			UpdateVariants(gomock.Any(), product.Id, gomock.Len(2), int64(0)).
+			DoAndReturn(returnUpdatedVariants(product))
+		mockCache.EXPECT().Set(gomock.Any()).Return(nil)
+
+		res, err := uc.AddVariant(context.Background(), product.Id, variant)
+		require.NoError(t, err)
+		require.Len(t, res.Variants, 2)
+	})
+	t.Run("duplicate options", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product, existing := newProductWithVariant()
+		variant := &entity.Variant{ //nolint: exhaustruct
+			Sku:     "SKU-S2",
+			Price:   entity.Money{Amount: 1200, Currency: "RUB"},
+			Options: existing.Options,
+		}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+
+		_, err := uc.AddVariant(context.Background(), product.Id, variant)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeIncorrectInput))
+	})
+}
+
+func TestProductUseCase_UpdateVariant(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product, variant := newProductWithVariant()
+		variant.Price = entity.Money{Amount: 1500, Currency: "RUB"}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+		mockRepo.EXPECT().
+			UpdateVariants(gomock.Any(), product.Id, []entity.Variant{variant}, product.Version).
+			DoAndReturn(returnUpdatedVariants(product))
+		mockCache.EXPECT().Set(gomock.Any()).Return(nil)
+
+		res, err := uc.UpdateVariant(context.Background(), product.Id, &variant)
+		require.NoError(t, err)
+		assert.Equal(t, int64(1500), res.Variants[0].Price.Amount)
+	})
+	t.Run("variant not found", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product, variant := newProductWithVariant()
+		variant.Id = types.NewId()
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+
+		_, err := uc.UpdateVariant(context.Background(), product.Id, &variant)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeNotFound))
+	})
+	t.Run("invalid price", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product, variant := newProductWithVariant()
+		variant.Price = entity.Money{Amount: 1500, Currency: "XXX"}
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+
+		_, err := uc.UpdateVariant(context.Background(), product.Id, &variant)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeIncorrectInput))
+	})
+}
+
+func TestProductUseCase_DeleteVariant(t *testing.T) {
+	t.Run("Success", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, mockCache, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product, variant := newProductWithVariant()
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+		mockRepo.EXPECT().
+			UpdateVariants(gomock.Any(), product.Id, gomock.Len(0), product.Version).
+			DoAndReturn(returnUpdatedVariants(product))
+		mockCache.EXPECT().Set(gomock.Any()).Return(nil)
+
+		res, err := uc.DeleteVariant(context.Background(), product.Id, variant.Id)
+		require.NoError(t, err)
+		assert.Empty(t, res.Variants)
+	})
+	t.Run("concurrent modification", func(t *testing.T) {
+		t.Parallel()
+		uc, mockRepo, _, teardown := newProductUseCase(t)
+		defer teardown()
+
+		product, variant := newProductWithVariant()
+
+		mockRepo.EXPECT().GetProduct(gomock.Any(), product.Id).Return(product, nil)
+		mockRepo.EXPECT().
+			UpdateVariants(gomock.Any(), product.Id, gomock.Any(), product.Version).
+			Return(nil, commonerr.NewConflictError("product %s was modified concurrently, retry", product.Id.Hex()))
+
+		_, err := uc.DeleteVariant(context.Background(), product.Id, variant.Id)
+		require.Error(t, err)
+		assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeConflict))
+	})
+}
@@ -0,0 +1,71 @@
+package cache
+
+import (
+	"context"
+	"sync"
+)
+
+const defaultSubscriberBufferSize = 64
+
+var _ InvalidationBus = (*MemoryInvalidationBus)(nil)
+
+type subscriber struct {
+	messages chan InvalidationMessage
+}
+
+// MemoryInvalidationBus реализация InvalidationBus в рамках одного процесса.
+type MemoryInvalidationBus struct {
+	subscribers map[*subscriber]struct{}
+	mutex       sync.RWMutex
+}
+
+func NewMemoryInvalidationBus() *MemoryInvalidationBus {
+	return &MemoryInvalidationBus{
+		subscribers: make(map[*subscriber]struct{}),
+		mutex:       sync.RWMutex{},
+	}
+}
+
+// Publish не блокируется: если буфер подписчика заполнен, сообщение для него отбрасывается
+// и учитывается в метрике cache_invalidation_messages_dropped_total.
+func (b *MemoryInvalidationBus) Publish(msg InvalidationMessage) error {
+	b.mutex.RLock()
+	defer b.mutex.RUnlock()
+
+	for s := range b.subscribers {
+		deliver(s.messages, msg)
+	}
+
+	return nil
+}
+
+func (b *MemoryInvalidationBus) Subscribe(ctx context.Context) (<-chan InvalidationMessage, error) {
+	s := &subscriber{
+		messages: make(chan InvalidationMessage, defaultSubscriberBufferSize),
+	}
+
+	b.mutex.Lock()
+	b.subscribers[s] = struct{}{}
+	b.mutex.Unlock()
+
+	go func() {
+		<-ctx.Done()
+
+		b.mutex.Lock()
+		delete(b.subscribers, s)
+		b.mutex.Unlock()
+
+		close(s.messages)
+	}()
+
+	return s.messages, nil
+}
+
+// deliver отправляет сообщение подписчику без ожидания, отбрасывая его при заполненном буфере.
+func deliver(messages chan<- InvalidationMessage, msg InvalidationMessage) {
+	select {
+	case messages <- msg:
+	default:
+		invalidationMessagesDropped.Inc()
+	}
+}
@@ -0,0 +1,61 @@
+package cache
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/prometheus/client_golang/prometheus/testutil"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryInvalidationBus_Publish(t *testing.T) {
+	t.Run("slow subscriber does not block publisher", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		bus := NewMemoryInvalidationBus()
+		messages, err := bus.Subscribe(ctx)
+		require.NoError(t, err)
+
+		dropped := testutil.ToFloat64(invalidationMessagesDropped)
+		published := make(chan struct{})
+
+		go func() {
+			defer close(published)
+
+			for i := 0; i < defaultSubscriberBufferSize+10; i++ {
+				_ = bus.Publish(InvalidationMessage{Origin: "node", Key: "key"})
+			}
+		}()
+
+		select {
+		case <-published:
+		case <-time.After(time.Second):
+			t.Fatal("publish blocked on a subscriber with a full buffer")
+		}
+
+		assert.Len(t, messages, defaultSubscriberBufferSize)
+		assert.Equal(t, dropped+10, testutil.ToFloat64(invalidationMessagesDropped))
+	})
+	t.Run("subscription closed after context cancel", func(t *testing.T) {
+		ctx, cancel := context.WithCancel(context.Background())
+
+		bus := NewMemoryInvalidationBus()
+		messages, err := bus.Subscribe(ctx)
+		require.NoError(t, err)
+
+		cancel()
+
+		require.Eventually(t, func() bool {
+			select {
+			case _, ok := <-messages:
+				return !ok
+			default:
+				return false
+			}
+		}, time.Second, time.Millisecond)
+		require.NoError(t, bus.Publish(InvalidationMessage{Origin: "node", Key: "key"}))
+	})
+}
@@ -1,6 +1,9 @@
 package cache
 
-import "errors"
+import (
+	"context"
+	"errors"
+)
 
 var ErrKeyNotFound = errors.New("key not found")
 
@@ -14,5 +17,20 @@ type EntityCache[V Hashable] interface {
 	Set(value V) error
 	Delete(key string) error
 	GetList(limit uint, offset uint) ([]V, error)
+	// Find возвращает страницу значений, для которых match вернул true
+	Find(match func(value V) bool, limit uint, offset uint) ([]V, error)
 	Replace(values []V) error
 }
+
+// InvalidationMessage сообщение об изменении записи с ключом Key на узле Origin.
+type InvalidationMessage struct {
+	Origin string
+	Key    string
+}
+
+// InvalidationBus pub/sub канал, через который реплики оповещают друг друга об изменениях в кеше.
+type InvalidationBus interface {
+	Publish(msg InvalidationMessage) error
+	// Subscribe возвращает канал с сообщениями. Канал закрывается после отмены ctx.
+	Subscribe(ctx context.Context) (<-chan InvalidationMessage, error)
+}
@@ -0,0 +1,392 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync"
+	"time"
+
+	"github.com/google/uuid"
+	"golang.org/x/sync/singleflight"
+)
+
+const (
+	// listKey ключ для singleflight и негативного кеша при загрузке полного списка.
+	listKey = "\x00list"
+	// defaultMaxNegativeEntries размер негативного кеша, если LoaderOptions.MaxNegativeEntries не задан
+	defaultMaxNegativeEntries = 10000
+)
+
+type (
+	LoadFunc[V Hashable]    func(ctx context.Context, key string) (V, error)
+	LoadAllFunc[V Hashable] func(ctx context.Context) ([]V, error)
+)
+
+type LoaderOptions struct {
+	// NegativeTtl время, в течение которого повторный запрос отсутствующего ключа не уходит в источник.
+	// Нулевое значение отключает негативное кеширование.
+	NegativeTtl time.Duration
+
+	// MaxNegativeEntries ограничение числа ключей в негативном кеше. При переполнении сначала удаляются
+	// истекшие записи, затем произвольные. Нулевое значение — defaultMaxNegativeEntries.
+	MaxNegativeEntries int
+
+	// LoadTimeout ограничение времени одной загрузки из источника. Загрузка общая для всех ожидающих ее
+	// вызовов и не отменяется вместе с контекстом первого из них. Нулевое значение — без ограничения.
+	LoadTimeout time.Duration
+
+	// IsNotFound определяет, что ошибка источника означает отсутствие записи.
+	IsNotFound func(err error) bool
+
+	// Bus канал инвалидации между репликами. Forget рассылает через него ключ остальным репликам,
+	// Run удаляет из негативного кеша ключи, измененные на других репликах. nil — только эта реплика.
+	Bus InvalidationBus
+}
+
+type negativeEntry struct {
+	err       error
+	expiresAt time.Time
+}
+
+// inflightLoad загрузка ключа из источника. invalidated выставляется, если ключ удалили во время загрузки:
+// прочитанное до удаления значение не должно вернуться в кеш.
+type inflightLoad struct {
+	invalidated bool
+}
+
+// Loader read-through обертка над EntityCache: при промахе загружает данные из источника.
+// Одновременные промахи по одному ключу объединяются в один запрос к источнику.
+type Loader[V Hashable] struct {
+	cache    EntityCache[V]
+	load     LoadFunc[V]
+	loadAll  LoadAllFunc[V]
+	options  LoaderOptions
+	group    singleflight.Group
+	negative map[string]negativeEntry
+	// lastSweep время последней очистки негативного кеша от истекших записей
+	lastSweep time.Time
+	mutex     sync.Mutex
+	// inflight загрузки по ключам. loadMutex упорядочивает запись загруженного значения в кеш и Delete
+	inflight  map[string]*inflightLoad
+	loadMutex sync.Mutex
+	nodeId    string
+	now       func() time.Time
+}
+
+func NewLoader[V Hashable](
+	cache EntityCache[V],
+	load LoadFunc[V],
+	loadAll LoadAllFunc[V],
+	options LoaderOptions,
+) *Loader[V] {
+	if options.IsNotFound == nil {
+		options.IsNotFound = func(err error) bool { return false }
+	}
+
+	if options.MaxNegativeEntries <= 0 {
+		options.MaxNegativeEntries = defaultMaxNegativeEntries
+	}
+
+	return &Loader[V]{
+		cache:     cache,
+		load:      load,
+		loadAll:   loadAll,
+		options:   options,
+		group:     singleflight.Group{},
+		negative:  make(map[string]negativeEntry),
+		lastSweep: time.Time{},
+		mutex:     sync.Mutex{},
+		inflight:  make(map[string]*inflightLoad),
+		loadMutex: sync.Mutex{},
+		nodeId:    uuid.New().String(),
+		now:       time.Now,
+	}
+}
+
+func (l *Loader[V]) Get(ctx context.Context, key string) (V, error) {
+	var noop V
+
+	value, err := l.cache.Get(key)
+	if err == nil {
+		return value, nil
+	}
+
+	if !errors.Is(err, ErrKeyNotFound) {
+		return noop, err
+	}
+
+	if err := l.negativeHit(key); err != nil {
+		return noop, err
+	}
+
+	res, err := l.do(ctx, key, func(loadCtx context.Context) (interface{}, error) {
+		load := l.startLoad(key)
+		defer l.finishLoad(key)
+
+		value, err := l.load(loadCtx, key)
+		if err != nil {
+			if l.options.IsNotFound(err) {
+				l.rememberMiss(key, err)
+			}
+
+			return nil, err
+		}
+
+		// ошибка записи в кеш не должна ломать чтение: значение уже получено из источника
+		_ = l.store(load, func() error { return l.cache.Set(value) })
+
+		return value, nil
+	})
+	if err != nil {
+		return noop, err
+	}
+
+	value, ok := res.(V)
+	if !ok {
+		return noop, ErrKeyNotFound
+	}
+
+	return value, nil
+}
+
+// GetList возвращает страницу из кеша. Если кеш пуст, загружает полный список из источника.
+func (l *Loader[V]) GetList(ctx context.Context, limit uint, offset uint) ([]V, error) {
+	values, err := l.cache.GetList(limit, offset)
+	if err != nil || len(values) > 0 || offset > 0 || l.loadAll == nil {
+		return values, err
+	}
+
+	// источник был пуст при прошлой загрузке
+	if l.negativeHit(listKey) != nil {
+		return values, nil
+	}
+
+	_, err = l.do(ctx, listKey, func(loadCtx context.Context) (interface{}, error) {
+		load := l.startLoad(listKey)
+		defer l.finishLoad(listKey)
+
+		loaded, err := l.loadAll(loadCtx)
+		if err != nil {
+			return nil, err
+		}
+
+		if len(loaded) == 0 {
+			l.rememberMiss(listKey, ErrKeyNotFound)
+		}
+
+		return nil, l.store(load, func() error { return l.cache.Replace(loaded) })
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return l.cache.GetList(limit, offset)
+}
+
+// Find возвращает страницу подходящих под match значений. Пустой кеш предварительно загружается из источника.
+func (l *Loader[V]) Find(ctx context.Context, match func(value V) bool, limit uint, offset uint) ([]V, error) {
+	if _, err := l.GetList(ctx, 1, 0); err != nil {
+		return nil, err
+	}
+
+	return l.cache.Find(match, limit, offset)
+}
+
+// Delete удаляет ключ из кеша после удаления записи в источнике. Загрузки ключа и полного списка,
+// начатые до удаления, не запишут прочитанное ими значение в кеш.
+func (l *Loader[V]) Delete(key string) error {
+	l.loadMutex.Lock()
+	defer l.loadMutex.Unlock()
+
+	for _, inflightKey := range []string{key, listKey} {
+		if load, ok := l.inflight[inflightKey]; ok {
+			load.invalidated = true
+		}
+	}
+
+	return l.cache.Delete(key)
+}
+
+// Forget удаляет ключ из негативного кеша, например после создания записи. С LoaderOptions.Bus ключ
+// удаляется и из негативных кешей остальных реплик.
+func (l *Loader[V]) Forget(key string) error {
+	l.forget(key)
+
+	if l.options.Bus == nil {
+		return nil
+	}
+
+	if err := l.options.Bus.Publish(InvalidationMessage{Origin: l.nodeId, Key: key}); err != nil {
+		return fmt.Errorf("can't publish invalidation message: %w", err)
+	}
+
+	return nil
+}
+
+// Run удаляет из негативного кеша ключи из сообщений об инвалидации других реплик до отмены ctx.
+// Любое изменение записи означает, что она могла появиться в источнике.
+func (l *Loader[V]) Run(ctx context.Context) error {
+	if l.options.Bus == nil {
+		return nil
+	}
+
+	messages, err := l.options.Bus.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("can't subscribe to invalidation bus: %w", err)
+	}
+
+	for msg := range messages {
+		if msg.Origin != l.nodeId {
+			l.forget(msg.Key)
+		}
+	}
+
+	return nil
+}
+
+func (l *Loader[V]) forget(key string) {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	delete(l.negative, key)
+	delete(l.negative, listKey)
+}
+
+func (l *Loader[V]) startLoad(key string) *inflightLoad {
+	l.loadMutex.Lock()
+	defer l.loadMutex.Unlock()
+
+	load := &inflightLoad{invalidated: false}
+	l.inflight[key] = load
+
+	return load
+}
+
+func (l *Loader[V]) finishLoad(key string) {
+	l.loadMutex.Lock()
+	defer l.loadMutex.Unlock()
+
+	delete(l.inflight, key)
+}
+
+// store записывает загруженное значение в кеш, если ключ не удалили во время загрузки.
+func (l *Loader[V]) store(load *inflightLoad, write func() error) error {
+	l.loadMutex.Lock()
+	defer l.loadMutex.Unlock()
+
+	if load.invalidated {
+		return nil
+	}
+
+	return write()
+}
+
+// do объединяет одновременные загрузки key. Загрузка выполняется с контекстом, отвязанным от отмены ctx:
+// отмена запроса первого вызвавшего не должна возвращать context.Canceled остальным ожидающим.
+// Сам вызывающий перестает ждать при отмене своего ctx.
+func (l *Loader[V]) do(
+	ctx context.Context,
+	key string,
+	fn func(loadCtx context.Context) (interface{}, error),
+) (interface{}, error) {
+	result := l.group.DoChan(key, func() (interface{}, error) {
+		loadCtx, cancel := l.loadContext(ctx)
+		defer cancel()
+
+		return fn(loadCtx)
+	})
+
+	select {
+	case res := <-result:
+		return res.Val, res.Err
+	case <-ctx.Done():
+		return nil, ctx.Err()
+	}
+}
+
+func (l *Loader[V]) loadContext(ctx context.Context) (context.Context, context.CancelFunc) {
+	detached := detachedContext{parent: ctx}
+	if l.options.LoadTimeout <= 0 {
+		return context.WithCancel(detached)
+	}
+
+	return context.WithTimeout(detached, l.options.LoadTimeout)
+}
+
+func (l *Loader[V]) negativeHit(key string) error {
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	entry, ok := l.negative[key]
+	if !ok {
+		return nil
+	}
+
+	if l.now().After(entry.expiresAt) {
+		delete(l.negative, key)
+
+		return nil
+	}
+
+	return entry.err
+}
+
+func (l *Loader[V]) rememberMiss(key string, err error) {
+	if l.options.NegativeTtl <= 0 {
+		return
+	}
+
+	l.mutex.Lock()
+	defer l.mutex.Unlock()
+
+	now := l.now()
+	if now.Sub(l.lastSweep) >= l.options.NegativeTtl || len(l.negative) >= l.options.MaxNegativeEntries {
+		l.sweepExpired(now)
+	}
+
+	// при переполнении живыми записями освобождаем место под новую: это только оптимизация,
+	// потерянная запись обойдется одним лишним запросом к источнику
+	for evict := range l.negative {
+		if len(l.negative) < l.options.MaxNegativeEntries {
+			break
+		}
+
+		delete(l.negative, evict)
+	}
+
+	l.negative[key] = negativeEntry{err: err, expiresAt: now.Add(l.options.NegativeTtl)}
+}
+
+// sweepExpired удаляет истекшие записи негативного кеша. Вызывается под mutex.
+func (l *Loader[V]) sweepExpired(now time.Time) {
+	for key, entry := range l.negative {
+		if now.After(entry.expiresAt) {
+			delete(l.negative, key)
+		}
+	}
+
+	l.lastSweep = now
+}
+
+// detachedContext передает значения родительского контекста (трейс, логгер, request id), но не его отмену
+// и дедлайн. Аналог context.WithoutCancel, которого нет в используемой версии Go.
+type detachedContext struct {
+	parent context.Context
+}
+
+func (detachedContext) Deadline() (time.Time, bool) {
+	return time.Time{}, false
+}
+
+func (detachedContext) Done() <-chan struct{} {
+	return nil
+}
+
+func (detachedContext) Err() error {
+	return nil
+}
+
+func (c detachedContext) Value(key interface{}) interface{} {
+	return c.parent.Value(key)
+}
@@ -0,0 +1,346 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+var errSourceNotFound = errors.New("not found in source")
+
+func isSourceNotFound(err error) bool {
+	return errors.Is(err, errSourceNotFound)
+}
+
+func TestLoader_GetCoalescesMisses(t *testing.T) {
+	var calls int32
+
+	release := make(chan struct{})
+	c := NewMemoryEntityCache[Entity]()
+	l := NewLoader[Entity](
+		c,
+		func(ctx context.Context, key string) (Entity, error) {
+			atomic.AddInt32(&calls, 1)
+			<-release
+
+			return 7, nil
+		},
+		nil,
+		LoaderOptions{}, //nolint: exhaustruct
+	)
+
+	const callers = 50
+
+	var wg sync.WaitGroup
+
+	results := make([]Entity, callers)
+
+	for i := 0; i < callers; i++ {
+		i := i
+		wg.Add(1)
+
+		go func() {
+			defer wg.Done()
+
+			v, err := l.Get(context.Background(), "7")
+			assert.NoError(t, err)
+			results[i] = v
+		}()
+	}
+
+	// даем горутинам встать в ожидание singleflight
+	time.Sleep(time.Millisecond * 50)
+	close(release)
+	wg.Wait()
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	for _, v := range results {
+		assert.Equal(t, Entity(7), v)
+	}
+
+	cached, err := c.Get("7")
+	require.NoError(t, err)
+	assert.Equal(t, Entity(7), cached)
+}
+
+func TestLoader_GetNegativeCache(t *testing.T) {
+	var calls int32
+
+	now := time.Now()
+	l := NewLoader[Entity](
+		NewMemoryEntityCache[Entity](),
+		func(ctx context.Context, key string) (Entity, error) {
+			atomic.AddInt32(&calls, 1)
+
+			return 0, errSourceNotFound
+		},
+		nil,
+		LoaderOptions{NegativeTtl: time.Minute, IsNotFound: isSourceNotFound},
+	)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 3; i++ {
+		_, err := l.Get(context.Background(), "1")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, errSourceNotFound))
+	}
+
+	assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+
+	t.Run("expired", func(t *testing.T) {
+		now = now.Add(time.Minute * 2)
+
+		_, err := l.Get(context.Background(), "1")
+		require.Error(t, err)
+		assert.Equal(t, int32(2), atomic.LoadInt32(&calls))
+	})
+	t.Run("forget", func(t *testing.T) {
+		require.NoError(t, l.Forget("1"))
+
+		_, err := l.Get(context.Background(), "1")
+		require.Error(t, err)
+		assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+	})
+}
+
+func TestLoader_GetNegativeCacheIsBounded(t *testing.T) {
+	now := time.Now()
+	l := NewLoader[Entity](
+		NewMemoryEntityCache[Entity](),
+		func(ctx context.Context, key string) (Entity, error) {
+			return 0, errSourceNotFound
+		},
+		nil,
+		LoaderOptions{NegativeTtl: time.Minute, MaxNegativeEntries: 10, IsNotFound: isSourceNotFound},
+	)
+	l.now = func() time.Time { return now }
+
+	for i := 0; i < 100; i++ {
+		_, err := l.Get(context.Background(), strconv.Itoa(i))
+		require.Error(t, err)
+		assert.LessOrEqual(t, len(l.negative), 10)
+	}
+
+	t.Run("expired entries are swept", func(t *testing.T) {
+		now = now.Add(time.Minute * 2)
+
+		_, err := l.Get(context.Background(), "new")
+		require.Error(t, err)
+		assert.Len(t, l.negative, 1)
+	})
+}
+
+func TestLoader_GetDetachedFromCallerContext(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+
+	var loadDeadline time.Time
+
+	l := NewLoader[Entity](
+		NewMemoryEntityCache[Entity](),
+		func(ctx context.Context, key string) (Entity, error) {
+			loadDeadline, _ = ctx.Deadline()
+
+			close(started)
+			<-release
+
+			return 7, ctx.Err()
+		},
+		nil,
+		LoaderOptions{LoadTimeout: time.Minute}, //nolint: exhaustruct
+	)
+
+	firstCtx, cancelFirst := context.WithCancel(context.Background())
+	firstErr := make(chan error, 1)
+
+	go func() {
+		_, err := l.Get(firstCtx, "7")
+		firstErr <- err
+	}()
+
+	<-started
+
+	secondResult := make(chan Entity, 1)
+
+	go func() {
+		v, err := l.Get(context.Background(), "7")
+		assert.NoError(t, err)
+		secondResult <- v
+	}()
+
+	// первый вызвавший уходит, не дождавшись загрузки
+	cancelFirst()
+	assert.ErrorIs(t, <-firstErr, context.Canceled)
+
+	close(release)
+	assert.Equal(t, Entity(7), <-secondResult)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), loadDeadline, time.Second*5)
+}
+
+func TestLoader_DeleteDuringLoad(t *testing.T) {
+	started := make(chan struct{})
+	release := make(chan struct{})
+	c := NewMemoryEntityCache[Entity]()
+	l := NewLoader[Entity](
+		c,
+		func(ctx context.Context, key string) (Entity, error) {
+			close(started)
+			<-release
+
+			return 1, nil
+		},
+		nil,
+		LoaderOptions{}, //nolint: exhaustruct
+	)
+
+	done := make(chan struct{})
+
+	go func() {
+		defer close(done)
+
+		// значение прочитано до удаления и возвращается вызывающему, но не попадает в кеш
+		v, err := l.Get(context.Background(), "1")
+		assert.NoError(t, err)
+		assert.Equal(t, Entity(1), v)
+	}()
+
+	<-started
+	assert.ErrorIs(t, l.Delete("1"), ErrKeyNotFound)
+	close(release)
+	<-done
+
+	_, err := c.Get("1")
+	assert.ErrorIs(t, err, ErrKeyNotFound)
+}
+
+func TestLoader_ForgetOnOtherReplicas(t *testing.T) {
+	bus := NewMemoryInvalidationBus()
+	newReplica := func() *Loader[Entity] {
+		return NewLoader[Entity](
+			NewMemoryEntityCache[Entity](),
+			func(ctx context.Context, key string) (Entity, error) {
+				return 0, errSourceNotFound
+			},
+			nil,
+			LoaderOptions{NegativeTtl: time.Hour, IsNotFound: isSourceNotFound, Bus: bus}, //nolint: exhaustruct
+		)
+	}
+	first, second := newReplica(), newReplica()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	go func() { _ = second.Run(ctx) }()
+
+	require.Eventually(t, func() bool {
+		bus.mutex.RLock()
+		defer bus.mutex.RUnlock()
+
+		return len(bus.subscribers) == 1
+	}, time.Second, time.Millisecond)
+
+	for _, l := range []*Loader[Entity]{first, second} {
+		_, err := l.Get(context.Background(), "1")
+		require.ErrorIs(t, err, errSourceNotFound)
+		require.Error(t, l.negativeHit("1"))
+	}
+
+	// продукт восстановили на первой реплике
+	require.NoError(t, first.Forget("1"))
+
+	assert.Eventually(t, func() bool { return second.negativeHit("1") == nil }, time.Second, time.Millisecond)
+}
+
+func TestLoader_GetDoesNotCacheOtherErrors(t *testing.T) {
+	var calls int32
+
+	l := NewLoader[Entity](
+		NewMemoryEntityCache[Entity](),
+		func(ctx context.Context, key string) (Entity, error) {
+			atomic.AddInt32(&calls, 1)
+
+			return 0, errors.New("connection refused")
+		},
+		nil,
+		LoaderOptions{NegativeTtl: time.Minute, IsNotFound: isSourceNotFound},
+	)
+
+	for i := 0; i < 3; i++ {
+		_, err := l.Get(context.Background(), "1")
+		require.Error(t, err)
+	}
+
+	assert.Equal(t, int32(3), atomic.LoadInt32(&calls))
+}
+
+func TestLoader_GetList(t *testing.T) {
+	t.Run("loads empty cache", func(t *testing.T) {
+		var calls int32
+
+		values := []Entity{1, 2, 3, 4}
+		l := NewLoader[Entity](
+			NewMemoryEntityCache[Entity](),
+			nil,
+			func(ctx context.Context) ([]Entity, error) {
+				atomic.AddInt32(&calls, 1)
+
+				return values, nil
+			},
+			LoaderOptions{}, //nolint: exhaustruct
+		)
+
+		list, err := l.GetList(context.Background(), 2, 0)
+		require.NoError(t, err)
+		assert.Equal(t, values[:2], list)
+
+		list, err = l.GetList(context.Background(), 2, 2)
+		require.NoError(t, err)
+		assert.Equal(t, values[2:], list)
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+	t.Run("empty source is cached", func(t *testing.T) {
+		var calls int32
+
+		l := NewLoader[Entity](
+			NewMemoryEntityCache[Entity](),
+			nil,
+			func(ctx context.Context) ([]Entity, error) {
+				atomic.AddInt32(&calls, 1)
+
+				return nil, nil
+			},
+			LoaderOptions{NegativeTtl: time.Minute, IsNotFound: isSourceNotFound},
+		)
+
+		for i := 0; i < 3; i++ {
+			list, err := l.GetList(context.Background(), 10, 0)
+			require.NoError(t, err)
+			assert.Empty(t, list)
+		}
+
+		assert.Equal(t, int32(1), atomic.LoadInt32(&calls))
+	})
+	t.Run("source error", func(t *testing.T) {
+		l := NewLoader[Entity](
+			NewMemoryEntityCache[Entity](),
+			nil,
+			func(ctx context.Context) ([]Entity, error) {
+				return nil, errors.New("connection refused")
+			},
+			LoaderOptions{}, //nolint: exhaustruct
+		)
+
+		list, err := l.GetList(context.Background(), 10, 0)
+		require.Error(t, err)
+		assert.Nil(t, list)
+	})
+}
@@ -18,10 +18,13 @@ func NewMemoryEntityCache[V Hashable]() *MemoryEntityCache[V] {
 	}
 }
 
+// mutexLessSet заменяет значение на месте, сохраняя порядок GetList. Более старая версия значения игнорируется.
 func (c *MemoryEntityCache[V]) mutexLessSet(value V) error {
 	idx, ok := c.keyToIndexMap[value.Hash()]
 	if ok {
-		c.plainCache[idx] = value
+		if !outdated(value, c.plainCache[idx]) {
+			c.plainCache[idx] = value
+		}
 
 		return nil
 	}
@@ -96,6 +99,34 @@ func (c *MemoryEntityCache[V]) GetList(limit uint, offset uint) ([]V, error) {
 	return c.plainCache[start:end], nil
 }
 
+func (c *MemoryEntityCache[V]) Find(match func(value V) bool, limit uint, offset uint) ([]V, error) {
+	c.mutex.RLock()
+	defer c.mutex.RUnlock()
+
+	result := make([]V, 0)
+	skipped := uint(0)
+
+	for _, value := range c.plainCache {
+		if uint(len(result)) >= limit {
+			break
+		}
+
+		if !match(value) {
+			continue
+		}
+
+		if skipped < offset {
+			skipped++
+
+			continue
+		}
+
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
 func (c *MemoryEntityCache[V]) Replace(values []V) error {
 	c.mutex.Lock()
 	defer c.mutex.Unlock()
@@ -46,6 +46,39 @@ func TestMemoryEntityCache_Set(t *testing.T) {
 	})
 }
 
+type versionedEntity struct {
+	Id      string
+	Version int64
+}
+
+func (e *versionedEntity) Hash() string {
+	return e.Id
+}
+
+func (e *versionedEntity) CacheVersion() int64 {
+	return e.Version
+}
+
+func TestMemoryEntityCache_SetOutdated(t *testing.T) {
+	c := NewMemoryEntityCache[*versionedEntity]()
+
+	require.NoError(t, c.Set(&versionedEntity{Id: "1", Version: 2}))
+	require.NoError(t, c.Set(&versionedEntity{Id: "2", Version: 1}))
+
+	// запись, прочитавшая продукт до последнего изменения, завершилась позже него
+	require.NoError(t, c.Set(&versionedEntity{Id: "1", Version: 1}))
+
+	value, err := c.Get("1")
+	require.NoError(t, err)
+	assert.Equal(t, int64(2), value.Version)
+
+	require.NoError(t, c.Set(&versionedEntity{Id: "1", Version: 3}))
+
+	values, err := c.GetList(10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []*versionedEntity{{Id: "1", Version: 3}, {Id: "2", Version: 1}}, values)
+}
+
 func TestMemoryEntityCache_Get(t *testing.T) {
 	c := NewMemoryEntityCache[Entity]()
 
@@ -105,6 +138,35 @@ func TestMemoryEntityCache_GetList(t *testing.T) {
 	assert.Equal(t, values, list)
 }
 
+func TestMemoryEntityCache_Find(t *testing.T) {
+	c := NewMemoryEntityCache[Entity]()
+
+	values := []Entity{1, 2, 3, 4, 5, 6, 7, 8}
+	err := c.Replace(values)
+	require.NoError(t, err)
+
+	even := func(e Entity) bool { return e%2 == 0 }
+
+	testCases := []struct {
+		limit    uint
+		offset   uint
+		expected []Entity
+	}{
+		{limit: 10, offset: 0, expected: []Entity{2, 4, 6, 8}},
+		{limit: 2, offset: 0, expected: []Entity{2, 4}},
+		{limit: 2, offset: 1, expected: []Entity{4, 6}},
+		{limit: 10, offset: 3, expected: []Entity{8}},
+		{limit: 10, offset: 4, expected: []Entity{}},
+		{limit: 0, offset: 0, expected: []Entity{}},
+	}
+
+	for _, tc := range testCases {
+		list, err := c.Find(even, tc.limit, tc.offset)
+		require.NoError(t, err)
+		assert.Equal(t, tc.expected, list, "limit: %d, offset: %d", tc.limit, tc.offset)
+	}
+}
+
 func TestMemoryEntityCache_Replace(t *testing.T) {
 	c := NewMemoryEntityCache[Entity]()
 
@@ -0,0 +1,11 @@
+package cache
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var invalidationMessagesDropped = promauto.NewCounter(prometheus.CounterOpts{ //nolint: exhaustruct
+	Name: "cache_invalidation_messages_dropped_total",
+	Help: "Total number of cache invalidation messages dropped because a subscriber was not keeping up.",
+})
@@ -5,6 +5,7 @@
 package cache
 
 import (
+	context "context"
 	reflect "reflect"
 
 	gomock "github.com/golang/mock/gomock"
@@ -84,6 +85,21 @@ func (mr *MockEntityCacheMockRecorder[V]) Delete(key interface{}) *gomock.Call {
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Delete", reflect.TypeOf((*MockEntityCache[V])(nil).Delete), key)
 }
 
+// Find mocks base method.
+func (m *MockEntityCache[V]) Find(match func(V) bool, limit, offset uint) ([]V, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Find", match, limit, offset)
+	ret0, _ := ret[0].([]V)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Find indicates an expected call of Find.
+func (mr *MockEntityCacheMockRecorder[V]) Find(match, limit, offset interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Find", reflect.TypeOf((*MockEntityCache[V])(nil).Find), match, limit, offset)
+}
+
 // Get mocks base method.
 func (m *MockEntityCache[V]) Get(key string) (V, error) {
 	m.ctrl.T.Helper()
@@ -141,3 +157,55 @@ func (mr *MockEntityCacheMockRecorder[V]) Set(value interface{}) *gomock.Call {
 	mr.mock.ctrl.T.Helper()
 	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Set", reflect.TypeOf((*MockEntityCache[V])(nil).Set), value)
 }
+
+// MockInvalidationBus is a mock of InvalidationBus interface.
+type MockInvalidationBus struct {
+	ctrl     *gomock.Controller
+	recorder *MockInvalidationBusMockRecorder
+}
+
+// MockInvalidationBusMockRecorder is the mock recorder for MockInvalidationBus.
+type MockInvalidationBusMockRecorder struct {
+	mock *MockInvalidationBus
+}
+
+// NewMockInvalidationBus creates a new mock instance.
+func NewMockInvalidationBus(ctrl *gomock.Controller) *MockInvalidationBus {
+	mock := &MockInvalidationBus{ctrl: ctrl}
+	mock.recorder = &MockInvalidationBusMockRecorder{mock}
+	return mock
+}
+
+// EXPECT returns an object that allows the caller to indicate expected use.
+func (m *MockInvalidationBus) EXPECT() *MockInvalidationBusMockRecorder {
+	return m.recorder
+}
+
+// Publish mocks base method.
+func (m *MockInvalidationBus) Publish(msg InvalidationMessage) error {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Publish", msg)
+	ret0, _ := ret[0].(error)
+	return ret0
+}
+
+// Publish indicates an expected call of Publish.
+func (mr *MockInvalidationBusMockRecorder) Publish(msg interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Publish", reflect.TypeOf((*MockInvalidationBus)(nil).Publish), msg)
+}
+
+// Subscribe mocks base method.
+func (m *MockInvalidationBus) Subscribe(ctx context.Context) (<-chan InvalidationMessage, error) {
+	m.ctrl.T.Helper()
+	ret := m.ctrl.Call(m, "Subscribe", ctx)
+	ret0, _ := ret[0].(<-chan InvalidationMessage)
+	ret1, _ := ret[1].(error)
+	return ret0, ret1
+}
+
+// Subscribe indicates an expected call of Subscribe.
+func (mr *MockInvalidationBusMockRecorder) Subscribe(ctx interface{}) *gomock.Call {
+	mr.mock.ctrl.T.Helper()
+	return mr.mock.ctrl.RecordCallWithMethodType(mr.mock, "Subscribe", reflect.TypeOf((*MockInvalidationBus)(nil).Subscribe), ctx)
+}
@@ -0,0 +1,296 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"encoding/gob"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strconv"
+	"time"
+
+	"github.com/go-redis/redis/v8"
+)
+
+var (
+	_ EntityCache[Hashable] = (*RedisEntityCache[Hashable])(nil)
+	_ InvalidationBus       = (*RedisInvalidationBus)(nil)
+)
+
+// setIfNotOutdated записывает значение ARGV[3] в поле ARGV[1] хеша, если сохраненное значение не новее
+// версии ARGV[2]. Версия хранится в начале значения до двоеточия, см. encodeValue.
+var setIfNotOutdated = redis.NewScript(`
+local current = redis.call('HGET', KEYS[1], ARGV[1])
+if current then
+	local version = tonumber(string.match(current, '^(-?%d+):'))
+	if version and version > tonumber(ARGV[2]) then
+		return 0
+	end
+end
+redis.call('HSET', KEYS[1], ARGV[1], ARGV[3])
+return 1
+`)
+
+// RedisEntityCache общий для всех реплик кеш. Значения хранятся в хеше key в формате `<версия>:<gob>`,
+// полем служит Hash(). Каждая операция ограничена timeout, так как интерфейс EntityCache не принимает контекст.
+type RedisEntityCache[V Hashable] struct {
+	client  redis.UniversalClient
+	key     string
+	timeout time.Duration
+}
+
+func NewRedisEntityCache[V Hashable](client redis.UniversalClient, key string, timeout time.Duration) *RedisEntityCache[V] {
+	return &RedisEntityCache[V]{
+		client:  client,
+		key:     key,
+		timeout: timeout,
+	}
+}
+
+func (c *RedisEntityCache[V]) Get(key string) (V, error) {
+	ctx, cancel := operationContext(c.timeout)
+	defer cancel()
+
+	var noop V
+
+	data, err := c.client.HGet(ctx, c.key, key).Bytes()
+	if err != nil {
+		if errors.Is(err, redis.Nil) {
+			return noop, ErrKeyNotFound
+		}
+
+		return noop, fmt.Errorf("can't get value from redis: %w", err)
+	}
+
+	return decodeValue[V](data)
+}
+
+// Set атомарно сравнивает версии скриптом Lua: значение более старой версии, чем сохраненное, не записывается.
+func (c *RedisEntityCache[V]) Set(value V) error {
+	data, err := encodeValue(value)
+	if err != nil {
+		return err
+	}
+
+	ctx, cancel := operationContext(c.timeout)
+	defer cancel()
+
+	args := []interface{}{value.Hash(), versionOf(value), data}
+	if err := setIfNotOutdated.Run(ctx, c.client, []string{c.key}, args...).Err(); err != nil {
+		return fmt.Errorf("can't set value in redis: %w", err)
+	}
+
+	return nil
+}
+
+func (c *RedisEntityCache[V]) Delete(key string) error {
+	ctx, cancel := operationContext(c.timeout)
+	defer cancel()
+
+	deleted, err := c.client.HDel(ctx, c.key, key).Result()
+	if err != nil {
+		return fmt.Errorf("can't delete value from redis: %w", err)
+	}
+
+	if deleted == 0 {
+		return ErrKeyNotFound
+	}
+
+	return nil
+}
+
+func (c *RedisEntityCache[V]) GetList(limit uint, offset uint) ([]V, error) {
+	return c.Find(func(V) bool { return true }, limit, offset)
+}
+
+// Find читает и сортирует весь хеш за O(n) на каждый вызов, поэтому предназначен для редких вызовов, например
+// отладки. TieredEntityCache выполняет GetList и Find по локальному кешу и этот метод не вызывает.
+// Значения упорядочены по ключу.
+func (c *RedisEntityCache[V]) Find(match func(value V) bool, limit uint, offset uint) ([]V, error) {
+	ctx, cancel := operationContext(c.timeout)
+	defer cancel()
+
+	entries, err := c.client.HGetAll(ctx, c.key).Result()
+	if err != nil {
+		return nil, fmt.Errorf("can't get values from redis: %w", err)
+	}
+
+	keys := make([]string, 0, len(entries))
+	for key := range entries {
+		keys = append(keys, key)
+	}
+
+	sort.Strings(keys)
+
+	result := make([]V, 0)
+	skipped := uint(0)
+
+	for _, key := range keys {
+		if uint(len(result)) >= limit {
+			break
+		}
+
+		value, err := decodeValue[V]([]byte(entries[key]))
+		if err != nil {
+			return nil, err
+		}
+
+		if !match(value) {
+			continue
+		}
+
+		if skipped < offset {
+			skipped++
+
+			continue
+		}
+
+		result = append(result, value)
+	}
+
+	return result, nil
+}
+
+// Replace атомарно заменяет содержимое хеша в транзакции MULTI/EXEC.
+func (c *RedisEntityCache[V]) Replace(values []V) error {
+	fields := make([]interface{}, 0, len(values)*2)
+
+	for _, value := range values {
+		data, err := encodeValue(value)
+		if err != nil {
+			return err
+		}
+
+		fields = append(fields, value.Hash(), data)
+	}
+
+	ctx, cancel := operationContext(c.timeout)
+	defer cancel()
+
+	_, err := c.client.TxPipelined(ctx, func(pipe redis.Pipeliner) error {
+		pipe.Del(ctx, c.key)
+
+		if len(fields) > 0 {
+			pipe.HSet(ctx, c.key, fields...)
+		}
+
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("can't replace values in redis: %w", err)
+	}
+
+	return nil
+}
+
+// operationContext контекст одной операции с Redis. timeout <= 0 — без ограничения.
+func operationContext(timeout time.Duration) (context.Context, context.CancelFunc) {
+	if timeout <= 0 {
+		return context.WithCancel(context.Background())
+	}
+
+	return context.WithTimeout(context.Background(), timeout)
+}
+
+// encodeValue кодирует значение в gob с префиксом версии, по которому сравнивает версии setIfNotOutdated.
+func encodeValue[V Hashable](value V) ([]byte, error) {
+	var buf bytes.Buffer
+
+	buf.WriteString(strconv.FormatInt(versionOf(value), 10))
+	buf.WriteByte(':')
+
+	if err := gob.NewEncoder(&buf).Encode(value); err != nil {
+		return nil, fmt.Errorf("can't encode value %s: %w", value.Hash(), err)
+	}
+
+	return buf.Bytes(), nil
+}
+
+func decodeValue[V Hashable](data []byte) (V, error) {
+	var value V
+
+	_, encoded, ok := bytes.Cut(data, []byte{':'})
+	if !ok {
+		return value, errors.New("can't decode value: version prefix is missing")
+	}
+
+	if err := gob.NewDecoder(bytes.NewReader(encoded)).Decode(&value); err != nil {
+		return value, fmt.Errorf("can't decode value: %w", err)
+	}
+
+	return value, nil
+}
+
+// RedisInvalidationBus реализация InvalidationBus поверх Redis pub/sub. Доставка не гарантируется:
+// пропущенные сообщения компенсируются периодической синхронизацией кеша.
+type RedisInvalidationBus struct {
+	client  redis.UniversalClient
+	channel string
+	timeout time.Duration
+}
+
+func NewRedisInvalidationBus(client redis.UniversalClient, channel string, timeout time.Duration) *RedisInvalidationBus {
+	return &RedisInvalidationBus{
+		client:  client,
+		channel: channel,
+		timeout: timeout,
+	}
+}
+
+func (b *RedisInvalidationBus) Publish(msg InvalidationMessage) error {
+	data, err := json.Marshal(msg)
+	if err != nil {
+		return fmt.Errorf("can't encode invalidation message: %w", err)
+	}
+
+	ctx, cancel := operationContext(b.timeout)
+	defer cancel()
+
+	if err := b.client.Publish(ctx, b.channel, data).Err(); err != nil {
+		return fmt.Errorf("can't publish invalidation message: %w", err)
+	}
+
+	return nil
+}
+
+// Subscribe дожидается подтверждения подписки, чтобы сообщения, опубликованные после возврата, не терялись.
+func (b *RedisInvalidationBus) Subscribe(ctx context.Context) (<-chan InvalidationMessage, error) {
+	pubsub := b.client.Subscribe(ctx, b.channel)
+
+	if _, err := pubsub.Receive(ctx); err != nil {
+		_ = pubsub.Close()
+
+		return nil, fmt.Errorf("can't subscribe to redis channel %s: %w", b.channel, err)
+	}
+
+	messages := make(chan InvalidationMessage, defaultSubscriberBufferSize)
+
+	go func() {
+		defer close(messages)
+		defer pubsub.Close()
+
+		incoming := pubsub.Channel()
+
+		for {
+			select {
+			case <-ctx.Done():
+				return
+			case raw, ok := <-incoming:
+				if !ok {
+					return
+				}
+
+				var msg InvalidationMessage
+				if err := json.Unmarshal([]byte(raw.Payload), &msg); err != nil {
+					continue
+				}
+
+				deliver(messages, msg)
+			}
+		}
+	}()
+
+	return messages, nil
+}
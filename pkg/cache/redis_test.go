@@ -0,0 +1,48 @@
+package cache
+
+import (
+	"strings"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type record struct {
+	Id   string
+	Tags []string
+}
+
+func (r *record) Hash() string {
+	return r.Id
+}
+
+func TestRedisValueEncoding(t *testing.T) {
+	value := &record{Id: "1", Tags: []string{"sale"}}
+
+	data, err := encodeValue(value)
+	require.NoError(t, err)
+
+	decoded, err := decodeValue[*record](data)
+	require.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	_, err = decodeValue[*record]([]byte("garbage"))
+	assert.Error(t, err)
+}
+
+func TestRedisValueEncoding_VersionPrefix(t *testing.T) {
+	value := &versionedEntity{Id: "1", Version: 42}
+
+	data, err := encodeValue(value)
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "42:"))
+
+	decoded, err := decodeValue[*versionedEntity](data)
+	require.NoError(t, err)
+	assert.Equal(t, value, decoded)
+
+	data, err = encodeValue(&record{Id: "1", Tags: nil})
+	require.NoError(t, err)
+	assert.True(t, strings.HasPrefix(string(data), "0:"))
+}
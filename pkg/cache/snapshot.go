@@ -0,0 +1,140 @@
+package cache
+
+import (
+	"compress/gzip"
+	"context"
+	"encoding/gob"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"time"
+)
+
+const snapshotVersion = 1
+
+type snapshot[V Hashable] struct {
+	Version int
+	SavedAt time.Time
+	Values  []V
+}
+
+// WriteSnapshot пишет содержимое кеша в w в формате gob со сжатием gzip.
+func (c *MemoryEntityCache[V]) WriteSnapshot(w io.Writer) error {
+	c.mutex.RLock()
+	values := make([]V, len(c.plainCache))
+	copy(values, c.plainCache)
+	c.mutex.RUnlock()
+
+	zw := gzip.NewWriter(w)
+
+	err := gob.NewEncoder(zw).Encode(snapshot[V]{
+		Version: snapshotVersion,
+		SavedAt: time.Now().UTC(),
+		Values:  values,
+	})
+	if err != nil {
+		return fmt.Errorf("can't encode snapshot: %w", err)
+	}
+
+	return zw.Close()
+}
+
+// ReadSnapshot заменяет содержимое кеша данными из снапшота, записанного WriteSnapshot.
+func (c *MemoryEntityCache[V]) ReadSnapshot(r io.Reader) error {
+	zr, err := gzip.NewReader(r)
+	if err != nil {
+		return fmt.Errorf("can't read snapshot: %w", err)
+	}
+	defer zr.Close()
+
+	var s snapshot[V]
+
+	if err := gob.NewDecoder(zr).Decode(&s); err != nil {
+		return fmt.Errorf("can't decode snapshot: %w", err)
+	}
+
+	if s.Version != snapshotVersion {
+		return fmt.Errorf("unsupported snapshot version %d", s.Version)
+	}
+
+	return c.Replace(s.Values)
+}
+
+// Snapshotter сохраняет MemoryEntityCache в файл path и восстанавливает его оттуда при старте.
+type Snapshotter[V Hashable] struct {
+	cache *MemoryEntityCache[V]
+	path  string
+}
+
+func NewSnapshotter[V Hashable](cache *MemoryEntityCache[V], path string) *Snapshotter[V] {
+	return &Snapshotter[V]{
+		cache: cache,
+		path:  path,
+	}
+}
+
+// Load загружает снапшот в кеш. Если файла нет, возвращается ошибка, удовлетворяющая errors.Is(err, fs.ErrNotExist).
+func (s *Snapshotter[V]) Load() error {
+	f, err := os.Open(s.path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	return s.cache.ReadSnapshot(f)
+}
+
+// Save атомарно перезаписывает файл снапшота: данные пишутся во временный файл, который затем переименовывается.
+func (s *Snapshotter[V]) Save() error {
+	tmp, err := os.CreateTemp(filepath.Dir(s.path), filepath.Base(s.path)+".*.tmp")
+	if err != nil {
+		return fmt.Errorf("can't create snapshot file: %w", err)
+	}
+	defer os.Remove(tmp.Name()) //nolint: errcheck // после успешного rename файла уже нет
+
+	if err := s.cache.WriteSnapshot(tmp); err != nil {
+		_ = tmp.Close()
+
+		return err
+	}
+
+	if err := tmp.Sync(); err != nil {
+		_ = tmp.Close()
+
+		return fmt.Errorf("can't sync snapshot file: %w", err)
+	}
+
+	if err := tmp.Close(); err != nil {
+		return fmt.Errorf("can't close snapshot file: %w", err)
+	}
+
+	return os.Rename(tmp.Name(), s.path)
+}
+
+// Run периодически сохраняет снапшот до отмены ctx. Ошибки сохранения передаются в onError.
+// При interval <= 0 сразу возвращается: снапшот сохраняется только в Close.
+func (s *Snapshotter[V]) Run(ctx context.Context, interval time.Duration, onError func(err error)) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			if err := s.Save(); err != nil && onError != nil {
+				onError(err)
+			}
+		}
+	}
+}
+
+// Close сохраняет снапшот при остановке приложения.
+func (s *Snapshotter[V]) Close() error {
+	return s.Save()
+}
@@ -0,0 +1,98 @@
+package cache
+
+import (
+	"bytes"
+	"context"
+	"errors"
+	"io/fs"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+func TestMemoryEntityCache_Snapshot(t *testing.T) {
+	c := NewMemoryEntityCache[Entity]()
+	values := []Entity{1, 2, 3, 4, 5}
+	require.NoError(t, c.Replace(values))
+
+	var buf bytes.Buffer
+	require.NoError(t, c.WriteSnapshot(&buf))
+
+	restored := NewMemoryEntityCache[Entity]()
+	require.NoError(t, restored.ReadSnapshot(&buf))
+	assert.Equal(t, values, restored.plainCache)
+
+	for i, value := range values {
+		assert.Equal(t, i, restored.keyToIndexMap[value.Hash()])
+	}
+}
+
+func TestMemoryEntityCache_ReadSnapshotCorrupted(t *testing.T) {
+	c := NewMemoryEntityCache[Entity]()
+	require.NoError(t, c.Replace([]Entity{1, 2}))
+
+	err := c.ReadSnapshot(bytes.NewBufferString("definitely not a snapshot"))
+	require.Error(t, err)
+	assert.Equal(t, []Entity{1, 2}, c.plainCache)
+}
+
+func TestSnapshotter(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "products.snapshot")
+
+	t.Run("load missing file", func(t *testing.T) {
+		s := NewSnapshotter[Entity](NewMemoryEntityCache[Entity](), path)
+
+		err := s.Load()
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, fs.ErrNotExist))
+	})
+	t.Run("save and load", func(t *testing.T) {
+		c := NewMemoryEntityCache[Entity]()
+		require.NoError(t, c.Replace([]Entity{3, 2, 1}))
+		require.NoError(t, NewSnapshotter[Entity](c, path).Close())
+
+		restored := NewMemoryEntityCache[Entity]()
+		require.NoError(t, NewSnapshotter[Entity](restored, path).Load())
+		assert.Equal(t, []Entity{3, 2, 1}, restored.plainCache)
+
+		entries, err := os.ReadDir(filepath.Dir(path))
+		require.NoError(t, err)
+		assert.Len(t, entries, 1, "temporary files must be cleaned up")
+	})
+	t.Run("periodic save", func(t *testing.T) {
+		periodicPath := filepath.Join(t.TempDir(), "periodic.snapshot")
+		c := NewMemoryEntityCache[Entity]()
+		require.NoError(t, c.Replace([]Entity{1}))
+
+		ctx, cancel := context.WithCancel(context.Background())
+		defer cancel()
+
+		go NewSnapshotter[Entity](c, periodicPath).Run(ctx, time.Millisecond*10, func(err error) {
+			t.Errorf("unexpected snapshot error: %v", err)
+		})
+
+		require.Eventually(t, func() bool {
+			_, err := os.Stat(periodicPath)
+
+			return err == nil
+		}, time.Second, time.Millisecond*5)
+	})
+	t.Run("save only on close", func(t *testing.T) {
+		closePath := filepath.Join(t.TempDir(), "close.snapshot")
+		s := NewSnapshotter[Entity](NewMemoryEntityCache[Entity](), closePath)
+
+		s.Run(context.Background(), 0, nil)
+
+		_, err := os.Stat(closePath)
+		require.ErrorIs(t, err, fs.ErrNotExist)
+
+		require.NoError(t, s.Close())
+
+		_, err = os.Stat(closePath)
+		require.NoError(t, err)
+	})
+}
@@ -0,0 +1,137 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"fmt"
+
+	"github.com/google/uuid"
+)
+
+var _ EntityCache[Hashable] = (*TieredEntityCache[Hashable])(nil)
+
+// TieredEntityCache двухуровневый кеш: локальный MemoryEntityCache перед общим для всех реплик remote кешем.
+// Чтение идет из локального кеша, запись — в оба уровня с рассылкой инвалидации остальным репликам через bus.
+type TieredEntityCache[V Hashable] struct {
+	local  *MemoryEntityCache[V]
+	remote EntityCache[V]
+	bus    InvalidationBus
+	nodeId string
+}
+
+func NewTieredEntityCache[V Hashable](
+	local *MemoryEntityCache[V],
+	remote EntityCache[V],
+	bus InvalidationBus,
+) *TieredEntityCache[V] {
+	return &TieredEntityCache[V]{
+		local:  local,
+		remote: remote,
+		bus:    bus,
+		nodeId: uuid.New().String(),
+	}
+}
+
+func (c *TieredEntityCache[V]) Get(key string) (V, error) {
+	value, err := c.local.Get(key)
+	if err == nil {
+		return value, nil
+	}
+
+	value, err = c.remote.Get(key)
+	if err != nil {
+		return value, err
+	}
+
+	return value, c.local.Set(value)
+}
+
+func (c *TieredEntityCache[V]) Set(value V) error {
+	if err := c.remote.Set(value); err != nil {
+		return fmt.Errorf("can't set value in remote cache: %w", err)
+	}
+
+	if err := c.local.Set(value); err != nil {
+		return err
+	}
+
+	return c.publish(value.Hash())
+}
+
+func (c *TieredEntityCache[V]) Delete(key string) error {
+	remoteErr := c.remote.Delete(key)
+	if remoteErr != nil && !errors.Is(remoteErr, ErrKeyNotFound) {
+		return fmt.Errorf("can't delete value from remote cache: %w", remoteErr)
+	}
+
+	localErr := c.local.Delete(key)
+
+	if err := c.publish(key); err != nil {
+		return err
+	}
+
+	if remoteErr != nil && localErr != nil {
+		return ErrKeyNotFound
+	}
+
+	return nil
+}
+
+// GetList и Find читают только локальный кеш: выборка из remote кеша требует чтения его целиком.
+func (c *TieredEntityCache[V]) GetList(limit uint, offset uint) ([]V, error) {
+	return c.local.GetList(limit, offset)
+}
+
+func (c *TieredEntityCache[V]) Find(match func(value V) bool, limit uint, offset uint) ([]V, error) {
+	return c.local.Find(match, limit, offset)
+}
+
+// Replace заменяет содержимое обоих уровней. Инвалидация не рассылается:
+// каждая реплика синхронизирует свой локальный кеш самостоятельно.
+func (c *TieredEntityCache[V]) Replace(values []V) error {
+	if err := c.remote.Replace(values); err != nil {
+		return fmt.Errorf("can't replace values in remote cache: %w", err)
+	}
+
+	return c.local.Replace(values)
+}
+
+// Run слушает сообщения об инвалидации от других реплик до отмены ctx.
+func (c *TieredEntityCache[V]) Run(ctx context.Context) error {
+	messages, err := c.bus.Subscribe(ctx)
+	if err != nil {
+		return fmt.Errorf("can't subscribe to invalidation bus: %w", err)
+	}
+
+	for msg := range messages {
+		if msg.Origin == c.nodeId {
+			continue
+		}
+
+		c.invalidate(msg.Key)
+	}
+
+	return nil
+}
+
+// invalidate заменяет локальную копию актуальной версией из remote кеша на том же месте, чтобы порядок
+// GetList не менялся и клиенты, листающие список по offset, не пропускали и не получали элементы дважды.
+// Запись, которой нет в remote кеше или которую не удалось прочитать, удаляется.
+func (c *TieredEntityCache[V]) invalidate(key string) {
+	value, err := c.remote.Get(key)
+	if err != nil {
+		_ = c.local.Delete(key)
+
+		return
+	}
+
+	_ = c.local.Set(value)
+}
+
+func (c *TieredEntityCache[V]) publish(key string) error {
+	if err := c.bus.Publish(InvalidationMessage{Origin: c.nodeId, Key: key}); err != nil {
+		return fmt.Errorf("can't publish invalidation message: %w", err)
+	}
+
+	return nil
+}
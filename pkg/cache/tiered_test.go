@@ -0,0 +1,160 @@
+package cache
+
+import (
+	"context"
+	"errors"
+	"strconv"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type versioned struct {
+	id      int
+	version int
+}
+
+func (v versioned) Hash() string {
+	return strconv.Itoa(v.id)
+}
+
+func newTieredReplicas(
+	t *testing.T,
+	count int,
+) ([]*TieredEntityCache[versioned], *MemoryEntityCache[versioned]) {
+	t.Helper()
+
+	ctx, cancel := context.WithCancel(context.Background())
+	t.Cleanup(cancel)
+
+	remote := NewMemoryEntityCache[versioned]()
+	bus := NewMemoryInvalidationBus()
+	replicas := make([]*TieredEntityCache[versioned], count)
+
+	for i := range replicas {
+		replica := NewTieredEntityCache[versioned](NewMemoryEntityCache[versioned](), remote, bus)
+		replicas[i] = replica
+
+		go func() { _ = replica.Run(ctx) }()
+	}
+
+	// ждем, пока все реплики подпишутся на шину
+	require.Eventually(t, func() bool {
+		bus.mutex.RLock()
+		defer bus.mutex.RUnlock()
+
+		return len(bus.subscribers) == count
+	}, time.Second, time.Millisecond)
+
+	return replicas, remote
+}
+
+func TestTieredEntityCache_Get(t *testing.T) {
+	t.Run("local hit", func(t *testing.T) {
+		replicas, _ := newTieredReplicas(t, 1)
+		c := replicas[0]
+		require.NoError(t, c.local.Set(versioned{id: 1, version: 1}))
+
+		v, err := c.Get("1")
+		require.NoError(t, err)
+		assert.Equal(t, versioned{id: 1, version: 1}, v)
+	})
+	t.Run("remote hit fills local", func(t *testing.T) {
+		replicas, remote := newTieredReplicas(t, 1)
+		c := replicas[0]
+		require.NoError(t, remote.Set(versioned{id: 1, version: 1}))
+
+		v, err := c.Get("1")
+		require.NoError(t, err)
+		assert.Equal(t, versioned{id: 1, version: 1}, v)
+
+		local, err := c.local.Get("1")
+		require.NoError(t, err)
+		assert.Equal(t, v, local)
+	})
+	t.Run("miss", func(t *testing.T) {
+		replicas, _ := newTieredReplicas(t, 1)
+
+		_, err := replicas[0].Get("1")
+		require.Error(t, err)
+		assert.True(t, errors.Is(err, ErrKeyNotFound))
+	})
+}
+
+func TestTieredEntityCache_SetInvalidatesOtherReplicas(t *testing.T) {
+	replicas, remote := newTieredReplicas(t, 3)
+	initial := []versioned{{id: 1, version: 1}, {id: 2, version: 1}}
+
+	for _, replica := range replicas {
+		require.NoError(t, replica.Replace(initial))
+	}
+
+	updated := versioned{id: 1, version: 2}
+	require.NoError(t, replicas[0].Set(updated))
+
+	v, err := remote.Get("1")
+	require.NoError(t, err)
+	assert.Equal(t, updated, v)
+
+	for _, replica := range replicas {
+		replica := replica
+		require.Eventually(t, func() bool {
+			v, err := replica.local.Get("1")
+
+			return err == nil && v == updated
+		}, time.Second, time.Millisecond)
+	}
+}
+
+func TestTieredEntityCache_InvalidateKeepsOrder(t *testing.T) {
+	replicas, _ := newTieredReplicas(t, 2)
+	initial := []versioned{{id: 1, version: 1}, {id: 2, version: 1}, {id: 3, version: 1}}
+
+	for _, replica := range replicas {
+		require.NoError(t, replica.Replace(initial))
+	}
+
+	require.NoError(t, replicas[0].Set(versioned{id: 1, version: 2}))
+
+	expected := []versioned{{id: 1, version: 2}, {id: 2, version: 1}, {id: 3, version: 1}}
+
+	require.Eventually(t, func() bool {
+		list, err := replicas[1].GetList(10, 0)
+
+		return err == nil && assert.ObjectsAreEqual(expected, list)
+	}, time.Second, time.Millisecond)
+}
+
+func TestTieredEntityCache_DeleteInvalidatesOtherReplicas(t *testing.T) {
+	replicas, remote := newTieredReplicas(t, 2)
+	initial := []versioned{{id: 1, version: 1}, {id: 2, version: 1}}
+
+	for _, replica := range replicas {
+		require.NoError(t, replica.Replace(initial))
+	}
+
+	require.NoError(t, replicas[0].Delete("2"))
+
+	_, err := remote.Get("2")
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+
+	require.Eventually(t, func() bool {
+		_, err := replicas[1].local.Get("2")
+
+		return errors.Is(err, ErrKeyNotFound)
+	}, time.Second, time.Millisecond)
+
+	list, err := replicas[1].GetList(10, 0)
+	require.NoError(t, err)
+	assert.Equal(t, []versioned{{id: 1, version: 1}}, list)
+}
+
+func TestTieredEntityCache_DeleteNotFound(t *testing.T) {
+	replicas, _ := newTieredReplicas(t, 1)
+
+	err := replicas[0].Delete("1")
+	require.Error(t, err)
+	assert.True(t, errors.Is(err, ErrKeyNotFound))
+}
@@ -0,0 +1,21 @@
+package cache
+
+// Versioned значение с версией, которая растет с каждым изменением. Кеш не заменяет значение более старой
+// версией: запись, прочитавшая данные раньше, но завершившаяся позже, не затрет более новое значение.
+type Versioned interface {
+	CacheVersion() int64
+}
+
+// versionOf версия значения. Значения без версии считаются версией 0 и заменяются всегда.
+func versionOf(value interface{}) int64 {
+	if versioned, ok := value.(Versioned); ok {
+		return versioned.CacheVersion()
+	}
+
+	return 0
+}
+
+// outdated проверяет, что value старее existing и не должно его заменять.
+func outdated[V Hashable](value V, existing V) bool {
+	return versionOf(value) < versionOf(existing)
+}
@@ -0,0 +1,71 @@
+package actor
+
+import (
+	"context"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+// MetadataKey ключ метаданных с идентификатором инициатора запроса.
+// Через grpc-gateway передается HTTP-заголовком `Grpc-Metadata-X-Actor`.
+const MetadataKey = "x-actor"
+
+// maxActorLength ограничивает длину значения, которое сохраняется в аудит.
+const maxActorLength = 256
+
+type actorKey struct{}
+
+func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(contextWithActorFromMetadata(ctx), req)
+	}
+}
+
+func StreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = contextWithActorFromMetadata(stream.Context())
+
+		return handler(srv, wrapped)
+	}
+}
+
+// NewContext возвращает контекст с инициатором запроса.
+func NewContext(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+func FromContext(ctx context.Context) string {
+	actor, ok := ctx.Value(actorKey{}).(string)
+	if !ok {
+		return ""
+	}
+
+	return actor
+}
+
+// contextWithActorFromMetadata не перезаписывает инициатора, уже определенного аутентификацией.
+func contextWithActorFromMetadata(ctx context.Context) context.Context {
+	if FromContext(ctx) != "" {
+		return ctx
+	}
+
+	md, ok := metadata.FromIncomingContext(ctx)
+	if !ok {
+		return ctx
+	}
+
+	values := md.Get(MetadataKey)
+	if len(values) == 0 || values[0] == "" {
+		return ctx
+	}
+
+	actor := values[0]
+	if len(actor) > maxActorLength {
+		actor = actor[:maxActorLength]
+	}
+
+	return NewContext(ctx, actor)
+}
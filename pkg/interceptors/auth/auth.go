@@ -0,0 +1,267 @@
+package auth
+
+import (
+	"context"
+	"crypto/sha256"
+	"crypto/subtle"
+	"errors"
+	"strings"
+
+	"github.com/golang-jwt/jwt/v4"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/actor"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+const (
+	// AuthorizationMetadataKey ключ метаданных с JWT в формате `Bearer <token>`
+	AuthorizationMetadataKey = "authorization"
+	// APIKeyMetadataKey ключ метаданных со статическим API ключом.
+	// Через grpc-gateway передается HTTP-заголовком `X-Api-Key`
+	APIKeyMetadataKey = "x-api-key"
+
+	MethodJWT    = "jwt"
+	MethodAPIKey = "api-key"
+)
+
+var (
+	errMissingCredentials = commonerr.NewUnauthenticatedError("missing credentials").WithReason("MISSING_CREDENTIALS", nil)
+	errInvalidCredentials = commonerr.NewUnauthenticatedError("invalid credentials").WithReason("INVALID_CREDENTIALS", nil)
+
+	// jwtValidMethods только асимметричные алгоритмы: проверяющей стороне не нужен секрет издателя
+	jwtValidMethods = []string{"RS256", "RS384", "RS512", "PS256", "PS384", "PS512", "ES256", "ES384", "ES512", "EdDSA"}
+)
+
+type principalKey struct{}
+
+// Principal аутентифицированный клиент.
+type Principal struct {
+	// Subject sub из JWT или имя API ключа
+	Subject string
+	// Method способ аутентификации: MethodJWT или MethodAPIKey
+	Method string
+	// Roles роли из claim `roles` JWT
+	Roles []string
+	// Scopes права из claim `scope` JWT, разделенные пробелом
+	Scopes []string
+}
+
+type Config struct {
+	// APIKeys имя ключа -> значение ключа
+	APIKeys map[string]string
+	// JWTKeys открытые ключи проверки подписи по kid. Ключ с пустым kid используется для токенов без kid
+	JWTKeys map[string]interface{}
+	// JWTIssuer, JWTAudience ожидаемые iss и aud. Пустое значение не проверяется
+	JWTIssuer   string
+	JWTAudience string
+	// ExemptMethods полные имена методов gRPC, доступных без аутентификации, например health check
+	ExemptMethods []string
+}
+
+type Authenticator struct {
+	// apiKeys sha256 ключа -> имя. Хеши одной длины сравниваются за постоянное время
+	apiKeys  map[[sha256.Size]byte]string
+	jwtKeys  map[string]interface{}
+	issuer   string
+	audience string
+	exempt   map[string]struct{}
+	logger   logging.ContextLogger
+}
+
+func New(cfg Config, logger logging.ContextLogger) *Authenticator {
+	apiKeys := make(map[[sha256.Size]byte]string, len(cfg.APIKeys))
+	for name, key := range cfg.APIKeys {
+		apiKeys[sha256.Sum256([]byte(key))] = name
+	}
+
+	exempt := make(map[string]struct{}, len(cfg.ExemptMethods))
+	for _, method := range cfg.ExemptMethods {
+		exempt[method] = struct{}{}
+	}
+
+	return &Authenticator{
+		apiKeys:  apiKeys,
+		jwtKeys:  cfg.JWTKeys,
+		issuer:   cfg.JWTIssuer,
+		audience: cfg.JWTAudience,
+		exempt:   exempt,
+		logger:   logger,
+	}
+}
+
+// Authenticate проверяет JWT из `authorization` или API ключ из `x-api-key`.
+func (a *Authenticator) Authenticate(ctx context.Context) (*Principal, error) {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get(AuthorizationMetadataKey); len(values) > 0 && values[0] != "" {
+		token, ok := cutPrefixFold(values[0], "Bearer ")
+		if !ok {
+			return nil, errInvalidCredentials
+		}
+
+		return a.authenticateJWT(token)
+	}
+
+	if values := md.Get(APIKeyMetadataKey); len(values) > 0 && values[0] != "" {
+		return a.authenticateAPIKey(values[0])
+	}
+
+	return nil, errMissingCredentials
+}
+
+func UnaryServerInterceptor(a *Authenticator) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, err := a.authenticateCall(ctx, info.FullMethod)
+		if err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(a *Authenticator) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, err := a.authenticateCall(stream.Context(), info.FullMethod)
+		if err != nil {
+			return err
+		}
+
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
+}
+
+func NewContext(ctx context.Context, principal *Principal) context.Context {
+	return context.WithValue(ctx, principalKey{}, principal)
+}
+
+// FromContext возвращает аутентифицированного клиента или nil для методов без аутентификации.
+func FromContext(ctx context.Context) *Principal {
+	principal, ok := ctx.Value(principalKey{}).(*Principal)
+	if !ok {
+		return nil
+	}
+
+	return principal
+}
+
+// ExtractPrincipal поля клиента для logging.UnaryServerInterceptor. Логирование идет в цепочке раньше
+// аутентификации, поэтому клиент берется из места, зарезервированного PrincipalHolderUnaryServerInterceptor.
+func ExtractPrincipal(ctx context.Context) []any {
+	principal := FromContext(ctx)
+	if holder := holderFromContext(ctx); principal == nil && holder != nil {
+		principal = holder.get()
+	}
+
+	if principal == nil {
+		return []any{}
+	}
+
+	return []any{"grpc.principal", principal.Subject, "grpc.authMethod", principal.Method}
+}
+
+// authenticateCall кладет клиента в контекст, логгер и аудит. Инициатором изменений в аудите становится
+// аутентифицированный клиент, а не значение x-actor от вызывающей стороны.
+func (a *Authenticator) authenticateCall(ctx context.Context, fullMethod string) (context.Context, error) {
+	if _, ok := a.exempt[fullMethod]; ok {
+		return ctx, nil
+	}
+
+	principal, err := a.Authenticate(ctx)
+	if err != nil {
+		_, logger := a.logger.FromContext(ctx)
+		logger.Warnw("Authentication failed", "method", fullMethod, "err", err)
+
+		return nil, commonerr.GrpcErrHandler(ctx, err, nil)
+	}
+
+	if holder := holderFromContext(ctx); holder != nil {
+		holder.set(principal)
+	}
+
+	ctx, _ = a.logger.FromContext(ctx, "principal", principal.Subject)
+	ctx = NewContext(ctx, principal)
+
+	return actor.NewContext(ctx, principal.Subject), nil
+}
+
+type jwtClaims struct {
+	Roles []string `json:"roles"`
+	Scope string   `json:"scope"`
+	jwt.RegisteredClaims
+}
+
+func (a *Authenticator) authenticateJWT(tokenString string) (*Principal, error) {
+	if len(a.jwtKeys) == 0 {
+		return nil, errInvalidCredentials
+	}
+
+	var claims jwtClaims
+
+	_, err := jwt.ParseWithClaims(
+		tokenString,
+		&claims,
+		a.jwtKey,
+		jwt.WithValidMethods(jwtValidMethods),
+	)
+	if err != nil {
+		return nil, errInvalidCredentials
+	}
+
+	if a.issuer != "" && !claims.VerifyIssuer(a.issuer, true) {
+		return nil, errInvalidCredentials
+	}
+
+	if a.audience != "" && !claims.VerifyAudience(a.audience, true) {
+		return nil, errInvalidCredentials
+	}
+
+	if claims.ExpiresAt == nil || claims.Subject == "" {
+		return nil, errInvalidCredentials
+	}
+
+	return &Principal{
+		Subject: claims.Subject,
+		Method:  MethodJWT,
+		Roles:   claims.Roles,
+		Scopes:  strings.Fields(claims.Scope),
+	}, nil
+}
+
+func (a *Authenticator) jwtKey(token *jwt.Token) (interface{}, error) {
+	kid, _ := token.Header["kid"].(string)
+
+	key, ok := a.jwtKeys[kid]
+	if !ok {
+		return nil, errors.New("unknown key id")
+	}
+
+	return key, nil
+}
+
+func (a *Authenticator) authenticateAPIKey(key string) (*Principal, error) {
+	hash := sha256.Sum256([]byte(key))
+
+	for knownHash, name := range a.apiKeys {
+		if subtle.ConstantTimeCompare(hash[:], knownHash[:]) == 1 {
+			return &Principal{Subject: name, Method: MethodAPIKey, Roles: nil, Scopes: nil}, nil
+		}
+	}
+
+	return nil, errInvalidCredentials
+}
+
+func cutPrefixFold(s string, prefix string) (string, bool) {
+	if len(s) < len(prefix) || !strings.EqualFold(s[:len(prefix)], prefix) {
+		return "", false
+	}
+
+	return strings.TrimSpace(s[len(prefix):]), true
+}
@@ -0,0 +1,288 @@
+package auth
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/rsa"
+	"crypto/x509"
+	"encoding/base64"
+	"encoding/json"
+	"encoding/pem"
+	"math/big"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/golang-jwt/jwt/v4"
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/actor"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+const (
+	testIssuer   = "https://auth.example.com"
+	testAudience = "products"
+)
+
+func newTestAuthenticator(t *testing.T) (*Authenticator, *rsa.PrivateKey) {
+	t.Helper()
+
+	privateKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	return New(Config{
+		APIKeys:       map[string]string{"storefront": "secret-key"},
+		JWTKeys:       map[string]interface{}{"main": &privateKey.PublicKey},
+		JWTIssuer:     testIssuer,
+		JWTAudience:   testAudience,
+		ExemptMethods: []string{"/grpc.health.v1.Health/Check"},
+	}, logging.NewDummyLogger()), privateKey
+}
+
+func signToken(t *testing.T, key interface{}, method jwt.SigningMethod, kid string, claims jwt.Claims) string {
+	t.Helper()
+
+	token := jwt.NewWithClaims(method, claims)
+	if kid != "" {
+		token.Header["kid"] = kid
+	}
+
+	signed, err := token.SignedString(key)
+	require.NoError(t, err)
+
+	return signed
+}
+
+func validClaims() jwtClaims {
+	return jwtClaims{
+		Roles: []string{"editor"},
+		Scope: "products:read products:write",
+		RegisteredClaims: jwt.RegisteredClaims{ //nolint: exhaustruct
+			Subject:   "user-1",
+			Issuer:    testIssuer,
+			Audience:  jwt.ClaimStrings{testAudience},
+			ExpiresAt: jwt.NewNumericDate(time.Now().Add(time.Hour)),
+		},
+	}
+}
+
+func incomingContext(pairs ...string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(pairs...))
+}
+
+func TestAuthenticator_Authenticate(t *testing.T) {
+	authenticator, privateKey := newTestAuthenticator(t)
+	otherKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	expired := validClaims()
+	expired.ExpiresAt = jwt.NewNumericDate(time.Now().Add(-time.Minute))
+
+	wrongIssuer := validClaims()
+	wrongIssuer.Issuer = "https://evil.example.com"
+
+	wrongAudience := validClaims()
+	wrongAudience.Audience = jwt.ClaimStrings{"billing"}
+
+	withoutExpiration := validClaims()
+	withoutExpiration.ExpiresAt = nil
+
+	testCases := []struct {
+		name      string
+		ctx       context.Context
+		principal *Principal
+	}{
+		{
+			name: "valid jwt",
+			ctx:  incomingContext(AuthorizationMetadataKey, "Bearer "+signToken(t, privateKey, jwt.SigningMethodRS256, "main", validClaims())),
+			principal: &Principal{
+				Subject: "user-1",
+				Method:  MethodJWT,
+				Roles:   []string{"editor"},
+				Scopes:  []string{"products:read", "products:write"},
+			},
+		},
+		{
+			name:      "valid api key",
+			ctx:       incomingContext(APIKeyMetadataKey, "secret-key"),
+			principal: &Principal{Subject: "storefront", Method: MethodAPIKey, Roles: nil, Scopes: nil},
+		},
+		{name: "no credentials", ctx: context.Background(), principal: nil},
+		{name: "wrong api key", ctx: incomingContext(APIKeyMetadataKey, "secret-key-2"), principal: nil},
+		{name: "not bearer", ctx: incomingContext(AuthorizationMetadataKey, "Basic dXNlcjpwYXNz"), principal: nil},
+		{
+			name:      "foreign signature",
+			ctx:       incomingContext(AuthorizationMetadataKey, "Bearer "+signToken(t, otherKey, jwt.SigningMethodRS256, "main", validClaims())),
+			principal: nil,
+		},
+		{
+			name:      "unknown kid",
+			ctx:       incomingContext(AuthorizationMetadataKey, "Bearer "+signToken(t, privateKey, jwt.SigningMethodRS256, "old", validClaims())),
+			principal: nil,
+		},
+		{
+			name:      "hmac token",
+			ctx:       incomingContext(AuthorizationMetadataKey, "Bearer "+signToken(t, []byte("secret"), jwt.SigningMethodHS256, "main", validClaims())),
+			principal: nil,
+		},
+		{
+			name:      "expired",
+			ctx:       incomingContext(AuthorizationMetadataKey, "Bearer "+signToken(t, privateKey, jwt.SigningMethodRS256, "main", expired)),
+			principal: nil,
+		},
+		{
+			name:      "wrong issuer",
+			ctx:       incomingContext(AuthorizationMetadataKey, "Bearer "+signToken(t, privateKey, jwt.SigningMethodRS256, "main", wrongIssuer)),
+			principal: nil,
+		},
+		{
+			name:      "wrong audience",
+			ctx:       incomingContext(AuthorizationMetadataKey, "Bearer "+signToken(t, privateKey, jwt.SigningMethodRS256, "main", wrongAudience)),
+			principal: nil,
+		},
+		{
+			name: "without expiration",
+			ctx: incomingContext(
+				AuthorizationMetadataKey, "Bearer "+signToken(t, privateKey, jwt.SigningMethodRS256, "main", withoutExpiration),
+			),
+			principal: nil,
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			principal, err := authenticator.Authenticate(tc.ctx)
+			if tc.principal == nil {
+				require.Error(t, err)
+				assert.True(t, commonerr.IsErrorType(err, commonerr.ErrorTypeUnauthenticated))
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.principal, principal)
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	authenticator, _ := newTestAuthenticator(t)
+	interceptor := UnaryServerInterceptor(authenticator)
+
+	var (
+		principal *Principal
+		audit     string
+	)
+
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) {
+		principal = FromContext(ctx)
+		audit = actor.FromContext(ctx)
+
+		return "ok", nil
+	}
+
+	t.Run("authenticated", func(t *testing.T) {
+		ctx := incomingContext(APIKeyMetadataKey, "secret-key", actor.MetadataKey, "spoofed")
+
+		resp, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{FullMethod: "/ProductService/DeleteProduct"}, handler) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+		require.NotNil(t, principal)
+		assert.Equal(t, "storefront", principal.Subject)
+		assert.Equal(t, "storefront", audit)
+	})
+	t.Run("rejected", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/ProductService/DeleteProduct"}, handler) //nolint: exhaustruct
+		assert.Equal(t, codes.Unauthenticated, status.Code(err))
+	})
+	t.Run("exempt method", func(t *testing.T) {
+		principal = &Principal{} //nolint: exhaustruct
+
+		_, err := interceptor(context.Background(), nil, &grpc.UnaryServerInfo{FullMethod: "/grpc.health.v1.Health/Check"}, handler) //nolint: exhaustruct
+		require.NoError(t, err)
+		assert.Nil(t, principal)
+	})
+}
+
+func TestLoadJWTKeys(t *testing.T) {
+	dir := t.TempDir()
+
+	rsaKey, err := rsa.GenerateKey(rand.Reader, 2048)
+	require.NoError(t, err)
+
+	ecKey, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	t.Run("pem", func(t *testing.T) {
+		der, err := x509.MarshalPKIXPublicKey(&ecKey.PublicKey)
+		require.NoError(t, err)
+
+		path := filepath.Join(dir, "key.pem")
+		require.NoError(t, os.WriteFile(path, pem.EncodeToMemory(&pem.Block{Type: "PUBLIC KEY", Bytes: der}), 0o600)) //nolint: exhaustruct
+
+		keys, err := LoadJWTKeys(path)
+		require.NoError(t, err)
+		assert.Equal(t, &ecKey.PublicKey, keys[""])
+	})
+	t.Run("jwks", func(t *testing.T) {
+		encode := func(i *big.Int) string { return base64.RawURLEncoding.EncodeToString(i.Bytes()) }
+
+		data, err := json.Marshal(map[string]interface{}{"keys": []map[string]string{
+			{"kty": "RSA", "kid": "rsa", "use": "sig", "n": encode(rsaKey.N), "e": encode(big.NewInt(int64(rsaKey.E)))},
+			{"kty": "EC", "kid": "ec", "crv": "P-256", "x": encode(ecKey.X), "y": encode(ecKey.Y)},
+			{"kty": "RSA", "kid": "encryption", "use": "enc", "n": encode(rsaKey.N), "e": "AQAB"},
+		}})
+		require.NoError(t, err)
+
+		path := filepath.Join(dir, "jwks.json")
+		require.NoError(t, os.WriteFile(path, data, 0o600))
+
+		keys, err := LoadJWTKeys(path)
+		require.NoError(t, err)
+		require.Len(t, keys, 2)
+		assert.Equal(t, &rsaKey.PublicKey, keys["rsa"])
+		assert.True(t, ecKey.PublicKey.Equal(keys["ec"]))
+	})
+	t.Run("garbage", func(t *testing.T) {
+		path := filepath.Join(dir, "garbage")
+		require.NoError(t, os.WriteFile(path, []byte("not a key"), 0o600))
+
+		_, err := LoadJWTKeys(path)
+		require.Error(t, err)
+	})
+}
+
+func TestExtractPrincipal_BeforeAuthentication(t *testing.T) {
+	authenticator, _ := newTestAuthenticator(t)
+	chain := grpc_middleware.ChainUnaryServer(
+		PrincipalHolderUnaryServerInterceptor(),
+		// логирование в цепочке раньше аутентификации и читает клиента после вызова
+		func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+			assert.Empty(t, ExtractPrincipal(ctx))
+
+			resp, err := handler(ctx, req)
+
+			assert.Equal(t, []any{"grpc.principal", "storefront", "grpc.authMethod", MethodAPIKey}, ExtractPrincipal(ctx))
+
+			return resp, err
+		},
+		UnaryServerInterceptor(authenticator),
+	)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/ProductService/DeleteProduct"} //nolint: exhaustruct
+
+	_, err := chain(incomingContext(APIKeyMetadataKey, "secret-key"), nil, info, handler)
+	require.NoError(t, err)
+}
@@ -0,0 +1,59 @@
+package auth
+
+import (
+	"context"
+	"sync"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+)
+
+type principalHolderKey struct{}
+
+// principalHolder место в контексте, куда authenticateCall записывает клиента. Через него клиента видят
+// interceptor'ы, стоящие в цепочке раньше аутентификации, например логирование запроса.
+type principalHolder struct {
+	principal *Principal
+	mutex     sync.RWMutex
+}
+
+func (h *principalHolder) set(principal *Principal) {
+	h.mutex.Lock()
+	defer h.mutex.Unlock()
+
+	h.principal = principal
+}
+
+func (h *principalHolder) get() *Principal {
+	h.mutex.RLock()
+	defer h.mutex.RUnlock()
+
+	return h.principal
+}
+
+// PrincipalHolderUnaryServerInterceptor резервирует место под клиента для ExtractPrincipal.
+// Должен идти в цепочке раньше логирования.
+func PrincipalHolderUnaryServerInterceptor() grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(withPrincipalHolder(ctx), req)
+	}
+}
+
+func PrincipalHolderStreamServerInterceptor() grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = withPrincipalHolder(stream.Context())
+
+		return handler(srv, wrapped)
+	}
+}
+
+func withPrincipalHolder(ctx context.Context) context.Context {
+	return context.WithValue(ctx, principalHolderKey{}, &principalHolder{principal: nil, mutex: sync.RWMutex{}})
+}
+
+func holderFromContext(ctx context.Context) *principalHolder {
+	holder, _ := ctx.Value(principalHolderKey{}).(*principalHolder)
+
+	return holder
+}
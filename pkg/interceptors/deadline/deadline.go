@@ -0,0 +1,80 @@
+package deadline
+
+import (
+	"context"
+	"time"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"google.golang.org/grpc"
+)
+
+type Config struct {
+	// Default дедлайн вызова, если клиент его не передал. 0 — без дедлайна
+	Default time.Duration
+	// Max верхняя граница дедлайна: более поздний дедлайн клиента сокращается. 0 — без ограничения
+	Max time.Duration
+	// ExemptMethods долгоживущие методы, например health Watch, которым дедлайн не назначается
+	ExemptMethods []string
+}
+
+type Deadlines struct {
+	defaultTimeout time.Duration
+	maxTimeout     time.Duration
+	exempt         map[string]struct{}
+}
+
+func New(cfg Config) *Deadlines {
+	exempt := make(map[string]struct{}, len(cfg.ExemptMethods))
+	for _, method := range cfg.ExemptMethods {
+		exempt[method] = struct{}{}
+	}
+
+	return &Deadlines{
+		defaultTimeout: cfg.Default,
+		maxTimeout:     cfg.Max,
+		exempt:         exempt,
+	}
+}
+
+// WithDeadline назначает вызову дедлайн по умолчанию или сокращает слишком поздний дедлайн клиента.
+func (d *Deadlines) WithDeadline(ctx context.Context, fullMethod string) (context.Context, context.CancelFunc) {
+	if _, ok := d.exempt[fullMethod]; ok {
+		return ctx, func() {}
+	}
+
+	if _, ok := ctx.Deadline(); !ok {
+		if d.defaultTimeout <= 0 {
+			return ctx, func() {}
+		}
+
+		return context.WithTimeout(ctx, d.defaultTimeout)
+	}
+
+	if d.maxTimeout <= 0 {
+		return ctx, func() {}
+	}
+
+	// context.WithTimeout оставляет более ранний дедлайн клиента без изменений
+	return context.WithTimeout(ctx, d.maxTimeout)
+}
+
+func UnaryServerInterceptor(d *Deadlines) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		ctx, cancel := d.WithDeadline(ctx, info.FullMethod)
+		defer cancel()
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(d *Deadlines) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		ctx, cancel := d.WithDeadline(stream.Context(), info.FullMethod)
+		defer cancel()
+
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = ctx
+
+		return handler(srv, wrapped)
+	}
+}
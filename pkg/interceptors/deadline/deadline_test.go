@@ -0,0 +1,92 @@
+package deadline
+
+import (
+	"context"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+)
+
+const (
+	getProducts = "/ProductService/GetProducts"
+	healthWatch = "/grpc.health.v1.Health/Watch"
+)
+
+func TestDeadlines_WithDeadline(t *testing.T) {
+	deadlines := New(Config{Default: time.Second * 5, Max: time.Minute, ExemptMethods: []string{healthWatch}})
+
+	testCases := []struct {
+		name     string
+		method   string
+		timeout  time.Duration
+		expected time.Duration
+	}{
+		{name: "default", method: getProducts, timeout: 0, expected: time.Second * 5},
+		{name: "client deadline", method: getProducts, timeout: time.Second * 20, expected: time.Second * 20},
+		{name: "capped", method: getProducts, timeout: time.Hour, expected: time.Minute},
+		{name: "exempt", method: healthWatch, timeout: 0, expected: 0},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+
+			ctx := context.Background()
+			if tc.timeout > 0 {
+				var cancel context.CancelFunc
+				ctx, cancel = context.WithTimeout(ctx, tc.timeout)
+				defer cancel()
+			}
+
+			ctx, cancel := deadlines.WithDeadline(ctx, tc.method)
+			defer cancel()
+
+			deadline, ok := ctx.Deadline()
+			if tc.expected == 0 {
+				assert.False(t, ok)
+
+				return
+			}
+
+			require.True(t, ok)
+			assert.WithinDuration(t, time.Now().Add(tc.expected), deadline, time.Second)
+		})
+	}
+}
+
+func TestDeadlines_Disabled(t *testing.T) {
+	deadlines := New(Config{}) //nolint: exhaustruct
+
+	ctx, cancel := deadlines.WithDeadline(context.Background(), getProducts)
+	defer cancel()
+
+	_, ok := ctx.Deadline()
+	assert.False(t, ok)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor(New(Config{Default: time.Second, Max: time.Minute, ExemptMethods: nil}))
+
+	var handlerCtx context.Context
+
+	_, err := interceptor(
+		context.Background(),
+		nil,
+		&grpc.UnaryServerInfo{FullMethod: getProducts}, //nolint: exhaustruct
+		func(ctx context.Context, req interface{}) (interface{}, error) {
+			handlerCtx = ctx
+
+			return nil, nil
+		},
+	)
+	require.NoError(t, err)
+
+	_, ok := handlerCtx.Deadline()
+	assert.True(t, ok)
+	// после завершения вызова контекст освобождается
+	assert.Error(t, handlerCtx.Err())
+}
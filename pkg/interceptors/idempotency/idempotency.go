@@ -0,0 +1,215 @@
+package idempotency
+
+import (
+	"bytes"
+	"context"
+	"crypto/rand"
+	"crypto/sha256"
+	"encoding/hex"
+	"errors"
+	"regexp"
+	"time"
+
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/anypb"
+
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+const (
+	// MetadataKey ключ метаданных с ключом идемпотентности. Через grpc-gateway передается
+	// HTTP-заголовком `Idempotency-Key`
+	MetadataKey = "idempotency-key"
+	// ReplayedMetadataKey заголовок ответа, который выставляется, если ответ взят из сохраненного результата
+	ReplayedMetadataKey = "idempotency-replayed"
+
+	// storeTimeout время на сохранение результата. Результат сохраняется, даже если клиент уже отключился
+	storeTimeout = time.Second * 5
+)
+
+// validKey ключ, сгенерированный клиентом, например UUID
+var validKey = regexp.MustCompile(`^[A-Za-z0-9._:-]{1,128}$`)
+
+// ErrReservationLost запись с ключом уже перезаписана другим запросом после LockTimeout, результат не сохранен
+var ErrReservationLost = errors.New("idempotency key reservation was lost")
+
+// Record запрос с ключом идемпотентности и его результат.
+type Record struct {
+	// Key ключ клиента вместе с именем аутентифицированного клиента: одинаковые ключи разных клиентов не пересекаются
+	Key         string
+	Method      string
+	RequestHash []byte
+	// Response сериализованный google.protobuf.Any с ответом. nil — запрос еще выполняется
+	Response []byte
+	// Token случайный идентификатор резервирования. Complete и Release применяются только к записи с тем же
+	// токеном, поэтому запрос, чью запись перехватили после LockTimeout, не затрет результат нового владельца
+	Token     string
+	CreatedAt time.Time
+}
+
+type Store interface {
+	// Reserve сохраняет запись о начале выполнения запроса. Если запись с таким ключом уже есть, возвращает ее
+	// без изменений. Незавершенная запись старше lockTimeout считается брошенной и перезаписывается.
+	Reserve(ctx context.Context, record *Record, lockTimeout time.Duration) (*Record, error)
+	// Complete сохраняет ответ на запрос, если запись все еще зарезервирована с token. Иначе возвращает
+	// ErrReservationLost.
+	Complete(ctx context.Context, key string, token string, response []byte) error
+	// Release удаляет незавершенную запись с token, чтобы запрос после ошибки можно было повторить.
+	// Запись другого резервирования не удаляется.
+	Release(ctx context.Context, key string, token string) error
+}
+
+type Config struct {
+	// Methods полные имена изменяющих методов gRPC, для которых учитывается ключ идемпотентности
+	Methods []string
+	// LockTimeout время, после которого незавершенный запрос с тем же ключом можно выполнить повторно
+	LockTimeout time.Duration
+}
+
+// UnaryServerInterceptor выполняет запрос с ключом идемпотентности один раз: повтор с тем же ключом и телом
+// получает сохраненный ответ, повтор с другим телом отклоняется. Ответы с ошибкой не сохраняются,
+// такой запрос можно повторить с тем же ключом.
+func UnaryServerInterceptor(store Store, cfg Config, logger logging.ContextLogger) grpc.UnaryServerInterceptor {
+	methods := make(map[string]struct{}, len(cfg.Methods))
+	for _, method := range cfg.Methods {
+		methods[method] = struct{}{}
+	}
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if _, ok := methods[info.FullMethod]; !ok {
+			return handler(ctx, req)
+		}
+
+		md, _ := metadata.FromIncomingContext(ctx)
+
+		keys := md.Get(MetadataKey)
+		if len(keys) == 0 {
+			return handler(ctx, req)
+		}
+
+		if !validKey.MatchString(keys[0]) {
+			return nil, status.Errorf(codes.InvalidArgument, "invalid %s, expected up to 128 of A-Za-z0-9._:-", MetadataKey)
+		}
+
+		message, ok := req.(proto.Message)
+		if !ok {
+			return handler(ctx, req)
+		}
+
+		record, err := newRecord(ctx, keys[0], info.FullMethod, message)
+		if err != nil {
+			return nil, status.Error(codes.Internal, "can't prepare idempotency record")
+		}
+
+		_, ctxLogger := logger.FromContext(ctx)
+
+		existing, err := store.Reserve(ctx, record, cfg.LockTimeout)
+		if err != nil {
+			ctxLogger.Errorw("Can't reserve idempotency key", "err", err)
+
+			return nil, status.Error(codes.Unavailable, "can't check idempotency key, retry later")
+		}
+
+		if existing != nil {
+			return replay(ctx, record, existing)
+		}
+
+		resp, err := handler(ctx, req)
+
+		storeCtx, cancel := context.WithTimeout(context.Background(), storeTimeout)
+		defer cancel()
+
+		if err != nil {
+			if releaseErr := store.Release(storeCtx, record.Key, record.Token); releaseErr != nil {
+				ctxLogger.Warnw("Can't release idempotency key", "err", releaseErr)
+			}
+
+			return nil, err
+		}
+
+		if err := complete(storeCtx, store, record, resp); err != nil {
+			// ответ уже получен, повтор выполнит запрос заново после LockTimeout
+			ctxLogger.Warnw("Can't save idempotent response", "err", err)
+		}
+
+		return resp, nil
+	}
+}
+
+func newRecord(ctx context.Context, key string, method string, req proto.Message) (*Record, error) {
+	data, err := proto.MarshalOptions{Deterministic: true}.Marshal(req) //nolint: exhaustruct
+	if err != nil {
+		return nil, err
+	}
+
+	hash := sha256.New()
+	hash.Write([]byte(method))
+	hash.Write([]byte{0})
+	hash.Write(data)
+
+	if principal := auth.FromContext(ctx); principal != nil {
+		key = principal.Subject + "/" + key
+	}
+
+	token := make([]byte, 16)
+	if _, err := rand.Read(token); err != nil {
+		return nil, err
+	}
+
+	return &Record{
+		Key:         key,
+		Method:      method,
+		RequestHash: hash.Sum(nil),
+		Response:    nil,
+		Token:       hex.EncodeToString(token),
+		CreatedAt:   time.Now().UTC(),
+	}, nil
+}
+
+func replay(ctx context.Context, record *Record, existing *Record) (interface{}, error) {
+	if existing.Method != record.Method || !bytes.Equal(existing.RequestHash, record.RequestHash) {
+		return nil, status.Errorf(codes.InvalidArgument, "%s was already used with a different request", MetadataKey)
+	}
+
+	if existing.Response == nil {
+		return nil, status.Errorf(codes.Aborted, "request with the same %s is in progress", MetadataKey)
+	}
+
+	var response anypb.Any
+	if err := proto.Unmarshal(existing.Response, &response); err != nil {
+		return nil, status.Error(codes.Internal, "can't read saved response")
+	}
+
+	message, err := response.UnmarshalNew()
+	if err != nil {
+		return nil, status.Error(codes.Internal, "can't read saved response")
+	}
+
+	_ = grpc.SetHeader(ctx, metadata.Pairs(ReplayedMetadataKey, "true"))
+
+	return message, nil
+}
+
+func complete(ctx context.Context, store Store, record *Record, resp interface{}) error {
+	message, ok := resp.(proto.Message)
+	if !ok {
+		return store.Release(ctx, record.Key, record.Token)
+	}
+
+	response, err := anypb.New(message)
+	if err != nil {
+		return err
+	}
+
+	data, err := proto.Marshal(response)
+	if err != nil {
+		return err
+	}
+
+	return store.Complete(ctx, record.Key, record.Token, data)
+}
@@ -0,0 +1,253 @@
+package idempotency
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+	"google.golang.org/protobuf/proto"
+	"google.golang.org/protobuf/types/known/emptypb"
+	"google.golang.org/protobuf/types/known/wrapperspb"
+
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+const (
+	deleteProduct = "/ProductService/DeleteProduct"
+	getProduct    = "/ProductService/GetProduct"
+)
+
+type memoryStore struct {
+	mu      sync.Mutex
+	records map[string]*Record
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{mu: sync.Mutex{}, records: make(map[string]*Record)}
+}
+
+func (s *memoryStore) Reserve(_ context.Context, record *Record, lockTimeout time.Duration) (*Record, error) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[record.Key]
+	if ok && (existing.Response != nil || record.CreatedAt.Sub(existing.CreatedAt) < lockTimeout) {
+		return existing, nil
+	}
+
+	s.records[record.Key] = record
+
+	return nil, nil
+}
+
+func (s *memoryStore) Complete(_ context.Context, key string, token string, response []byte) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	existing, ok := s.records[key]
+	if !ok || existing.Token != token || existing.Response != nil {
+		return ErrReservationLost
+	}
+
+	existing.Response = response
+
+	return nil
+}
+
+func (s *memoryStore) Release(_ context.Context, key string, token string) error {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if existing, ok := s.records[key]; ok && existing.Token == token && existing.Response == nil {
+		delete(s.records, key)
+	}
+
+	return nil
+}
+
+type countingHandler struct {
+	calls int
+	err   error
+}
+
+func (h *countingHandler) handle(_ context.Context, req interface{}) (interface{}, error) {
+	h.calls++
+	if h.err != nil {
+		return nil, h.err
+	}
+
+	return &emptypb.Empty{}, nil
+}
+
+func newInterceptor(store Store) grpc.UnaryServerInterceptor {
+	return UnaryServerInterceptor(
+		store,
+		Config{Methods: []string{deleteProduct}, LockTimeout: time.Minute},
+		logging.NewDummyLogger(),
+	)
+}
+
+func keyContext(key string) context.Context {
+	return metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, key))
+}
+
+func call(interceptor grpc.UnaryServerInterceptor, ctx context.Context, method string, req proto.Message, h *countingHandler) (interface{}, error) {
+	return interceptor(ctx, req, &grpc.UnaryServerInfo{FullMethod: method}, h.handle) //nolint: exhaustruct
+}
+
+func TestUnaryServerInterceptor_Replay(t *testing.T) {
+	interceptor := newInterceptor(newMemoryStore())
+	handler := &countingHandler{}
+	req := wrapperspb.String("62fa1d6a2c5b0a5f0b3c1234")
+
+	resp, err := call(interceptor, keyContext("key-1"), deleteProduct, req, handler)
+	require.NoError(t, err)
+	assert.IsType(t, &emptypb.Empty{}, resp)
+
+	// повтор после таймаута сети: ответ из хранилища, без повторного удаления
+	resp, err = call(interceptor, keyContext("key-1"), deleteProduct, req, handler)
+	require.NoError(t, err)
+	assert.True(t, proto.Equal(&emptypb.Empty{}, resp.(proto.Message)))
+	assert.Equal(t, 1, handler.calls)
+
+	// другой ключ выполняет запрос заново
+	_, err = call(interceptor, keyContext("key-2"), deleteProduct, req, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 2, handler.calls)
+}
+
+func TestUnaryServerInterceptor_DifferentPayload(t *testing.T) {
+	interceptor := newInterceptor(newMemoryStore())
+	handler := &countingHandler{}
+
+	_, err := call(interceptor, keyContext("key-1"), deleteProduct, wrapperspb.String("first"), handler)
+	require.NoError(t, err)
+
+	_, err = call(interceptor, keyContext("key-1"), deleteProduct, wrapperspb.String("second"), handler)
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestUnaryServerInterceptor_InProgress(t *testing.T) {
+	store := newMemoryStore()
+	interceptor := newInterceptor(store)
+	req := wrapperspb.String("first")
+
+	record, err := newRecord(context.Background(), "key-1", deleteProduct, req)
+	require.NoError(t, err)
+
+	_, err = store.Reserve(context.Background(), record, time.Minute)
+	require.NoError(t, err)
+
+	handler := &countingHandler{}
+	_, err = call(interceptor, keyContext("key-1"), deleteProduct, req, handler)
+	assert.Equal(t, codes.Aborted, status.Code(err))
+	assert.Equal(t, 0, handler.calls)
+}
+
+func TestUnaryServerInterceptor_ErrorReleasesKey(t *testing.T) {
+	interceptor := newInterceptor(newMemoryStore())
+	req := wrapperspb.String("first")
+
+	failing := &countingHandler{err: status.Error(codes.Unavailable, "")}
+	_, err := call(interceptor, keyContext("key-1"), deleteProduct, req, failing)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+
+	handler := &countingHandler{}
+	_, err = call(interceptor, keyContext("key-1"), deleteProduct, req, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 1, handler.calls)
+}
+
+func TestUnaryServerInterceptor_StaleReservation(t *testing.T) {
+	store := newMemoryStore()
+	req := wrapperspb.String("first")
+
+	// запрос завис дольше LockTimeout, его запись перехватил повтор
+	stale, err := newRecord(context.Background(), "key-1", deleteProduct, req)
+	require.NoError(t, err)
+	stale.CreatedAt = stale.CreatedAt.Add(-time.Hour)
+
+	_, err = store.Reserve(context.Background(), stale, time.Minute)
+	require.NoError(t, err)
+
+	handler := &countingHandler{}
+	_, err = call(newInterceptor(store), keyContext("key-1"), deleteProduct, req, handler)
+	require.NoError(t, err)
+	assert.Equal(t, 1, handler.calls)
+
+	owner := store.records["key-1"]
+	require.NotNil(t, owner.Response)
+	assert.NotEqual(t, stale.Token, owner.Token)
+
+	// прежний владелец не может ни перезаписать ответ, ни удалить запись
+	assert.ErrorIs(t, store.Complete(context.Background(), "key-1", stale.Token, []byte("stale")), ErrReservationLost)
+	require.NoError(t, store.Release(context.Background(), "key-1", stale.Token))
+	assert.Same(t, owner, store.records["key-1"])
+	assert.NotEqual(t, []byte("stale"), owner.Response)
+}
+
+func TestUnaryServerInterceptor_Skipped(t *testing.T) {
+	store := newMemoryStore()
+	interceptor := newInterceptor(store)
+	handler := &countingHandler{}
+	req := wrapperspb.String("first")
+
+	for i := 0; i < 2; i++ {
+		_, err := call(interceptor, context.Background(), deleteProduct, req, handler)
+		require.NoError(t, err)
+
+		_, err = call(interceptor, keyContext("key-1"), getProduct, req, handler)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 4, handler.calls)
+	assert.Empty(t, store.records)
+}
+
+func TestUnaryServerInterceptor_KeyScope(t *testing.T) {
+	store := newMemoryStore()
+	interceptor := newInterceptor(store)
+	handler := &countingHandler{}
+
+	for _, subject := range []string{"storefront", "importer"} {
+		ctx := auth.NewContext(keyContext("key-1"), &auth.Principal{Subject: subject}) //nolint: exhaustruct
+
+		_, err := call(interceptor, ctx, deleteProduct, wrapperspb.String(subject), handler)
+		require.NoError(t, err)
+	}
+
+	assert.Equal(t, 2, handler.calls)
+	assert.Contains(t, store.records, "storefront/key-1")
+}
+
+func TestUnaryServerInterceptor_InvalidKey(t *testing.T) {
+	interceptor := newInterceptor(newMemoryStore())
+
+	_, err := call(interceptor, keyContext("key with spaces"), deleteProduct, wrapperspb.String("first"), &countingHandler{})
+	assert.Equal(t, codes.InvalidArgument, status.Code(err))
+}
+
+type failingStore struct{ memoryStore }
+
+func (s *failingStore) Reserve(context.Context, *Record, time.Duration) (*Record, error) {
+	return nil, errors.New("connection refused")
+}
+
+func TestUnaryServerInterceptor_StoreUnavailable(t *testing.T) {
+	interceptor := newInterceptor(&failingStore{})
+	handler := &countingHandler{}
+
+	_, err := call(interceptor, keyContext("key-1"), deleteProduct, wrapperspb.String("first"), handler)
+	assert.Equal(t, codes.Unavailable, status.Code(err))
+	assert.Equal(t, 0, handler.calls)
+}
@@ -0,0 +1,104 @@
+package locale
+
+import (
+	"context"
+	"fmt"
+
+	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
+	"golang.org/x/text/language"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+const (
+	// MetadataKey ключ метаданных с явно выбранной локалью. Имеет приоритет над Accept-Language.
+	MetadataKey = "x-locale"
+	// acceptLanguageKey заголовок Accept-Language, пришедший напрямую по gRPC
+	acceptLanguageKey = "accept-language"
+	// gatewayAcceptLanguageKey заголовок Accept-Language, проброшенный grpc-gateway
+	gatewayAcceptLanguageKey = "grpcgateway-accept-language"
+)
+
+type localeKey struct{}
+
+// Resolver выбирает наиболее подходящую из поддерживаемых локалей.
+type Resolver struct {
+	supported []string
+	matcher   language.Matcher
+}
+
+// NewResolver создает Resolver. fallback используется, когда ни одна из запрошенных локалей не поддерживается.
+func NewResolver(fallback string, supported []string) (*Resolver, error) {
+	locales := append([]string{fallback}, supported...)
+	tags := make([]language.Tag, 0, len(locales))
+
+	for _, locale := range locales {
+		tag, err := language.Parse(locale)
+		if err != nil {
+			return nil, fmt.Errorf("invalid locale %q: %w", locale, err)
+		}
+
+		tags = append(tags, tag)
+	}
+
+	return &Resolver{
+		supported: locales,
+		matcher:   language.NewMatcher(tags),
+	}, nil
+}
+
+// Resolve принимает значения в формате Accept-Language и возвращает выбранную локаль.
+func (r *Resolver) Resolve(acceptLanguage string) string {
+	tags, _, err := language.ParseAcceptLanguage(acceptLanguage)
+	if err != nil || len(tags) == 0 {
+		return r.supported[0]
+	}
+
+	_, idx, confidence := r.matcher.Match(tags...)
+	if confidence == language.No {
+		return r.supported[0]
+	}
+
+	return r.supported[idx]
+}
+
+func UnaryServerInterceptor(resolver *Resolver) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		return handler(resolver.contextWithLocale(ctx), req)
+	}
+}
+
+func StreamServerInterceptor(resolver *Resolver) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		wrapped := grpc_middleware.WrapServerStream(stream)
+		wrapped.WrappedContext = resolver.contextWithLocale(stream.Context())
+
+		return handler(srv, wrapped)
+	}
+}
+
+func NewContext(ctx context.Context, locale string) context.Context {
+	return context.WithValue(ctx, localeKey{}, locale)
+}
+
+// FromContext возвращает локаль запроса или пустую строку, если интерцептор не подключен.
+func FromContext(ctx context.Context) string {
+	locale, ok := ctx.Value(localeKey{}).(string)
+	if !ok {
+		return ""
+	}
+
+	return locale
+}
+
+func (r *Resolver) contextWithLocale(ctx context.Context) context.Context {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	for _, key := range []string{MetadataKey, acceptLanguageKey, gatewayAcceptLanguageKey} {
+		if values := md.Get(key); len(values) > 0 && values[0] != "" {
+			return NewContext(ctx, r.Resolve(values[0]))
+		}
+	}
+
+	return NewContext(ctx, r.supported[0])
+}
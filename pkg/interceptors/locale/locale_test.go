@@ -0,0 +1,79 @@
+package locale
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+)
+
+func TestResolver_Resolve(t *testing.T) {
+	resolver, err := NewResolver("ru", []string{"en", "kk"})
+	require.NoError(t, err)
+
+	testCases := []struct {
+		acceptLanguage string
+		expected       string
+	}{
+		{acceptLanguage: "", expected: "ru"},
+		{acceptLanguage: "en", expected: "en"},
+		{acceptLanguage: "en-US,en;q=0.9", expected: "en"},
+		{acceptLanguage: "de-DE,kk;q=0.8,en;q=0.5", expected: "kk"},
+		{acceptLanguage: "fr", expected: "ru"},
+		{acceptLanguage: "not a locale;;", expected: "ru"},
+	}
+
+	for _, tc := range testCases {
+		assert.Equal(t, tc.expected, resolver.Resolve(tc.acceptLanguage), "Accept-Language: %q", tc.acceptLanguage)
+	}
+}
+
+func TestNewResolver_InvalidLocale(t *testing.T) {
+	_, err := NewResolver("ru", []string{"!!"})
+	require.Error(t, err)
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	resolver, err := NewResolver("ru", []string{"en"})
+	require.NoError(t, err)
+
+	interceptor := UnaryServerInterceptor(resolver)
+
+	testCases := []struct {
+		name     string
+		md       metadata.MD
+		expected string
+	}{
+		{name: "no metadata", md: nil, expected: "ru"},
+		{name: "accept-language", md: metadata.Pairs("accept-language", "en"), expected: "en"},
+		{name: "gateway header", md: metadata.Pairs("grpcgateway-accept-language", "en-GB"), expected: "en"},
+		{
+			name:     "explicit locale wins",
+			md:       metadata.Pairs(MetadataKey, "ru", "grpcgateway-accept-language", "en"),
+			expected: "ru",
+		},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			ctx := context.Background()
+			if tc.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tc.md)
+			}
+
+			var got string
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) { //nolint: exhaustruct
+				got = FromContext(ctx)
+
+				return nil, nil
+			})
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, got)
+		})
+	}
+}
@@ -0,0 +1,11 @@
+package ratelimit
+
+import (
+	"github.com/prometheus/client_golang/prometheus"
+	"github.com/prometheus/client_golang/prometheus/promauto"
+)
+
+var throttledRequests = promauto.NewCounterVec(prometheus.CounterOpts{ //nolint: exhaustruct
+	Name: "grpc_throttled_requests_total",
+	Help: "Total number of gRPC calls rejected by rate limiter by method.",
+}, []string{"method"})
@@ -0,0 +1,228 @@
+package ratelimit
+
+import (
+	"context"
+	"fmt"
+	"math"
+	"net"
+	"strconv"
+	"strings"
+	"sync"
+	"time"
+
+	"golang.org/x/time/rate"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+)
+
+const (
+	// forwardedForMetadataKey адрес клиента, который grpc-gateway дописывает последним значением
+	forwardedForMetadataKey = "x-forwarded-for"
+	// idleBucketTtl время, после которого неиспользуемый bucket клиента удаляется
+	idleBucketTtl = time.Minute * 10
+	// reasonRateLimited причина отказа в google.rpc.ErrorInfo
+	reasonRateLimited = "RATE_LIMITED"
+)
+
+// Limit ограничение token bucket: RPS запросов в секунду в среднем и до Burst подряд.
+// Нулевой RPS снимает ограничение.
+type Limit struct {
+	RPS   float64
+	Burst int
+}
+
+// ParseLimit разбирает ограничение из строки `<rps>/<burst>` или `<rps>`, тогда burst равен rps
+// с округлением вверх.
+func ParseLimit(value string) (Limit, error) {
+	rpsValue, burstValue, hasBurst := strings.Cut(strings.TrimSpace(value), "/")
+
+	rps, err := strconv.ParseFloat(rpsValue, 64)
+	if err != nil || rps < 0 {
+		return Limit{}, fmt.Errorf("invalid rate limit %q, expected <rps>/<burst>", value) //nolint: exhaustruct
+	}
+
+	burst := int(math.Ceil(rps))
+	if hasBurst {
+		burst, err = strconv.Atoi(burstValue)
+		if err != nil || burst < 1 {
+			return Limit{}, fmt.Errorf("invalid rate limit burst %q", value) //nolint: exhaustruct
+		}
+	}
+
+	return Limit{RPS: rps, Burst: burst}, nil
+}
+
+func (l Limit) unlimited() bool {
+	return l.RPS <= 0
+}
+
+type Config struct {
+	// Default ограничение для методов без своего
+	Default Limit
+	// Methods ограничения по полному имени метода gRPC
+	Methods map[string]Limit
+	// ExemptMethods методы без ограничений, например health check
+	ExemptMethods []string
+}
+
+type bucket struct {
+	limiter  *rate.Limiter
+	lastSeen time.Time
+}
+
+// Limiter хранит token bucket на каждую пару метода и клиента.
+type Limiter struct {
+	defaultLimit Limit
+	methods      map[string]Limit
+	exempt       map[string]struct{}
+	now          func() time.Time
+
+	mu        sync.Mutex
+	buckets   map[string]*bucket
+	lastSweep time.Time
+}
+
+func New(cfg Config) *Limiter {
+	exempt := make(map[string]struct{}, len(cfg.ExemptMethods))
+	for _, method := range cfg.ExemptMethods {
+		exempt[method] = struct{}{}
+	}
+
+	return &Limiter{
+		defaultLimit: cfg.Default,
+		methods:      cfg.Methods,
+		exempt:       exempt,
+		now:          time.Now,
+		mu:           sync.Mutex{},
+		buckets:      make(map[string]*bucket),
+		lastSweep:    time.Time{},
+	}
+}
+
+// Allow забирает токен из bucket клиента. Если токенов нет, возвращает время до появления следующего.
+func (l *Limiter) Allow(fullMethod string, client string) (bool, time.Duration) {
+	if _, ok := l.exempt[fullMethod]; ok {
+		return true, 0
+	}
+
+	limit, ok := l.methods[fullMethod]
+	if !ok {
+		limit = l.defaultLimit
+	}
+
+	if limit.unlimited() {
+		return true, 0
+	}
+
+	now := l.now()
+	limiter := l.limiter(fullMethod+" "+client, limit, now)
+
+	reservation := limiter.ReserveN(now, 1)
+	if !reservation.OK() {
+		return false, time.Second
+	}
+
+	if delay := reservation.DelayFrom(now); delay > 0 {
+		reservation.CancelAt(now)
+
+		return false, delay
+	}
+
+	return true, 0
+}
+
+func (l *Limiter) limiter(key string, limit Limit, now time.Time) *rate.Limiter {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+
+	if now.Sub(l.lastSweep) > idleBucketTtl {
+		for k, b := range l.buckets {
+			if now.Sub(b.lastSeen) > idleBucketTtl {
+				delete(l.buckets, k)
+			}
+		}
+
+		l.lastSweep = now
+	}
+
+	b, ok := l.buckets[key]
+	if !ok {
+		b = &bucket{limiter: rate.NewLimiter(rate.Limit(limit.RPS), limit.Burst), lastSeen: now}
+		l.buckets[key] = b
+	}
+
+	b.lastSeen = now
+
+	return b.limiter
+}
+
+func UnaryServerInterceptor(l *Limiter) grpc.UnaryServerInterceptor {
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := l.limit(ctx, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(l *Limiter) grpc.StreamServerInterceptor {
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := l.limit(stream.Context(), info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+func (l *Limiter) limit(ctx context.Context, fullMethod string) error {
+	allowed, retryDelay := l.Allow(fullMethod, ClientKey(ctx))
+	if allowed {
+		return nil
+	}
+
+	throttledRequests.WithLabelValues(fullMethod).Inc()
+
+	return commonerr.GrpcErrHandler(
+		ctx,
+		commonerr.NewRateLimitedError("too many requests, retry later").
+			WithReason(reasonRateLimited, map[string]string{"method": fullMethod}).
+			WithRetryDelay(retryDelay),
+		nil,
+	)
+}
+
+// ClientKey идентифицирует клиента: аутентифицированный клиент по имени, остальные по IP адресу.
+// Запросам через grpc-gateway, который подключается с loopback адреса, доверяется последний адрес
+// из x-forwarded-for: его дописывает сам шлюз, предыдущие значения приходят от клиента.
+func ClientKey(ctx context.Context) string {
+	if principal := auth.FromContext(ctx); principal != nil {
+		return "principal:" + principal.Subject
+	}
+
+	p, ok := peer.FromContext(ctx)
+	if !ok || p.Addr == nil {
+		return "peer:unknown"
+	}
+
+	host, _, err := net.SplitHostPort(p.Addr.String())
+	if err != nil {
+		host = p.Addr.String()
+	}
+
+	if ip := net.ParseIP(host); ip != nil && ip.IsLoopback() {
+		md, _ := metadata.FromIncomingContext(ctx)
+		if forwarded := md.Get(forwardedForMetadataKey); len(forwarded) > 0 {
+			addresses := strings.Split(forwarded[len(forwarded)-1], ",")
+
+			return "peer:" + strings.TrimSpace(addresses[len(addresses)-1])
+		}
+	}
+
+	return "peer:" + host
+}
@@ -0,0 +1,186 @@
+package ratelimit
+
+import (
+	"context"
+	"net"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/peer"
+	"google.golang.org/grpc/status"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+)
+
+const (
+	getProducts   = "/ProductService/GetProducts"
+	deleteProduct = "/ProductService/DeleteProduct"
+	healthCheck   = "/grpc.health.v1.Health/Check"
+)
+
+func newTestLimiter() (*Limiter, *time.Time) {
+	now := time.Date(2022, 8, 1, 12, 0, 0, 0, time.UTC)
+	limiter := New(Config{
+		Default:       Limit{RPS: 10, Burst: 2},
+		Methods:       map[string]Limit{deleteProduct: {RPS: 1, Burst: 1}},
+		ExemptMethods: []string{healthCheck},
+	})
+	limiter.now = func() time.Time { return now }
+
+	return limiter, &now
+}
+
+func TestLimiter_Allow(t *testing.T) {
+	limiter, now := newTestLimiter()
+
+	allowed, _ := limiter.Allow(deleteProduct, "client-1")
+	assert.True(t, allowed)
+
+	allowed, retryDelay := limiter.Allow(deleteProduct, "client-1")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Second, retryDelay)
+
+	// у другого клиента и другого метода свои bucket
+	allowed, _ = limiter.Allow(deleteProduct, "client-2")
+	assert.True(t, allowed)
+
+	for i := 0; i < 2; i++ {
+		allowed, _ = limiter.Allow(getProducts, "client-1")
+		assert.True(t, allowed)
+	}
+
+	allowed, retryDelay = limiter.Allow(getProducts, "client-1")
+	assert.False(t, allowed)
+	assert.Equal(t, time.Millisecond*100, retryDelay)
+
+	*now = now.Add(time.Second)
+
+	allowed, _ = limiter.Allow(deleteProduct, "client-1")
+	assert.True(t, allowed)
+
+	for i := 0; i < 10; i++ {
+		allowed, _ = limiter.Allow(healthCheck, "client-1")
+		assert.True(t, allowed)
+	}
+}
+
+func TestLimiter_SweepIdleBuckets(t *testing.T) {
+	limiter, now := newTestLimiter()
+
+	limiter.Allow(deleteProduct, "client-1")
+	*now = now.Add(idleBucketTtl * 2)
+	limiter.Allow(deleteProduct, "client-2")
+
+	assert.Len(t, limiter.buckets, 1)
+	assert.Contains(t, limiter.buckets, deleteProduct+" client-2")
+}
+
+func TestParseLimit(t *testing.T) {
+	testCases := []struct {
+		value    string
+		expected Limit
+		valid    bool
+	}{
+		{value: "10/20", expected: Limit{RPS: 10, Burst: 20}, valid: true},
+		{value: "0.5", expected: Limit{RPS: 0.5, Burst: 1}, valid: true},
+		{value: "0", expected: Limit{RPS: 0, Burst: 0}, valid: true},
+		{value: "ten", valid: false},
+		{value: "10/0", valid: false},
+		{value: "-1/5", valid: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.value, func(t *testing.T) {
+			t.Parallel()
+
+			limit, err := ParseLimit(tc.value)
+			if !tc.valid {
+				require.Error(t, err)
+
+				return
+			}
+
+			require.NoError(t, err)
+			assert.Equal(t, tc.expected, limit)
+		})
+	}
+}
+
+func peerContext(addr string, pairs ...string) context.Context {
+	ctx := peer.NewContext(context.Background(), &peer.Peer{ //nolint: exhaustruct
+		Addr: &net.TCPAddr{IP: net.ParseIP(addr), Port: 51234}, //nolint: exhaustruct
+	})
+
+	return metadata.NewIncomingContext(ctx, metadata.Pairs(pairs...))
+}
+
+func TestClientKey(t *testing.T) {
+	testCases := []struct {
+		name     string
+		ctx      context.Context
+		expected string
+	}{
+		{
+			name:     "principal",
+			ctx:      auth.NewContext(peerContext("10.0.0.1"), &auth.Principal{Subject: "storefront"}), //nolint: exhaustruct
+			expected: "principal:storefront",
+		},
+		{name: "peer", ctx: peerContext("10.0.0.1"), expected: "peer:10.0.0.1"},
+		{
+			name:     "spoofed forwarded for",
+			ctx:      peerContext("10.0.0.1", forwardedForMetadataKey, "1.1.1.1"),
+			expected: "peer:10.0.0.1",
+		},
+		{
+			name:     "gateway",
+			ctx:      peerContext("127.0.0.1", forwardedForMetadataKey, "1.1.1.1, 192.168.1.5"),
+			expected: "peer:192.168.1.5",
+		},
+		{name: "without peer", ctx: context.Background(), expected: "peer:unknown"},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.expected, ClientKey(tc.ctx))
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	limiter, _ := newTestLimiter()
+	interceptor := UnaryServerInterceptor(limiter)
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: deleteProduct} //nolint: exhaustruct
+	ctx := peerContext("10.0.0.1")
+
+	resp, err := interceptor(ctx, nil, info, handler)
+	require.NoError(t, err)
+	assert.Equal(t, "ok", resp)
+
+	_, err = interceptor(ctx, nil, info, handler)
+	require.Error(t, err)
+
+	st := status.Convert(err)
+	assert.Equal(t, codes.ResourceExhausted, st.Code())
+	require.Len(t, st.Details(), 2)
+
+	errorInfo, ok := st.Details()[0].(*errdetails.ErrorInfo)
+	require.True(t, ok)
+	assert.Equal(t, reasonRateLimited, errorInfo.GetReason())
+	assert.Equal(t, commonerr.ErrorDomain, errorInfo.GetDomain())
+	assert.Equal(t, deleteProduct, errorInfo.GetMetadata()["method"])
+
+	retryInfo, ok := st.Details()[1].(*errdetails.RetryInfo)
+	require.True(t, ok)
+	assert.Equal(t, time.Second, retryInfo.GetRetryDelay().AsDuration())
+}
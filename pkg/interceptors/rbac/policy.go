@@ -0,0 +1,110 @@
+package rbac
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+)
+
+// serviceWildcard метод в ключе правила, под который попадают все методы сервиса: `/AdminService/*`
+const serviceWildcard = "*"
+
+// Rule требования к клиенту. Доступ разрешен, если у клиента есть хотя бы одна из ролей или хотя бы одно из прав.
+type Rule struct {
+	Roles  []string `json:"roles"`
+	Scopes []string `json:"scopes"`
+}
+
+// Policy соответствие полных имен методов gRPC и требований к клиенту. Методы без правила запрещены.
+type Policy struct {
+	// Methods полное имя метода `/Service/Method` или `/Service/*` -> правило
+	Methods map[string]Rule `json:"methods"`
+	// Subjects роли, выданные клиенту по его имени. Нужны для API ключей, у которых нет своих ролей
+	Subjects map[string][]string `json:"subjects"`
+}
+
+// LoadPolicy читает политику из JSON файла.
+func LoadPolicy(path string) (*Policy, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read authorization policy: %w", err)
+	}
+
+	return ParsePolicy(data)
+}
+
+// ParsePolicy разбирает политику из JSON и проверяет имена методов и правила.
+func ParsePolicy(data []byte) (*Policy, error) {
+	var policy Policy
+
+	decoder := json.NewDecoder(bytes.NewReader(data))
+	decoder.DisallowUnknownFields()
+
+	if err := decoder.Decode(&policy); err != nil {
+		return nil, fmt.Errorf("can't parse authorization policy: %w", err)
+	}
+
+	for method, rule := range policy.Methods {
+		if _, _, ok := splitMethod(method); !ok {
+			return nil, fmt.Errorf("invalid method %q in authorization policy, expected /Service/Method", method)
+		}
+
+		if len(rule.Roles) == 0 && len(rule.Scopes) == 0 {
+			return nil, fmt.Errorf("rule for %s in authorization policy has neither roles nor scopes", method)
+		}
+	}
+
+	return &policy, nil
+}
+
+// Allowed проверяет, может ли клиент вызвать метод. Точное правило метода важнее правила сервиса.
+func (p *Policy) Allowed(fullMethod string, principal *auth.Principal) bool {
+	rule, ok := p.rule(fullMethod)
+	if !ok {
+		return false
+	}
+
+	roles := append(append([]string{}, principal.Roles...), p.Subjects[principal.Subject]...)
+
+	return intersects(rule.Roles, roles) || intersects(rule.Scopes, principal.Scopes)
+}
+
+func (p *Policy) rule(fullMethod string) (Rule, bool) {
+	if rule, ok := p.Methods[fullMethod]; ok {
+		return rule, true
+	}
+
+	service, _, ok := splitMethod(fullMethod)
+	if !ok {
+		return Rule{}, false //nolint: exhaustruct
+	}
+
+	rule, ok := p.Methods["/"+service+"/"+serviceWildcard]
+
+	return rule, ok
+}
+
+func splitMethod(fullMethod string) (string, string, bool) {
+	parts := strings.Split(fullMethod, "/")
+	if len(parts) != 3 || parts[0] != "" || parts[1] == "" || parts[2] == "" {
+		return "", "", false
+	}
+
+	return parts[1], parts[2], true
+}
+
+func intersects(required []string, granted []string) bool {
+	for _, r := range required {
+		for _, g := range granted {
+			if r == g {
+				return true
+			}
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,99 @@
+package rbac
+
+import (
+	"context"
+
+	"google.golang.org/grpc"
+
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+// reasonPermissionDenied причина отказа в google.rpc.ErrorInfo
+const reasonPermissionDenied = "PERMISSION_DENIED"
+
+// UnaryServerInterceptor проверяет права клиента, которого положил в контекст auth.UnaryServerInterceptor.
+// Должен идти после него в цепочке. exemptMethods — полные имена методов, доступных без аутентификации,
+// остальные вызовы без клиента в контексте отклоняются.
+func UnaryServerInterceptor(
+	policy *Policy,
+	exemptMethods []string,
+	logger logging.ContextLogger,
+) grpc.UnaryServerInterceptor {
+	exempt := exemptSet(exemptMethods)
+
+	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
+		if err := authorize(ctx, policy, exempt, logger, info.FullMethod); err != nil {
+			return nil, err
+		}
+
+		return handler(ctx, req)
+	}
+}
+
+func StreamServerInterceptor(
+	policy *Policy,
+	exemptMethods []string,
+	logger logging.ContextLogger,
+) grpc.StreamServerInterceptor {
+	exempt := exemptSet(exemptMethods)
+
+	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
+		if err := authorize(stream.Context(), policy, exempt, logger, info.FullMethod); err != nil {
+			return err
+		}
+
+		return handler(srv, stream)
+	}
+}
+
+// authorize пропускает только освобожденные методы и клиентов, которым политика разрешает вызов.
+// Вызов без клиента в контексте означает ошибку в цепочке interceptor'ов и отклоняется.
+func authorize(
+	ctx context.Context,
+	policy *Policy,
+	exempt map[string]struct{},
+	logger logging.ContextLogger,
+	fullMethod string,
+) error {
+	if _, ok := exempt[fullMethod]; ok {
+		return nil
+	}
+
+	_, ctxLogger := logger.FromContext(ctx)
+
+	principal := auth.FromContext(ctx)
+	if principal == nil {
+		ctxLogger.Warnw("Permission denied to unauthenticated call", "method", fullMethod)
+
+		return commonerr.GrpcErrHandler(
+			ctx,
+			commonerr.NewPermissionDeniedError("unauthenticated client is not allowed to call %s", fullMethod).
+				WithReason(reasonPermissionDenied, map[string]string{"method": fullMethod}),
+			nil,
+		)
+	}
+
+	if policy.Allowed(fullMethod, principal) {
+		return nil
+	}
+
+	ctxLogger.Warnw("Permission denied", "method", fullMethod, "principal", principal.Subject, "roles", principal.Roles)
+
+	return commonerr.GrpcErrHandler(
+		ctx,
+		commonerr.NewPermissionDeniedError("%s is not allowed to call %s", principal.Subject, fullMethod).
+			WithReason(reasonPermissionDenied, map[string]string{"method": fullMethod}),
+		nil,
+	)
+}
+
+func exemptSet(methods []string) map[string]struct{} {
+	exempt := make(map[string]struct{}, len(methods))
+	for _, method := range methods {
+		exempt[method] = struct{}{}
+	}
+
+	return exempt
+}
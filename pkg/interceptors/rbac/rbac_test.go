@@ -0,0 +1,191 @@
+package rbac
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/genproto/googleapis/rpc/errdetails"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/status"
+
+	"github.com/qulaz/artforintrovert-test/gen/api/v1"
+	"github.com/qulaz/artforintrovert-test/internal/common/commonerr"
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/auth"
+	"github.com/qulaz/artforintrovert-test/pkg/logging"
+)
+
+// examplePolicyPath политика из корня репозитория: тесты заодно проверяют, что пример остается корректным
+const examplePolicyPath = "../../../auth_policy.example.json"
+
+func newPrincipal(subject string, roles ...string) *auth.Principal {
+	return &auth.Principal{Subject: subject, Method: auth.MethodJWT, Roles: roles, Scopes: nil}
+}
+
+func TestPolicy_ProductService(t *testing.T) {
+	policy, err := LoadPolicy(examplePolicyPath)
+	require.NoError(t, err)
+
+	testCases := []struct {
+		method string
+		reader bool
+		editor bool
+	}{
+		{method: "GetProducts", reader: true, editor: true},
+		{method: "GetProduct", reader: true, editor: true},
+		{method: "UpdateProduct", reader: false, editor: true},
+		{method: "DeleteProduct", reader: false, editor: false},
+		{method: "RestoreProduct", reader: false, editor: false},
+		{method: "AddVariant", reader: false, editor: true},
+		{method: "UpdateVariant", reader: false, editor: true},
+		{method: "DeleteVariant", reader: false, editor: true},
+		{method: "ReserveStock", reader: false, editor: true},
+		{method: "ReleaseStock", reader: false, editor: true},
+		{method: "AdjustStock", reader: false, editor: true},
+		{method: "GetProductHistory", reader: false, editor: true},
+	}
+
+	covered := make(map[string]struct{}, len(testCases))
+
+	for _, tc := range testCases {
+		tc := tc
+		covered[tc.method] = struct{}{}
+
+		t.Run(tc.method, func(t *testing.T) {
+			t.Parallel()
+			fullMethod := "/" + api.ProductService_ServiceDesc.ServiceName + "/" + tc.method
+
+			assert.Equal(t, tc.reader, policy.Allowed(fullMethod, newPrincipal("user-1", "reader")))
+			assert.Equal(t, tc.editor, policy.Allowed(fullMethod, newPrincipal("user-2", "editor")))
+			assert.True(t, policy.Allowed(fullMethod, newPrincipal("user-3", "admin")))
+			assert.False(t, policy.Allowed(fullMethod, newPrincipal("user-4")))
+		})
+	}
+
+	for _, method := range api.ProductService_ServiceDesc.Methods {
+		assert.Contains(t, covered, method.MethodName, "ProductService method without test case")
+	}
+}
+
+func TestPolicy_ExampleGrantsNoSubjects(t *testing.T) {
+	policy, err := LoadPolicy(examplePolicyPath)
+	require.NoError(t, err)
+
+	// пример можно развернуть как есть: ни один API ключ не должен получить роли по умолчанию
+	assert.Empty(t, policy.Subjects)
+
+	apiKey := &auth.Principal{Subject: "dev", Method: auth.MethodAPIKey, Roles: nil, Scopes: nil}
+	assert.False(t, policy.Allowed("/ProductService/GetProduct", apiKey))
+	assert.False(t, policy.Allowed("/AdminService/ResyncProductsCache", apiKey))
+}
+
+func TestPolicy_Allowed(t *testing.T) {
+	policy, err := LoadPolicy(examplePolicyPath)
+	require.NoError(t, err)
+
+	policy.Subjects = map[string][]string{"dev": {"admin"}}
+
+	scoped := &auth.Principal{
+		Subject: "storefront",
+		Method:  auth.MethodJWT,
+		Roles:   nil,
+		Scopes:  []string{"products:read"},
+	}
+	apiKey := &auth.Principal{Subject: "dev", Method: auth.MethodAPIKey, Roles: nil, Scopes: nil}
+
+	testCases := []struct {
+		name      string
+		method    string
+		principal *auth.Principal
+		allowed   bool
+	}{
+		{name: "scope", method: "/ProductService/GetProduct", principal: scoped, allowed: true},
+		{name: "missing scope", method: "/ProductService/UpdateProduct", principal: scoped, allowed: false},
+		{name: "subject roles", method: "/ProductService/DeleteProduct", principal: apiKey, allowed: true},
+		{name: "service wildcard", method: "/AdminService/ResyncProductsCache", principal: apiKey, allowed: true},
+		{
+			name:      "method rule over wildcard",
+			method:    "/CategoryService/GetCategory",
+			principal: newPrincipal("user-1", "reader"),
+			allowed:   true,
+		},
+		{
+			name:      "wildcard denies",
+			method:    "/CategoryService/DeleteCategory",
+			principal: newPrincipal("user-1", "reader"),
+			allowed:   false,
+		},
+		{name: "unknown method", method: "/UnknownService/Call", principal: apiKey, allowed: false},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			t.Parallel()
+			assert.Equal(t, tc.allowed, policy.Allowed(tc.method, tc.principal))
+		})
+	}
+}
+
+func TestParsePolicy_Invalid(t *testing.T) {
+	testCases := map[string]string{
+		"unknown field":  `{"methods": {}, "users": {}}`,
+		"invalid method": `{"methods": {"ProductService.GetProduct": {"roles": ["reader"]}}}`,
+		"empty rule":     `{"methods": {"/ProductService/GetProduct": {}}}`,
+	}
+
+	for name, data := range testCases {
+		data := data
+		t.Run(name, func(t *testing.T) {
+			t.Parallel()
+			_, err := ParsePolicy([]byte(data))
+			require.Error(t, err)
+		})
+	}
+}
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	policy, err := LoadPolicy(examplePolicyPath)
+	require.NoError(t, err)
+
+	const healthMethod = "/grpc.health.v1.Health/Check"
+
+	interceptor := UnaryServerInterceptor(policy, []string{healthMethod}, logging.NewDummyLogger())
+	handler := func(ctx context.Context, req interface{}) (interface{}, error) { return "ok", nil }
+	info := &grpc.UnaryServerInfo{FullMethod: "/ProductService/DeleteProduct"} //nolint: exhaustruct
+
+	t.Run("denied", func(t *testing.T) {
+		ctx := auth.NewContext(context.Background(), newPrincipal("user-1", "reader"))
+
+		_, err := interceptor(ctx, nil, info, handler)
+
+		st := status.Convert(err)
+		assert.Equal(t, codes.PermissionDenied, st.Code())
+		require.NotEmpty(t, st.Details())
+
+		errorInfo, ok := st.Details()[0].(*errdetails.ErrorInfo)
+		require.True(t, ok)
+		assert.Equal(t, reasonPermissionDenied, errorInfo.GetReason())
+		assert.Equal(t, commonerr.ErrorDomain, errorInfo.GetDomain())
+	})
+	t.Run("allowed", func(t *testing.T) {
+		ctx := auth.NewContext(context.Background(), newPrincipal("user-1", "admin"))
+
+		resp, err := interceptor(ctx, nil, info, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+	t.Run("without principal", func(t *testing.T) {
+		_, err := interceptor(context.Background(), nil, info, handler)
+		assert.Equal(t, codes.PermissionDenied, status.Code(err))
+	})
+	t.Run("exempt method without principal", func(t *testing.T) {
+		exemptInfo := &grpc.UnaryServerInfo{FullMethod: healthMethod} //nolint: exhaustruct
+
+		resp, err := interceptor(context.Background(), nil, exemptInfo, handler)
+		require.NoError(t, err)
+		assert.Equal(t, "ok", resp)
+	})
+}
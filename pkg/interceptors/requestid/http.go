@@ -0,0 +1,18 @@
+package requestid
+
+import "net/http"
+
+// HTTPMiddleware берет идентификатор из заголовка X-Request-Id или генерирует новый,
+// кладет его в контекст запроса и возвращает клиенту в том же заголовке.
+func HTTPMiddleware(next http.Handler) http.Handler {
+	return http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		requestId := r.Header.Get(HeaderName)
+		if !validRequestId.MatchString(requestId) {
+			requestId = newRequestId()
+		}
+
+		w.Header().Set(HeaderName, requestId)
+
+		next.ServeHTTP(w, r.WithContext(NewContext(r.Context(), requestId)))
+	})
+}
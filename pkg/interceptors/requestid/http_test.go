@@ -0,0 +1,46 @@
+package requestid
+
+import (
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHTTPMiddleware(t *testing.T) {
+	var got string
+
+	handler := HTTPMiddleware(http.HandlerFunc(func(w http.ResponseWriter, r *http.Request) {
+		got = FromContext(r.Context())
+	}))
+
+	testCases := []struct {
+		name     string
+		header   string
+		expected string
+	}{
+		{name: "from header", header: "abc-123", expected: "abc-123"},
+		{name: "generated", header: "", expected: ""},
+		{name: "invalid header is replaced", header: "bad id\n", expected: ""},
+	}
+
+	for _, tc := range testCases {
+		req := httptest.NewRequest(http.MethodGet, "/products", nil)
+		if tc.header != "" {
+			req.Header.Set(HeaderName, tc.header)
+		}
+
+		rec := httptest.NewRecorder()
+		handler.ServeHTTP(rec, req)
+
+		assert.NotEmpty(t, got, tc.name)
+		assert.Equal(t, got, rec.Header().Get(HeaderName), tc.name)
+
+		if tc.expected != "" {
+			assert.Equal(t, tc.expected, got, tc.name)
+		} else {
+			assert.NotEqual(t, tc.header, got, tc.name)
+		}
+	}
+}
@@ -2,40 +2,63 @@ package requestid
 
 import (
 	"context"
+	"regexp"
 
 	"github.com/google/uuid"
 	grpc_middleware "github.com/grpc-ecosystem/go-grpc-middleware"
 	"google.golang.org/grpc"
+	"google.golang.org/grpc/metadata"
 )
 
+const (
+	// MetadataKey ключ метаданных gRPC с идентификатором запроса
+	MetadataKey = "x-request-id"
+	// HeaderName HTTP заголовок с идентификатором запроса
+	HeaderName = "X-Request-Id"
+)
+
+// validRequestId ограничивает идентификаторы от клиента, чтобы в логи и заголовки не попал мусор.
+var validRequestId = regexp.MustCompile(`^[A-Za-z0-9._-]{1,128}$`)
+
 type requestIDKey struct{}
 
 func newRequestId() string {
 	return uuid.New().String()
 }
 
+// UnaryServerInterceptor берет идентификатор запроса из метаданных x-request-id или генерирует новый
+// и возвращает его клиенту в заголовках и трейлерах ответа. Трейлеры доходят до клиента и тогда,
+// когда ответ с ошибкой отправлен без заголовков.
 func UnaryServerInterceptor() grpc.UnaryServerInterceptor {
 	return func(ctx context.Context, req interface{}, info *grpc.UnaryServerInfo, handler grpc.UnaryHandler) (interface{}, error) {
-		requestId := newRequestId()
-		ctx = context.WithValue(ctx, requestIDKey{}, requestId)
+		requestId := fromIncomingContext(ctx)
+		md := metadata.Pairs(MetadataKey, requestId)
+		// ошибка возможна, только если заголовки уже отправлены, что до вызова handler невозможно
+		_ = grpc.SetHeader(ctx, md)
+		_ = grpc.SetTrailer(ctx, md)
 
-		return handler(ctx, req)
+		return handler(NewContext(ctx, requestId), req)
 	}
 }
 
 func StreamServerInterceptor() grpc.StreamServerInterceptor {
 	return func(srv interface{}, stream grpc.ServerStream, info *grpc.StreamServerInfo, handler grpc.StreamHandler) error {
-		requestId := newRequestId()
-		ctx := stream.Context()
-		ctx = context.WithValue(ctx, requestIDKey{}, requestId)
+		requestId := fromIncomingContext(stream.Context())
+		md := metadata.Pairs(MetadataKey, requestId)
+		_ = stream.SetHeader(md)
+		stream.SetTrailer(md)
 
 		wrapped := grpc_middleware.WrapServerStream(stream)
-		wrapped.WrappedContext = ctx
+		wrapped.WrappedContext = NewContext(stream.Context(), requestId)
 
 		return handler(srv, wrapped)
 	}
 }
 
+func NewContext(ctx context.Context, requestId string) context.Context {
+	return context.WithValue(ctx, requestIDKey{}, requestId)
+}
+
 func FromContext(ctx context.Context) string {
 	id, ok := ctx.Value(requestIDKey{}).(string)
 	if !ok {
@@ -43,3 +66,14 @@ func FromContext(ctx context.Context) string {
 	}
 	return id
 }
+
+// fromIncomingContext возвращает идентификатор из метаданных запроса или новый, если его нет или он некорректен.
+func fromIncomingContext(ctx context.Context) string {
+	md, _ := metadata.FromIncomingContext(ctx)
+
+	if values := md.Get(MetadataKey); len(values) > 0 && validRequestId.MatchString(values[0]) {
+		return values[0]
+	}
+
+	return newRequestId()
+}
@@ -0,0 +1,127 @@
+package requestid
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	"google.golang.org/grpc"
+	"google.golang.org/grpc/codes"
+	"google.golang.org/grpc/metadata"
+	"google.golang.org/grpc/status"
+)
+
+// transportStream запоминает заголовки и трейлеры, которые сервер отправил бы клиенту.
+type transportStream struct {
+	header  metadata.MD
+	trailer metadata.MD
+}
+
+func (s *transportStream) Method() string { return "/ProductService/GetProduct" }
+
+func (s *transportStream) SetHeader(md metadata.MD) error {
+	s.header = metadata.Join(s.header, md)
+
+	return nil
+}
+
+func (s *transportStream) SendHeader(md metadata.MD) error { return s.SetHeader(md) }
+
+func (s *transportStream) SetTrailer(md metadata.MD) error {
+	s.trailer = metadata.Join(s.trailer, md)
+
+	return nil
+}
+
+// serverStream поток с транспортом transportStream.
+type serverStream struct {
+	grpc.ServerStream
+	transport *transportStream
+	ctx       context.Context
+}
+
+func (s *serverStream) Context() context.Context { return s.ctx }
+
+func (s *serverStream) SetHeader(md metadata.MD) error { return s.transport.SetHeader(md) }
+
+func (s *serverStream) SetTrailer(md metadata.MD) { _ = s.transport.SetTrailer(md) }
+
+func TestUnaryServerInterceptor(t *testing.T) {
+	interceptor := UnaryServerInterceptor()
+
+	testCases := []struct {
+		name     string
+		md       metadata.MD
+		expected string
+	}{
+		{name: "from metadata", md: metadata.Pairs(MetadataKey, "gateway-42"), expected: "gateway-42"},
+		{name: "generated", md: nil, expected: ""},
+		{name: "invalid id is replaced", md: metadata.Pairs(MetadataKey, "<script>"), expected: ""},
+	}
+
+	for _, tc := range testCases {
+		tc := tc
+		t.Run(tc.name, func(t *testing.T) {
+			stream := &transportStream{header: nil, trailer: nil}
+			ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+
+			if tc.md != nil {
+				ctx = metadata.NewIncomingContext(ctx, tc.md)
+			}
+
+			var got string
+
+			_, err := interceptor(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) { //nolint: exhaustruct
+				got = FromContext(ctx)
+
+				return nil, nil
+			})
+			require.NoError(t, err)
+
+			require.NotEmpty(t, got)
+			assert.Equal(t, []string{got}, stream.header.Get(MetadataKey))
+			assert.Equal(t, []string{got}, stream.trailer.Get(MetadataKey))
+
+			if tc.expected != "" {
+				assert.Equal(t, tc.expected, got)
+			}
+		})
+	}
+}
+
+func TestUnaryServerInterceptor_Error(t *testing.T) {
+	stream := &transportStream{header: nil, trailer: nil}
+	ctx := grpc.NewContextWithServerTransportStream(context.Background(), stream)
+	ctx = metadata.NewIncomingContext(ctx, metadata.Pairs(MetadataKey, "gateway-42"))
+
+	_, err := UnaryServerInterceptor()(ctx, nil, &grpc.UnaryServerInfo{}, func(ctx context.Context, req interface{}) (interface{}, error) { //nolint: exhaustruct
+		return nil, status.Error(codes.NotFound, "product not found")
+	})
+	require.Error(t, err)
+
+	// ответ с ошибкой может уйти без заголовков, идентификатор должен быть в трейлерах
+	assert.Equal(t, []string{"gateway-42"}, stream.trailer.Get(MetadataKey))
+}
+
+func TestStreamServerInterceptor(t *testing.T) {
+	transport := &transportStream{header: nil, trailer: nil}
+	stream := &serverStream{
+		ServerStream: nil,
+		transport:    transport,
+		ctx:          metadata.NewIncomingContext(context.Background(), metadata.Pairs(MetadataKey, "gateway-42")),
+	}
+
+	var got string
+
+	err := StreamServerInterceptor()(nil, stream, &grpc.StreamServerInfo{}, func(srv interface{}, stream grpc.ServerStream) error { //nolint: exhaustruct
+		got = FromContext(stream.Context())
+
+		return status.Error(codes.Unavailable, "database is unavailable")
+	})
+	require.Error(t, err)
+
+	assert.Equal(t, "gateway-42", got)
+	assert.Equal(t, []string{"gateway-42"}, transport.header.Get(MetadataKey))
+	assert.Equal(t, []string{"gateway-42"}, transport.trailer.Get(MetadataKey))
+}
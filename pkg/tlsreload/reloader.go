@@ -0,0 +1,205 @@
+package tlsreload
+
+import (
+	"context"
+	"crypto/tls"
+	"crypto/x509"
+	"errors"
+	"fmt"
+	"os"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Reloader держит сертификат с ключом и пул CA, загруженные из файлов, и перечитывает их при изменении.
+// Новые TLS соединения сразу получают обновленные сертификаты, уже установленные не разрываются.
+type Reloader struct {
+	certFile string
+	keyFile  string
+	caFile   string
+
+	mu          sync.RWMutex
+	cert        *tls.Certificate
+	caPool      *x509.CertPool
+	fingerprint string
+}
+
+// New загружает файлы. Любой из них может быть пустым, но сертификат и ключ задаются только вместе.
+func New(certFile string, keyFile string, caFile string) (*Reloader, error) {
+	if (certFile == "") != (keyFile == "") {
+		return nil, errors.New("certificate and key files must be set together")
+	}
+
+	r := &Reloader{ //nolint: exhaustruct
+		certFile: certFile,
+		keyFile:  keyFile,
+		caFile:   caFile,
+	}
+
+	if _, err := r.Reload(); err != nil {
+		return nil, err
+	}
+
+	return r, nil
+}
+
+// Reload перечитывает файлы, если изменилось время их модификации или размер, и сообщает, были ли они перечитаны.
+// При ошибке остаются прежние сертификаты.
+func (r *Reloader) Reload() (bool, error) {
+	fingerprint, err := r.filesFingerprint()
+	if err != nil {
+		return false, err
+	}
+
+	r.mu.RLock()
+	unchanged := fingerprint == r.fingerprint
+	r.mu.RUnlock()
+
+	if unchanged {
+		return false, nil
+	}
+
+	var cert *tls.Certificate
+
+	if r.certFile != "" {
+		keyPair, err := tls.LoadX509KeyPair(r.certFile, r.keyFile)
+		if err != nil {
+			return false, fmt.Errorf("can't load certificate: %w", err)
+		}
+
+		cert = &keyPair
+	}
+
+	var caPool *x509.CertPool
+
+	if r.caFile != "" {
+		caPool, err = loadCertPool(r.caFile)
+		if err != nil {
+			return false, err
+		}
+	}
+
+	r.mu.Lock()
+	r.cert = cert
+	r.caPool = caPool
+	r.fingerprint = fingerprint
+	r.mu.Unlock()
+
+	return true, nil
+}
+
+// Run проверяет файлы на изменение раз в interval до отмены ctx. После перечитывания вызывается onReload,
+// ошибки передаются в onError. При interval <= 0 сразу возвращается: сертификаты не перечитываются.
+func (r *Reloader) Run(ctx context.Context, interval time.Duration, onReload func(), onError func(err error)) {
+	if interval <= 0 {
+		return
+	}
+
+	ticker := time.NewTicker(interval)
+	defer ticker.Stop()
+
+	for {
+		select {
+		case <-ctx.Done():
+			return
+		case <-ticker.C:
+			reloaded, err := r.Reload()
+			if err != nil && onError != nil {
+				onError(err)
+			}
+
+			if reloaded && onReload != nil {
+				onReload()
+			}
+		}
+	}
+}
+
+// ServerConfig конфигурация TLS сервера. При requireClientCert и заданном CA клиенты обязаны предъявить
+// подписанный им сертификат. nextProtos передаются явно: конфигурация для каждого соединения собирается заново,
+// и протоколы, добавленные сервером в исходную конфигурацию, в нее не попадают.
+func (r *Reloader) ServerConfig(requireClientCert bool, nextProtos ...string) *tls.Config {
+	return &tls.Config{ //nolint: exhaustruct
+		MinVersion: tls.VersionTLS12,
+		GetConfigForClient: func(*tls.ClientHelloInfo) (*tls.Config, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			if r.cert == nil {
+				return nil, errors.New("server certificate is not loaded")
+			}
+
+			config := &tls.Config{ //nolint: exhaustruct
+				MinVersion:   tls.VersionTLS12,
+				Certificates: []tls.Certificate{*r.cert},
+				NextProtos:   nextProtos,
+			}
+
+			if requireClientCert && r.caPool != nil {
+				config.ClientCAs = r.caPool
+				config.ClientAuth = tls.RequireAndVerifyClientCert
+			}
+
+			return config, nil
+		},
+	}
+}
+
+// ClientConfig конфигурация TLS клиента. Сертификат сервера проверяется по CA из файла или системным CA,
+// если файл не задан. Клиентский сертификат, если он задан, перечитывается при изменении,
+// пул CA — только при создании конфигурации.
+func (r *Reloader) ClientConfig(serverName string) *tls.Config {
+	r.mu.RLock()
+	defer r.mu.RUnlock()
+
+	return &tls.Config{ //nolint: exhaustruct
+		MinVersion: tls.VersionTLS12,
+		ServerName: serverName,
+		RootCAs:    r.caPool,
+		GetClientCertificate: func(*tls.CertificateRequestInfo) (*tls.Certificate, error) {
+			r.mu.RLock()
+			defer r.mu.RUnlock()
+
+			if r.cert == nil {
+				// пустой сертификат: сервер, требующий mTLS, сам отклонит соединение
+				return &tls.Certificate{}, nil //nolint: exhaustruct
+			}
+
+			return r.cert, nil
+		},
+	}
+}
+
+func (r *Reloader) filesFingerprint() (string, error) {
+	parts := make([]string, 0, 3)
+
+	for _, path := range []string{r.certFile, r.keyFile, r.caFile} {
+		if path == "" {
+			continue
+		}
+
+		info, err := os.Stat(path)
+		if err != nil {
+			return "", err
+		}
+
+		parts = append(parts, fmt.Sprintf("%s:%d:%d", path, info.ModTime().UnixNano(), info.Size()))
+	}
+
+	return strings.Join(parts, ";"), nil
+}
+
+func loadCertPool(path string) (*x509.CertPool, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("can't read CA file: %w", err)
+	}
+
+	pool := x509.NewCertPool()
+	if !pool.AppendCertsFromPEM(data) {
+		return nil, fmt.Errorf("no certificates found in CA file %s", path)
+	}
+
+	return pool, nil
+}
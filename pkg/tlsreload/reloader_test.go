@@ -0,0 +1,181 @@
+package tlsreload
+
+import (
+	"context"
+	"crypto/ecdsa"
+	"crypto/elliptic"
+	"crypto/rand"
+	"crypto/tls"
+	"crypto/x509"
+	"crypto/x509/pkix"
+	"encoding/pem"
+	"math/big"
+	"net"
+	"os"
+	"path/filepath"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+)
+
+type testCert struct {
+	cert *x509.Certificate
+	key  *ecdsa.PrivateKey
+}
+
+// newTestCert выпускает сертификат для localhost, подписанный parent, или самоподписанный CA, если parent nil.
+func newTestCert(t *testing.T, name string, parent *testCert) *testCert {
+	t.Helper()
+
+	key, err := ecdsa.GenerateKey(elliptic.P256(), rand.Reader)
+	require.NoError(t, err)
+
+	template := &x509.Certificate{ //nolint: exhaustruct
+		SerialNumber: big.NewInt(time.Now().UnixNano()),
+		Subject:      pkix.Name{CommonName: name}, //nolint: exhaustruct
+		DNSNames:     []string{"localhost"},
+		NotBefore:    time.Now().Add(-time.Hour),
+		NotAfter:     time.Now().Add(time.Hour),
+		KeyUsage:     x509.KeyUsageDigitalSignature | x509.KeyUsageCertSign,
+		ExtKeyUsage:  []x509.ExtKeyUsage{x509.ExtKeyUsageServerAuth, x509.ExtKeyUsageClientAuth},
+	}
+
+	signer, signerKey := template, key
+	if parent == nil {
+		template.IsCA = true
+		template.BasicConstraintsValid = true
+	} else {
+		signer, signerKey = parent.cert, parent.key
+	}
+
+	der, err := x509.CreateCertificate(rand.Reader, template, signer, &key.PublicKey, signerKey)
+	require.NoError(t, err)
+
+	cert, err := x509.ParseCertificate(der)
+	require.NoError(t, err)
+
+	return &testCert{cert: cert, key: key}
+}
+
+func (c *testCert) write(t *testing.T, certPath string, keyPath string) {
+	t.Helper()
+
+	require.NoError(t, os.WriteFile(certPath, pem.EncodeToMemory(&pem.Block{Type: "CERTIFICATE", Bytes: c.cert.Raw}), 0o600)) //nolint: exhaustruct
+
+	if keyPath == "" {
+		return
+	}
+
+	der, err := x509.MarshalECPrivateKey(c.key)
+	require.NoError(t, err)
+	require.NoError(t, os.WriteFile(keyPath, pem.EncodeToMemory(&pem.Block{Type: "EC PRIVATE KEY", Bytes: der}), 0o600)) //nolint: exhaustruct
+}
+
+// handshake устанавливает TLS соединение через loopback и возвращает сертификат сервера и ошибку сервера.
+// net.Pipe не подходит: без буфера TLS 1.3 сервер и клиент блокируют друг друга на записи.
+func handshake(t *testing.T, server *tls.Config, client *tls.Config) (*x509.Certificate, error) {
+	t.Helper()
+
+	listener, err := net.Listen("tcp", "127.0.0.1:0")
+	require.NoError(t, err)
+	defer listener.Close()
+
+	serverErr := make(chan error, 1)
+
+	go func() {
+		conn, err := listener.Accept()
+		if err != nil {
+			serverErr <- err
+
+			return
+		}
+		defer conn.Close()
+
+		serverErr <- tls.Server(conn, server).Handshake()
+	}()
+
+	conn, err := tls.Dial("tcp", listener.Addr().String(), client)
+	if err == nil {
+		defer conn.Close()
+	}
+
+	if err := <-serverErr; err != nil {
+		return nil, err
+	}
+
+	require.NoError(t, err)
+
+	return conn.ConnectionState().PeerCertificates[0], nil
+}
+
+func TestReloader(t *testing.T) {
+	dir := t.TempDir()
+	certPath, keyPath, caPath := filepath.Join(dir, "tls.crt"), filepath.Join(dir, "tls.key"), filepath.Join(dir, "ca.crt")
+
+	ca := newTestCert(t, "ca", nil)
+	ca.write(t, caPath, "")
+
+	first := newTestCert(t, "first", ca)
+	first.write(t, certPath, keyPath)
+
+	server, err := New(certPath, keyPath, caPath)
+	require.NoError(t, err)
+
+	clientCertPath, clientKeyPath := filepath.Join(dir, "client.crt"), filepath.Join(dir, "client.key")
+	newTestCert(t, "gateway", ca).write(t, clientCertPath, clientKeyPath)
+
+	client, err := New(clientCertPath, clientKeyPath, caPath)
+	require.NoError(t, err)
+
+	t.Run("mutual tls", func(t *testing.T) {
+		peer, err := handshake(t, server.ServerConfig(true), client.ClientConfig("localhost"))
+		require.NoError(t, err)
+		assert.Equal(t, "first", peer.Subject.CommonName)
+	})
+	t.Run("client without certificate", func(t *testing.T) {
+		anonymous, err := New("", "", caPath)
+		require.NoError(t, err)
+
+		_, err = handshake(t, server.ServerConfig(true), anonymous.ClientConfig("localhost"))
+		require.Error(t, err)
+	})
+	t.Run("reload", func(t *testing.T) {
+		reloaded, err := server.Reload()
+		require.NoError(t, err)
+		assert.False(t, reloaded)
+
+		newTestCert(t, "second", ca).write(t, certPath, keyPath)
+		// время модификации может совпасть на файловых системах с грубым разрешением
+		later := time.Now().Add(time.Second)
+		require.NoError(t, os.Chtimes(certPath, later, later))
+
+		reloaded, err = server.Reload()
+		require.NoError(t, err)
+		assert.True(t, reloaded)
+
+		peer, err := handshake(t, server.ServerConfig(true), client.ClientConfig("localhost"))
+		require.NoError(t, err)
+		assert.Equal(t, "second", peer.Subject.CommonName)
+	})
+	t.Run("reloading disabled", func(t *testing.T) {
+		// Run без периода не должен паниковать в time.NewTicker и сразу возвращается
+		server.Run(context.Background(), 0, func() { t.Error("unexpected reload") }, nil)
+	})
+	t.Run("broken file keeps previous certificate", func(t *testing.T) {
+		require.NoError(t, os.WriteFile(keyPath, []byte("broken"), 0o600))
+
+		_, err := server.Reload()
+		require.Error(t, err)
+
+		peer, err := handshake(t, server.ServerConfig(false), client.ClientConfig("localhost"))
+		require.NoError(t, err)
+		assert.Equal(t, "second", peer.Subject.CommonName)
+	})
+}
+
+func TestNew_KeyWithoutCertificate(t *testing.T) {
+	_, err := New("", "tls.key", "")
+	require.Error(t, err)
+}
@@ -0,0 +1,34 @@
+package tracing
+
+import (
+	"context"
+
+	"go.opentelemetry.io/otel/attribute"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/requestid"
+)
+
+// RequestIdKey атрибут спана с идентификатором запроса, по нему трейс находится из лога или ответа с ошибкой.
+const RequestIdKey = attribute.Key("request.id")
+
+var _ tracesdk.SpanProcessor = requestIdProcessor{}
+
+// requestIdProcessor добавляет идентификатор запроса из контекста к каждому начатому спану.
+type requestIdProcessor struct{}
+
+func (requestIdProcessor) OnStart(parent context.Context, s tracesdk.ReadWriteSpan) {
+	if requestId := requestid.FromContext(parent); requestId != "" {
+		s.SetAttributes(RequestIdKey.String(requestId))
+	}
+}
+
+func (requestIdProcessor) OnEnd(tracesdk.ReadOnlySpan) {}
+
+func (requestIdProcessor) Shutdown(context.Context) error {
+	return nil
+}
+
+func (requestIdProcessor) ForceFlush(context.Context) error {
+	return nil
+}
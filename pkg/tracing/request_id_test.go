@@ -0,0 +1,40 @@
+package tracing
+
+import (
+	"context"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+	"github.com/stretchr/testify/require"
+	tracesdk "go.opentelemetry.io/otel/sdk/trace"
+	"go.opentelemetry.io/otel/sdk/trace/tracetest"
+
+	"github.com/qulaz/artforintrovert-test/pkg/interceptors/requestid"
+)
+
+func TestRequestIdProcessor(t *testing.T) {
+	exporter := tracetest.NewInMemoryExporter()
+	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithSpanProcessor(requestIdProcessor{}),
+		tracesdk.WithSyncer(exporter),
+	)
+	tracer := tp.Tracer("test")
+
+	ctx := requestid.NewContext(context.Background(), "request-42")
+	ctx, parent := tracer.Start(ctx, "parent")
+	_, child := tracer.Start(ctx, "child")
+	child.End()
+	parent.End()
+
+	_, withoutId := tracer.Start(context.Background(), "without id")
+	withoutId.End()
+
+	spans := exporter.GetSpans()
+	require.Len(t, spans, 3)
+
+	for _, span := range spans[:2] {
+		assert.Contains(t, span.Attributes, RequestIdKey.String("request-42"), span.Name)
+	}
+
+	assert.Empty(t, spans[2].Attributes)
+}
@@ -29,6 +29,7 @@ func New(serviceName string, exporterAddress string, exporterPort string) (*Tran
 	}
 
 	tp := tracesdk.NewTracerProvider(
+		tracesdk.WithSpanProcessor(requestIdProcessor{}),
 		tracesdk.WithBatcher(exporter),
 		tracesdk.WithResource(
 			resource.NewWithAttributes(